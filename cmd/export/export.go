@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+const defaultExportPageSize = 500
+
+// pageFunc reads one page of articles; db.Client.GetArticlesPaged satisfies it
+type pageFunc func(ctx context.Context, offset, limit int) ([]domain.Article, error)
+
+// articleWriter encodes articles one at a time to an underlying stream
+type articleWriter interface {
+	WriteArticle(article domain.Article) error
+	Flush() error
+}
+
+// exportRecord is the exported shape of an article
+type exportRecord struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	CrawledAt time.Time `json:"crawled_at"`
+}
+
+// newArticleWriter returns the writer for the given format ("csv" or "jsonl")
+func newArticleWriter(format string, out io.Writer) (articleWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVArticleWriter(out), nil
+	case "jsonl":
+		return &jsonlArticleWriter{enc: json.NewEncoder(out)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (want csv or jsonl)", format)
+	}
+}
+
+// csvArticleWriter writes a header row followed by one row per article
+// Fields containing commas, quotes or newlines are quoted by encoding/csv
+type csvArticleWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVArticleWriter(out io.Writer) *csvArticleWriter {
+	return &csvArticleWriter{w: csv.NewWriter(out)}
+}
+
+func (c *csvArticleWriter) WriteArticle(article domain.Article) error {
+	if !c.headerWritten {
+		if err := c.w.Write([]string{"url", "title", "text", "crawled_at"}); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		c.headerWritten = true
+	}
+
+	row := []string{article.URL, article.Title, article.Text, article.CrawledAt.UTC().Format(time.RFC3339)}
+	if err := c.w.Write(row); err != nil {
+		return fmt.Errorf("failed to write CSV row for %s: %w", article.URL, err)
+	}
+	return nil
+}
+
+func (c *csvArticleWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlArticleWriter writes one JSON object per line
+type jsonlArticleWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlArticleWriter) WriteArticle(article domain.Article) error {
+	record := exportRecord{
+		URL:       article.URL,
+		Title:     article.Title,
+		Text:      article.Text,
+		CrawledAt: article.CrawledAt.UTC(),
+	}
+	if err := j.enc.Encode(record); err != nil {
+		return fmt.Errorf("failed to write JSON line for %s: %w", article.URL, err)
+	}
+	return nil
+}
+
+func (j *jsonlArticleWriter) Flush() error {
+	return nil
+}
+
+// exportArticles streams all articles page by page into w and returns how many were written
+// Only one page is held in memory at a time
+func exportArticles(ctx context.Context, fetch pageFunc, w articleWriter, pageSize int) (int, error) {
+	if pageSize <= 0 {
+		pageSize = defaultExportPageSize
+	}
+
+	written := 0
+	for offset := 0; ; offset += pageSize {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		page, err := fetch(ctx, offset, pageSize)
+		if err != nil {
+			return written, fmt.Errorf("failed to read articles at offset %d: %w", offset, err)
+		}
+
+		for _, article := range page {
+			if err := w.WriteArticle(article); err != nil {
+				return written, err
+			}
+			written++
+		}
+
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return written, fmt.Errorf("failed to flush output: %w", err)
+	}
+	return written, nil
+}
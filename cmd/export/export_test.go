@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+// mockPager serves articles from a slice and records the requested offsets
+type mockPager struct {
+	articles []domain.Article
+	offsets  []int
+	err      error
+}
+
+func (m *mockPager) page(ctx context.Context, offset, limit int) ([]domain.Article, error) {
+	m.offsets = append(m.offsets, offset)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if offset >= len(m.articles) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(m.articles) {
+		end = len(m.articles)
+	}
+	return m.articles[offset:end], nil
+}
+
+func testArticles() []domain.Article {
+	crawled := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []domain.Article{
+		{URL: "https://example.com/a", Title: "Kafka, Flink and friends", Text: "line one\nline two", CrawledAt: crawled},
+		{URL: "https://example.com/b", Title: `The "real" story`, Text: "plain", CrawledAt: crawled},
+		{URL: "https://example.com/c", Title: "Simple", Text: "text", CrawledAt: crawled},
+	}
+}
+
+func TestExportArticles_CSVQuotesSpecialCharacters(t *testing.T) {
+	pager := &mockPager{articles: testArticles()}
+	var buf bytes.Buffer
+
+	count, err := exportArticles(context.Background(), pager.page, newCSVArticleWriter(&buf), 2)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 articles exported, got %d", count)
+	}
+
+	if !strings.Contains(buf.String(), `"Kafka, Flink and friends"`) {
+		t.Errorf("Expected title with comma to be quoted, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"The ""real"" story"`) {
+		t.Errorf("Expected quotes in title to be escaped, got:\n%s", buf.String())
+	}
+
+	// Round-trip to check the multi-line text stays in one field
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV output: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected header + 3 rows, got %d rows", len(records))
+	}
+	if strings.Join(records[0], ",") != "url,title,text,crawled_at" {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+	if records[1][2] != "line one\nline two" {
+		t.Errorf("Expected multi-line text preserved, got %q", records[1][2])
+	}
+	if records[1][3] != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected RFC3339 crawled_at, got %q", records[1][3])
+	}
+}
+
+func TestExportArticles_JSONLLineShape(t *testing.T) {
+	pager := &mockPager{articles: testArticles()}
+	var buf bytes.Buffer
+
+	writer, err := newArticleWriter("jsonl", &buf)
+	if err != nil {
+		t.Fatalf("newArticleWriter failed: %v", err)
+	}
+	if _, err := exportArticles(context.Background(), pager.page, writer, 10); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Line is not valid JSON: %v", err)
+	}
+	if len(record) != 4 {
+		t.Errorf("Expected exactly url, title, text, crawled_at, got %v", record)
+	}
+	if record["url"] != "https://example.com/a" || record["crawled_at"] != "2024-03-01T12:00:00Z" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+func TestExportArticles_StopsOnShortPage(t *testing.T) {
+	pager := &mockPager{articles: testArticles()}
+
+	if _, err := exportArticles(context.Background(), pager.page, newCSVArticleWriter(&bytes.Buffer{}), 2); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(pager.offsets) != 2 || pager.offsets[1] != 2 {
+		t.Errorf("Expected pages at offsets [0 2], got %v", pager.offsets)
+	}
+}
+
+func TestExportArticles_ReadError(t *testing.T) {
+	pager := &mockPager{err: errors.New("connection lost")}
+
+	if _, err := exportArticles(context.Background(), pager.page, newCSVArticleWriter(&bytes.Buffer{}), 2); err == nil {
+		t.Fatal("Expected error when reading fails, got nil")
+	}
+}
+
+func TestNewArticleWriter_UnknownFormat(t *testing.T) {
+	if _, err := newArticleWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Fatal("Expected error for unknown format, got nil")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"blog-search/pkg/domain"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// searchFunc runs a search query and returns up to limit matching articles
+// Both db.Client.SearchArticles and db.ArticleRepository.Search satisfy it
+type searchFunc func(ctx context.Context, query string, limit int) ([]domain.Article, error)
+
+// newMux creates the HTTP routes for the search server
+func newMux(search searchFunc) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", handleSearch(search))
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	return mux
+}
+
+// handleSearch serves GET /search?q=...&limit=...
+func handleSearch(search searchFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeError(w, http.StatusBadRequest, "missing required query parameter 'q'")
+			return
+		}
+
+		limit, err := parseLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		articles, err := search(r.Context(), query, limit)
+		if err != nil {
+			log.Printf("Search for %q failed: %v", query, err)
+			writeError(w, http.StatusInternalServerError, "search failed")
+			return
+		}
+
+		// Always return a JSON array, even when nothing matched
+		if articles == nil {
+			articles = []domain.Article{}
+		}
+		writeJSON(w, http.StatusOK, articles)
+	}
+}
+
+// handleHealthz serves GET /healthz
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// parseLimit parses the limit query parameter, applying the default and the maximum
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return defaultSearchLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, errInvalidLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	return limit, nil
+}
+
+// errInvalidLimit is returned when the limit parameter is not a positive integer
+var errInvalidLimit = errors.New("limit must be a positive integer")
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
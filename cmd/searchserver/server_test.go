@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+func TestHandleSearch_ReturnsArticlesAsJSON(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	search := func(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+		gotQuery = query
+		gotLimit = limit
+		return []domain.Article{
+			{URL: "https://example.com/kafka", Title: "Kafka", Text: "Partitions", CrawledAt: time.Now()},
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search?q=kafka&limit=5", nil)
+	newMux(search).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+	if gotQuery != "kafka" || gotLimit != 5 {
+		t.Errorf("Expected search(kafka, 5), got search(%s, %d)", gotQuery, gotLimit)
+	}
+
+	var results []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	for _, field := range []string{"url", "title", "text", "crawled_at"} {
+		if _, ok := results[0][field]; !ok {
+			t.Errorf("Expected field %q in result, got %v", field, results[0])
+		}
+	}
+}
+
+func TestHandleSearch_MissingQueryReturns400(t *testing.T) {
+	called := false
+	search := func(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+		called = true
+		return nil, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	newMux(search).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+	if called {
+		t.Error("Expected search not to be called without a query")
+	}
+}
+
+func TestHandleSearch_EmptyResultIsJSONArray(t *testing.T) {
+	search := func(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+		return nil, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search?q=nothing", nil)
+	newMux(search).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Errorf("Expected empty JSON array, got %q", body)
+	}
+}
+
+func TestHandleSearch_SearchErrorReturns500(t *testing.T) {
+	search := func(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+		return nil, errors.New("db down")
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/search?q=kafka", nil)
+	newMux(search).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", rec.Code)
+	}
+}
+
+func TestHandleHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	newMux(nil).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"blog-search/pkg/pipeline"
+)
+
+const discoverUsage = "Usage: go run . discover [sitemap|rss|paginate] <URL> [page-pattern] [extractor-type] [-url-filter=<path>] [-out=<file>]"
+
+// discoverOptions holds the parsed arguments of the discover subcommand
+type discoverOptions struct {
+	args    []string // Positional args, laid out like the pipeline subcommand's
+	flags   pipelineFlags
+	outPath string
+}
+
+// runDiscover runs only the URL-discovery steps of a pipeline and prints the resulting URLs
+func runDiscover(args []string) {
+	opts, err := parseDiscoverArgs(args)
+	if err != nil {
+		log.Fatalf("%v\n%s", err, discoverUsage)
+	}
+	applyLogLevel(opts.flags)
+
+	p, baseURL := buildDiscoveryPipeline(opts)
+	applyClientOptions(p, opts.flags, baseURL)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	discovered, err := p.Discover(ctx, baseURL)
+	if err != nil {
+		log.Fatalf("Discovery failed: %v", err)
+	}
+
+	if err := writeDiscoveredURLs(opts.outPath, discovered); err != nil {
+		log.Fatalf("Failed to write URLs: %v", err)
+	}
+	log.Printf("Discovered %d URLs", len(discovered))
+}
+
+// parseDiscoverArgs parses the discover subcommand's positional args and flags
+// Flags may only follow the positional args, as with the pipeline subcommand
+func parseDiscoverArgs(args []string) (discoverOptions, error) {
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	opts := discoverOptions{flags: newPipelineFlags(fs)}
+	outPath := fs.String("out", "", "Write URLs to this file instead of stdout")
+
+	positional := args
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			positional = args[:i]
+			if err := fs.Parse(args[i:]); err != nil {
+				return discoverOptions{}, err
+			}
+			break
+		}
+	}
+
+	if len(positional) < 2 {
+		return discoverOptions{}, fmt.Errorf("discover needs a source type and URL")
+	}
+	switch positional[0] {
+	case "sitemap", "rss", "follow":
+	case "paginate":
+		if len(positional) < 3 {
+			return discoverOptions{}, fmt.Errorf("paginate needs a base URL and page pattern")
+		}
+	default:
+		return discoverOptions{}, fmt.Errorf("unknown source type %q (want sitemap, rss, paginate, or follow)", positional[0])
+	}
+
+	opts.args = positional
+	opts.outPath = *outPath
+	return opts, nil
+}
+
+// buildDiscoveryPipeline builds the same pipeline as the pipeline subcommand
+// No database client is needed because Discover never runs the content consumer
+func buildDiscoveryPipeline(opts discoverOptions) (*pipeline.Pipeline, string) {
+	filters := buildURLFilters(opts.flags.urlFilterPath)
+
+	switch opts.args[0] {
+	case "sitemap":
+		return buildSitemapPipeline(nil, opts.args, filters)
+	case "rss":
+		return buildRSSPipeline(nil, opts.args, filters)
+	case "follow":
+		return buildFollowPipeline(nil, opts.args, filters)
+	default:
+		return buildPaginationPipeline(nil, opts.args, filters, buildPageRangeOptions(opts.flags))
+	}
+}
+
+// writeDiscoveredURLs writes one URL per line to outPath, or to stdout if outPath is empty
+func writeDiscoveredURLs(outPath string, discovered []string) error {
+	if outPath == "" {
+		return writeURLLines(os.Stdout, discovered)
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	if err := writeURLLines(f, discovered); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeURLLines writes one URL per line to out
+func writeURLLines(out io.Writer, discovered []string) error {
+	w := bufio.NewWriter(out)
+	for _, url := range discovered {
+		if _, err := fmt.Fprintln(w, url); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
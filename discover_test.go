@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiscoverArgs_SitemapWithFlags(t *testing.T) {
+	opts, err := parseDiscoverArgs([]string{"sitemap", "https://example.com/sitemap.xml", "-url-filter=/blog", "-out=seeds.txt"})
+	if err != nil {
+		t.Fatalf("parseDiscoverArgs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(opts.args, []string{"sitemap", "https://example.com/sitemap.xml"}) {
+		t.Errorf("Unexpected positional args: %v", opts.args)
+	}
+	if *opts.flags.urlFilterPath != "/blog" {
+		t.Errorf("Expected url filter '/blog', got %q", *opts.flags.urlFilterPath)
+	}
+	if opts.outPath != "seeds.txt" {
+		t.Errorf("Expected out path 'seeds.txt', got %q", opts.outPath)
+	}
+}
+
+func TestParseDiscoverArgs_PaginateKeepsPipelineLayout(t *testing.T) {
+	opts, err := parseDiscoverArgs([]string{"paginate", "https://se-radio.net", "/page/%d", "se-radio", "-empty-markers=Nothing here"})
+	if err != nil {
+		t.Fatalf("parseDiscoverArgs failed: %v", err)
+	}
+
+	if len(opts.args) != 4 || opts.args[3] != "se-radio" {
+		t.Errorf("Expected extractor type at index 3, got %v", opts.args)
+	}
+	if *opts.flags.emptyContentMarkers != "Nothing here" {
+		t.Errorf("Expected empty markers flag, got %q", *opts.flags.emptyContentMarkers)
+	}
+	if *opts.flags.contentCheckInterval != 10 {
+		t.Errorf("Expected default content check interval 10, got %d", *opts.flags.contentCheckInterval)
+	}
+}
+
+func TestParseDiscoverArgs_RepeatedHeaders(t *testing.T) {
+	opts, err := parseDiscoverArgs([]string{"rss", "https://example.com/feed", "-header=Cookie=session=abc", "-header", "Referer = https://example.com/"})
+	if err != nil {
+		t.Fatalf("parseDiscoverArgs failed: %v", err)
+	}
+
+	expected := headerFlags{"Cookie": "session=abc", "Referer": "https://example.com/"}
+	if !reflect.DeepEqual(opts.flags.headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, opts.flags.headers)
+	}
+}
+
+func TestParseDiscoverArgs_Invalid(t *testing.T) {
+	tests := map[string][]string{
+		"missing URL":          {"sitemap"},
+		"unknown source":       {"atom", "https://example.com/feed"},
+		"paginate w/o pattern": {"paginate", "https://example.com"},
+		"unknown flag":         {"rss", "https://example.com/feed", "-bogus"},
+		"header without value": {"rss", "https://example.com/feed", "-header=Cookie"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseDiscoverArgs(args); err == nil {
+				t.Errorf("Expected error for %v, got nil", args)
+			}
+		})
+	}
+}
+
+func TestDiscover_SitemapUsesPipelineFetchers(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>%[1]s/blog/post1</loc></url>
+	<url><loc>%[1]s/about</loc></url>
+	<url><loc>%[1]s/blog/post2</loc></url>
+</urlset>`, server.URL)
+	}))
+	defer server.Close()
+
+	opts, err := parseDiscoverArgs([]string{"sitemap", server.URL + "/sitemap.xml", "-url-filter=/blog"})
+	if err != nil {
+		t.Fatalf("parseDiscoverArgs failed: %v", err)
+	}
+
+	p, baseURL := buildDiscoveryPipeline(opts)
+	discovered, err := p.Discover(context.Background(), baseURL)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	expected := []string{server.URL + "/blog/post1", server.URL + "/blog/post2"}
+	if !reflect.DeepEqual(discovered, expected) {
+		t.Errorf("Expected %v, got %v", expected, discovered)
+	}
+}
+
+func TestDiscover_FollowsNextPageLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/category/eng":
+			fmt.Fprint(w, `<html><body><article><a href="/blog/post1">Post 1</a></article>
+				<nav><a class="next" href="/category/eng/page/2">Older</a></nav></body></html>`)
+		case "/category/eng/page/2":
+			fmt.Fprint(w, `<html><body><article><a href="/blog/post2">Post 2</a></article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	opts, err := parseDiscoverArgs([]string{"follow", server.URL + "/category/eng", "generic"})
+	if err != nil {
+		t.Fatalf("parseDiscoverArgs failed: %v", err)
+	}
+
+	p, baseURL := buildDiscoveryPipeline(opts)
+	discovered, err := p.Discover(context.Background(), baseURL)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	expected := []string{server.URL + "/blog/post1", server.URL + "/blog/post2"}
+	if !reflect.DeepEqual(discovered, expected) {
+		t.Errorf("Expected %v, got %v", expected, discovered)
+	}
+}
+
+func TestWriteDiscoveredURLs_ToFile(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "seeds.txt")
+
+	if err := writeDiscoveredURLs(outPath, []string{"https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("writeDiscoveredURLs failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(data) != "https://example.com/a\nhttps://example.com/b\n" {
+		t.Errorf("Unexpected file contents: %q", data)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"blog-search/pkg/urls"
+)
+
+// runExtract extracts URLs from an HTML file using a specified extractor
+// With -json, the URLs are printed as a JSON array (e.g., to pipe into jq) instead of a numbered list
+func runExtract(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the extracted URLs as an indented JSON array")
+
+	// Flags may only follow the positional args, as with the pipeline subcommand
+	positional := args
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			positional = args[:i]
+			if err := fs.Parse(args[i:]); err != nil {
+				log.Fatalf("Failed to parse flags: %v", err)
+			}
+			break
+		}
+	}
+
+	if len(positional) < 2 {
+		log.Fatalf("Usage: go run . extract <html-file-path> <extractor-type> [-json]\n" +
+			"  extractor-type: se-radio, data-engineering-podcast, generic")
+	}
+
+	htmlFilePath := positional[0]
+	extractorType := positional[1]
+
+	// Read HTML file
+	htmlContent, err := os.ReadFile(htmlFilePath)
+	if err != nil {
+		log.Fatalf("Failed to read HTML file %s: %v", htmlFilePath, err)
+	}
+
+	// Get the appropriate extractor
+	extractor := getExtractorByType(extractorType)
+	if extractor == nil {
+		log.Fatalf("Unknown extractor type: %s. Available: se-radio, data-engineering-podcast", extractorType)
+	}
+
+	// Extract URLs
+	extracted, err := extractor(string(htmlContent))
+	if err != nil {
+		log.Fatalf("Failed to extract URLs: %v", err)
+	}
+
+	if *asJSON {
+		err = writeExtractedJSON(os.Stdout, extracted)
+	} else {
+		err = writeExtractedText(os.Stdout, htmlFilePath, extracted)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write URLs: %v", err)
+	}
+}
+
+// writeExtractedText prints the extracted URLs as a numbered list
+func writeExtractedText(w io.Writer, source string, extracted []urls.URL) error {
+	if _, err := fmt.Fprintf(w, "\n=== Extracted %d URLs from %s ===\n\n", len(extracted), source); err != nil {
+		return err
+	}
+	for i, url := range extracted {
+		if _, err := fmt.Fprintf(w, "%d. Title: %s\n   URL: %s\n\n", i+1, url.Title, url.Location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExtractedJSON prints the extracted URLs as an indented JSON array; no URLs print as []
+func writeExtractedJSON(w io.Writer, extracted []urls.URL) error {
+	if extracted == nil {
+		extracted = []urls.URL{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(extracted)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"blog-search/pkg/urls"
+)
+
+// mockURLExtractor is a urls.URLExtractor returning two known episodes
+func mockURLExtractor(html string) ([]urls.URL, error) {
+	return []urls.URL{
+		{Location: "https://example.com/episode-1", Title: "Episode 1"},
+		{Location: "https://example.com/episode-2", Title: "Episode 2", Summary: "Kafka internals"},
+	}, nil
+}
+
+func TestWriteExtractedJSON(t *testing.T) {
+	var extractor urls.URLExtractor = mockURLExtractor
+	extracted, _ := extractor("<html></html>")
+
+	var buf bytes.Buffer
+	if err := writeExtractedJSON(&buf, extracted); err != nil {
+		t.Fatalf("writeExtractedJSON failed: %v", err)
+	}
+
+	var decoded []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", buf.String(), err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("Expected 2 URLs, got %d", len(decoded))
+	}
+	want := map[string]string{"location": "https://example.com/episode-1", "title": "Episode 1"}
+	if !reflect.DeepEqual(decoded[0], want) {
+		t.Errorf("Expected %v, got %v", want, decoded[0])
+	}
+	if decoded[1]["summary"] != "Kafka internals" {
+		t.Errorf("Expected the summary field, got %v", decoded[1])
+	}
+
+	buf.Reset()
+	if err := writeExtractedJSON(&buf, nil); err != nil {
+		t.Fatalf("writeExtractedJSON failed: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("Expected an empty array for no URLs, got %q", buf.String())
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"blog-search/pkg/httpclient"
+)
+
+func TestParseClientType(t *testing.T) {
+	tests := []struct {
+		value string
+		want  httpclient.ClientType
+	}{
+		{"browser", httpclient.BrowserClient},
+		{"cloudflare", httpclient.CloudflareClient},
+		{" Browser ", httpclient.BrowserClient},
+	}
+	for _, tt := range tests {
+		got, err := parseClientType(tt.value)
+		if err != nil {
+			t.Errorf("parseClientType(%q) failed: %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("parseClientType(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseClientType("chrome"); err == nil {
+		t.Error("Expected an error for an unknown client")
+	}
+}
+
+func TestClientOptions_LimitsHeadersToBaseHost(t *testing.T) {
+	userAgent := "blog-search/1.0"
+	flags := pipelineFlags{headers: headerFlags{"Cookie": "session=abc"}, userAgent: &userAgent}
+
+	opts, err := clientOptions(flags, "https://blog.example.com:8443/feed.xml")
+	if err != nil {
+		t.Fatalf("clientOptions failed: %v", err)
+	}
+	if opts.ExtraHeadersHost != "blog.example.com:8443" {
+		t.Errorf("Expected the base URL's host, got %q", opts.ExtraHeadersHost)
+	}
+	if opts.ExtraHeaders["Cookie"] != "session=abc" {
+		t.Errorf("Expected the -header flags, got %v", opts.ExtraHeaders)
+	}
+	if opts.UserAgent != userAgent {
+		t.Errorf("Expected the -user-agent flag, got %q", opts.UserAgent)
+	}
+
+	if _, err := clientOptions(flags, "not a url"); err == nil {
+		t.Error("Expected an error for a base URL without a host")
+	}
+}
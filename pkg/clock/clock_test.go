@@ -0,0 +1,23 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_OnlyMovesWhenTold(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	if got := fake.Now(); !got.Equal(start) {
+		t.Errorf("Expected %v, got %v", start, got)
+	}
+	fake.Advance(90 * time.Minute)
+	if got, want := fake.Now(), start.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("Expected %v after Advance, got %v", want, got)
+	}
+	fake.Set(start)
+	if got := fake.Now(); !got.Equal(start) {
+		t.Errorf("Expected %v after Set, got %v", start, got)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a pipeline run, as an alternative to positional command-line args
+//
+// Example:
+//
+//	type: paginate
+//	base_url: https://se-radio.net
+//	page_pattern: /page/%d
+//	extractor: se-radio
+//	pages_per_batch: 10
+//	workers:
+//	  page_gen: 1
+//	  html_fetcher: 3
+//	  content: 5
+//	url_filters:
+//	  - /episode
+type Config struct {
+	Type          string   `yaml:"type"`            // sitemap, rss, or paginate
+	BaseURL       string   `yaml:"base_url"`        // Sitemap/RSS URL, or site base URL for paginate
+	PagePattern   string   `yaml:"page_pattern"`    // paginate only, e.g. "/page/%d"
+	Extractor     string   `yaml:"extractor"`       // paginate only; empty means auto-detect
+	PagesPerBatch int      `yaml:"pages_per_batch"` // paginate only
+	Workers       Workers  `yaml:"workers"`
+	URLFilters    []string `yaml:"url_filters"` // Keep only URLs containing every one of these path segments
+}
+
+// Workers holds per-step worker counts; zero means the default for the pipeline type
+type Workers struct {
+	URLFetcher  int `yaml:"url_fetcher"`  // sitemap/rss
+	PageGen     int `yaml:"page_gen"`     // paginate
+	HTMLFetcher int `yaml:"html_fetcher"` // paginate
+	Content     int `yaml:"content"`
+}
+
+// Load reads a YAML config file, fills in defaults, and validates it
+// Unknown keys are rejected so typos don't silently fall back to defaults
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// applyDefaults fills unset values with the same defaults as the pipeline subcommand
+func (c *Config) applyDefaults() {
+	switch c.Type {
+	case "sitemap", "rss":
+		setDefault(&c.Workers.URLFetcher, 2)
+		setDefault(&c.Workers.Content, 3)
+	case "paginate":
+		setDefault(&c.PagesPerBatch, 10)
+		setDefault(&c.Workers.PageGen, 1)
+		setDefault(&c.Workers.HTMLFetcher, 3)
+		setDefault(&c.Workers.Content, 5)
+	}
+}
+
+// validate checks required fields for the pipeline type
+func (c *Config) validate() error {
+	switch c.Type {
+	case "sitemap", "rss":
+	case "paginate":
+		if c.PagePattern == "" {
+			return fmt.Errorf("page_pattern is required for paginate")
+		}
+	case "":
+		return fmt.Errorf("type is required")
+	default:
+		return fmt.Errorf("unknown type %q (want sitemap, rss, or paginate)", c.Type)
+	}
+
+	if c.BaseURL == "" {
+		return fmt.Errorf("base_url is required")
+	}
+	return nil
+}
+
+// setDefault sets *v to def when it is unset
+func setDefault(v *int, def int) {
+	if *v <= 0 {
+		*v = def
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeConfig writes contents to a temporary YAML file and returns its path
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_FullConfig(t *testing.T) {
+	path := writeConfig(t, `
+type: paginate
+base_url: https://se-radio.net
+page_pattern: /page/%d
+extractor: se-radio
+pages_per_batch: 20
+workers:
+  page_gen: 2
+  html_fetcher: 4
+  content: 8
+url_filters:
+  - /episode
+  - /blog
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expected := Config{
+		Type:          "paginate",
+		BaseURL:       "https://se-radio.net",
+		PagePattern:   "/page/%d",
+		Extractor:     "se-radio",
+		PagesPerBatch: 20,
+		Workers:       Workers{PageGen: 2, HTMLFetcher: 4, Content: 8},
+		URLFilters:    []string{"/episode", "/blog"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+}
+
+func TestLoad_PartialConfigUsesDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected Workers
+		batch    int
+	}{
+		{
+			name:     "sitemap",
+			yaml:     "type: sitemap\nbase_url: https://example.com/sitemap.xml\n",
+			expected: Workers{URLFetcher: 2, Content: 3},
+		},
+		{
+			name:     "paginate",
+			yaml:     "type: paginate\nbase_url: https://se-radio.net\npage_pattern: /page/%d\nworkers:\n  content: 10\n",
+			expected: Workers{PageGen: 1, HTMLFetcher: 3, Content: 10},
+			batch:    10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.yaml))
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if cfg.Workers != tt.expected {
+				t.Errorf("Expected workers %+v, got %+v", tt.expected, cfg.Workers)
+			}
+			if cfg.PagesPerBatch != tt.batch {
+				t.Errorf("Expected pages per batch %d, got %d", tt.batch, cfg.PagesPerBatch)
+			}
+		})
+	}
+}
+
+func TestLoad_MalformedFile(t *testing.T) {
+	tests := map[string]string{
+		"invalid YAML":     "type: [sitemap\n",
+		"unknown key":      "type: rss\nbase_url: https://example.com/feed\ncontent_workers: 3\n",
+		"wrong value type": "type: rss\nbase_url: https://example.com/feed\nworkers:\n  content: many\n",
+		"missing type":     "base_url: https://example.com/feed\n",
+		"unknown type":     "type: atom\nbase_url: https://example.com/feed\n",
+		"paginate pattern": "type: paginate\nbase_url: https://se-radio.net\n",
+		"missing base URL": "type: sitemap\n",
+		"empty file":       "",
+	}
+
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, contents)); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("Expected error for missing file, got nil")
+	}
+}
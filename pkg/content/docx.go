@@ -0,0 +1,97 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// wordNamespace is the XML namespace used by WordprocessingML elements (w:p, w:t, ...)
+const wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// ExtractTextFromDOCXReader extracts plain text from a Word (.docx) document
+// It reads word/document.xml from the archive and joins the <w:t> runs of each
+// paragraph, separating paragraphs with newlines
+func ExtractTextFromDOCXReader(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read docx: %w", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx archive: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		doc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to open word/document.xml: %w", err)
+		}
+		defer doc.Close()
+
+		return extractDOCXParagraphs(doc)
+	}
+
+	return "", fmt.Errorf("word/document.xml not found in docx")
+}
+
+// extractDOCXParagraphs walks the document XML and collects text runs per paragraph
+func extractDOCXParagraphs(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var paragraphs []string
+	var current strings.Builder
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to decode word/document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				current.WriteString("\t")
+			case "br":
+				current.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if paragraph := strings.TrimSpace(current.String()); paragraph != "" {
+					paragraphs = append(paragraphs, paragraph)
+				}
+				current.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+
+	return strings.Join(paragraphs, "\n"), nil
+}
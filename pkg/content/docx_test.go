@@ -0,0 +1,67 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// buildDOCX builds a minimal .docx archive containing the given document.xml body
+func buildDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+	<w:body>` + body + `</w:body>
+</w:document>`,
+	}
+
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s in docx: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s in docx: %v", name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close docx archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTextFromDOCXReader(t *testing.T) {
+	docx := buildDOCX(t, `
+		<w:p><w:r><w:t>Welcome to the </w:t></w:r><w:r><w:t>Data Engineering podcast.</w:t></w:r></w:p>
+		<w:p><w:r><w:t>Today we talk about pipelines.</w:t></w:r></w:p>
+		<w:p></w:p>`)
+
+	text, err := ExtractTextFromDOCXReader(bytes.NewReader(docx))
+	if err != nil {
+		t.Fatalf("ExtractTextFromDOCXReader failed: %v", err)
+	}
+
+	expected := "Welcome to the Data Engineering podcast.\nToday we talk about pipelines."
+	if text != expected {
+		t.Errorf("Expected text %q, got %q", expected, text)
+	}
+}
+
+func TestExtractTextFromDOCXReader_InvalidZip(t *testing.T) {
+	_, err := ExtractTextFromDOCXReader(strings.NewReader("this is not a zip archive"))
+	if err == nil {
+		t.Fatal("Expected error for invalid zip, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "failed to open docx archive") {
+		t.Errorf("Expected archive error, got: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package content
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -15,26 +16,51 @@ type Extractor interface {
 }
 
 // DefaultExtractor implements the Extractor interface using the standard extraction functions
-type DefaultExtractor struct{}
+type DefaultExtractor struct {
+	baseURL *url.URL // URL of the page, passed to go-readability to resolve relative links (optional)
+}
 
 // NewDefaultExtractor creates a new default extractor
 func NewDefaultExtractor() *DefaultExtractor {
 	return &DefaultExtractor{}
 }
 
+// NewDefaultExtractorWithOptions creates a default extractor for the page at baseURL
+// go-readability resolves relative links and image references in the extracted content against it
+// A nil baseURL behaves like NewDefaultExtractor
+func NewDefaultExtractorWithOptions(baseURL *url.URL) *DefaultExtractor {
+	return &DefaultExtractor{baseURL: baseURL}
+}
+
 // ExtractTitle extracts the article title using the default extraction logic
 func (e *DefaultExtractor) ExtractTitle(htmlContent string) (string, error) {
-	return ExtractTitle(htmlContent)
+	return extractTitle(htmlContent, e.baseURL)
 }
 
 // ExtractText extracts the article text using the default extraction logic
 func (e *DefaultExtractor) ExtractText(htmlContent string) (string, error) {
-	return ExtractText(htmlContent)
+	return extractText(htmlContent, e.baseURL)
+}
+
+// ExtractContent returns the cleaned-up article HTML, with links and image references
+// resolved against the extractor's base URL when it has one
+func (e *DefaultExtractor) ExtractContent(htmlContent string) (string, error) {
+	article, err := readability.FromReader(strings.NewReader(htmlContent), e.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract content: %w", err)
+	}
+
+	return strings.TrimSpace(article.Content), nil
 }
 
 // ExtractText extracts the main article text from HTML content
 func ExtractText(htmlContent string) (string, error) {
-	article, err := readability.FromReader(strings.NewReader(htmlContent), nil)
+	return extractText(htmlContent, nil)
+}
+
+// extractText extracts the main article text, resolving links against pageURL if it is set
+func extractText(htmlContent string, pageURL *url.URL) (string, error) {
+	article, err := readability.FromReader(strings.NewReader(htmlContent), pageURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to extract text: %w", err)
 	}
@@ -44,8 +70,13 @@ func ExtractText(htmlContent string) (string, error) {
 
 // ExtractTitle extracts the article title from HTML content with fallback mechanisms
 func ExtractTitle(htmlContent string) (string, error) {
+	return extractTitle(htmlContent, nil)
+}
+
+// extractTitle is ExtractTitle with the page URL passed to go-readability
+func extractTitle(htmlContent string, pageURL *url.URL) (string, error) {
 	// Try readability first
-	article, err := readability.FromReader(strings.NewReader(htmlContent), nil)
+	article, err := readability.FromReader(strings.NewReader(htmlContent), pageURL)
 	if err == nil {
 		title := strings.TrimSpace(article.Title)
 		if title != "" {
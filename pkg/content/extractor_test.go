@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 )
@@ -119,3 +121,32 @@ func TestExtractContentUsingDirectFetch(t *testing.T) {
 		fmt.Printf("First 300 chars: %s\n", text[:min(300, len(text))])
 	}
 }
+
+func TestDefaultExtractorWithOptions_ResolvesRelativeLinks(t *testing.T) {
+	paragraph := strings.Repeat("Partitions let consumers scale horizontally across a consumer group. ", 10)
+	html := `<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1>
+		<p>` + paragraph + `See <a href="../kafka-internals">the internals post</a>.</p>
+		<p>` + paragraph + `<img src="/images/partitions.png" alt="Partitions"></p>
+	</article></body></html>`
+
+	pageURL, err := url.Parse("https://blog.example.com/posts/kafka/")
+	if err != nil {
+		t.Fatalf("Failed to parse page URL: %v", err)
+	}
+
+	extracted, err := NewDefaultExtractorWithOptions(pageURL).ExtractContent(html)
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+	if !strings.Contains(extracted, `href="https://blog.example.com/posts/kafka-internals"`) {
+		t.Errorf("Expected the relative link to be made absolute, got:\n%s", extracted)
+	}
+	if !strings.Contains(extracted, `src="https://blog.example.com/images/partitions.png"`) {
+		t.Errorf("Expected the image reference to be made absolute, got:\n%s", extracted)
+	}
+
+	text, err := NewDefaultExtractorWithOptions(pageURL).ExtractText(html)
+	if err != nil || !strings.Contains(text, "Partitions let consumers scale") {
+		t.Errorf("Expected the article text, got %q (%v)", text, err)
+	}
+}
@@ -0,0 +1,24 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrContentTooShort is returned when the text extracted from a page is shorter than the
+// configured minimum, e.g., for tag listings, paywalled stubs or "page moved" notices
+var ErrContentTooShort = errors.New("extracted text too short")
+
+// CheckTextLength returns an error wrapping ErrContentTooShort if text has fewer than minLength
+// characters, ignoring surrounding whitespace; a minLength of zero or less disables the check
+func CheckTextLength(text string, minLength int) error {
+	if minLength <= 0 {
+		return nil
+	}
+	if n := utf8.RuneCountInString(strings.TrimSpace(text)); n < minLength {
+		return fmt.Errorf("%w: %d characters, minimum is %d", ErrContentTooShort, n, minLength)
+	}
+	return nil
+}
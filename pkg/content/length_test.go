@@ -0,0 +1,28 @@
+package content
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTextLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		minLength int
+		tooShort  bool
+	}{
+		{"disabled", "", 0, false},
+		{"under minimum", "Tagged: kafka", 50, true},
+		{"surrounding whitespace ignored", "  short  \n", 6, true},
+		{"exactly minimum", "héllo", 5, false},
+		{"over minimum", "A long enough article body", 10, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckTextLength(tt.text, tt.minLength)
+		if got := errors.Is(err, ErrContentTooShort); got != tt.tooShort {
+			t.Errorf("%s: expected too short = %v, got %v", tt.name, tt.tooShort, err)
+		}
+	}
+}
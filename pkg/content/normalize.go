@@ -0,0 +1,76 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+)
+
+// boilerplateMinRepeats is how often a line must occur to be treated as a page header or footer
+const boilerplateMinRepeats = 3
+
+// boilerplateMaxLength is the longest line treated as a page header or footer; longer lines are content
+const boilerplateMaxLength = 80
+
+var (
+	// hyphenatedBreak matches a word split across lines with a hyphen, e.g., "engi-\nneering"
+	hyphenatedBreak = regexp.MustCompile(`(\p{L})-[ \t]*\n[ \t]*(\p{Ll})`)
+	inlineSpace     = regexp.MustCompile(`[ \t\f\v\p{Zs}]+`)
+	digits          = regexp.MustCompile(`\d+`)
+)
+
+// NormalizeTranscript cleans up text extracted from PDF or TXT transcripts for search:
+// words hyphenated across line breaks are joined, short lines repeated on every page
+// (e.g., the show name or "Page 3 of 40") are dropped, runs of spaces become one space and
+// runs of blank lines become a single paragraph break
+func NormalizeTranscript(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = hyphenatedBreak.ReplaceAllString(text, "$1$2")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(inlineSpace.ReplaceAllString(line, " "))
+	}
+	boilerplate := repeatedLines(lines)
+
+	var out []string
+	blank := false
+	for _, line := range lines {
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if boilerplate[boilerplateKey(line)] {
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// repeatedLines returns the keys of short lines that occur at least boilerplateMinRepeats times
+func repeatedLines(lines []string) map[string]bool {
+	counts := make(map[string]int)
+	for _, line := range lines {
+		if line != "" && len(line) <= boilerplateMaxLength {
+			counts[boilerplateKey(line)]++
+		}
+	}
+
+	repeated := make(map[string]bool)
+	for key, count := range counts {
+		if count >= boilerplateMinRepeats {
+			repeated[key] = true
+		}
+	}
+	return repeated
+}
+
+// boilerplateKey groups lines that only differ in their numbers, such as page numbers
+func boilerplateKey(line string) string {
+	return strings.ToLower(digits.ReplaceAllString(line, "#"))
+}
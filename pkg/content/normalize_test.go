@@ -0,0 +1,43 @@
+package content
+
+import "testing"
+
+func TestNormalizeTranscript(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "hyphenated line break",
+			text: "Data engi-\nneering at scale",
+			want: "Data engineering at scale",
+		},
+		{
+			name: "hyphenated compound kept",
+			text: "exactly-\nOnce semantics",
+			want: "exactly-\nOnce semantics",
+		},
+		{
+			name: "whitespace collapse",
+			text: "  Kafka \t partitions\r\n\n\n\nscale   horizontally  ",
+			want: "Kafka partitions\n\nscale horizontally",
+		},
+		{
+			name: "repeated page headers dropped",
+			text: "SE Radio 512\nWelcome to the show.\nPage 1 of 3\nSE Radio 512\nToday we talk about Kafka.\nPage 2 of 3\nSE Radio 512\nThanks for listening.\nPage 3 of 3",
+			want: "Welcome to the show.\nToday we talk about Kafka.\nThanks for listening.",
+		},
+		{
+			name: "lines repeated twice kept",
+			text: "Yes.\nWhy?\nYes.",
+			want: "Yes.\nWhy?\nYes.",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTranscript(tt.text); got != tt.want {
+			t.Errorf("%s: NormalizeTranscript() = %q, expected %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrSoftNotFound is returned for pages served with HTTP 200 that are really error pages
+// (e.g., a "Page not found" template), so they aren't stored as articles
+var ErrSoftNotFound = errors.New("soft 404: page looks like an error page")
+
+// DefaultSoftNotFoundMarkers are the error page texts checked when no markers are configured
+var DefaultSoftNotFoundMarkers = []string{
+	"Not Acceptable",
+	"Page not found",
+	"404 Not Found",
+	"404 - Not Found",
+	"Page can't be found",
+}
+
+// CheckSoftNotFound returns an error wrapping ErrSoftNotFound if the page's title or a top-level
+// heading contains one of markers (case-insensitive); a nil markers uses DefaultSoftNotFoundMarkers
+// Only the title and headings are checked, so articles that merely mention "404 Not Found" pass;
+// pages without either (e.g., a bare "Not Acceptable" body) are checked on their whole text
+func CheckSoftNotFound(htmlContent string, markers []string) error {
+	if markers == nil {
+		markers = DefaultSoftNotFoundMarkers
+	}
+	if len(markers) == 0 {
+		return nil
+	}
+
+	headline := htmlContent
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent)); err == nil {
+		var parts []string
+		doc.Find("title, h1").Each(func(_ int, s *goquery.Selection) {
+			parts = append(parts, s.Text())
+		})
+		if len(parts) > 0 {
+			headline = strings.Join(parts, "\n")
+		} else {
+			headline = doc.Text()
+		}
+	}
+
+	headline = strings.ToLower(headline)
+	for _, marker := range markers {
+		if marker = strings.TrimSpace(marker); marker != "" && strings.Contains(headline, strings.ToLower(marker)) {
+			return fmt.Errorf("%w: found %q", ErrSoftNotFound, marker)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package content
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckSoftNotFound(t *testing.T) {
+	article := "<p>" + strings.Repeat("Retries help when a server answers 404 Not Found for a moment. ", 10) + "</p>"
+	tests := []struct {
+		name     string
+		html     string
+		markers  []string
+		notFound bool
+	}{
+		{"title", `<html><head><title>404 - Not Found</title></head><body><p>Sorry</p></body></html>`, nil, true},
+		{"heading", `<html><body><h1>Oops! Page not found</h1></body></html>`, nil, true},
+		{"bare body", "Not Acceptable", nil, true},
+		{"article mentioning 404", `<html><head><title>Retrying requests</title></head><body><h1>Retrying requests</h1>` + article + `</body></html>`, nil, false},
+		{"custom marker", `<html><head><title>Nothing here</title></head></html>`, []string{"nothing here"}, true},
+		{"custom markers replace defaults", `<html><head><title>Page not found</title></head></html>`, []string{"nothing here"}, false},
+		{"disabled", `<html><head><title>Page not found</title></head></html>`, []string{}, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckSoftNotFound(tt.html, tt.markers)
+		if got := errors.Is(err, ErrSoftNotFound); got != tt.notFound {
+			t.Errorf("%s: expected soft 404 = %v, got %v", tt.name, tt.notFound, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// DefaultMaxPDFBytes caps the size of PDFs decoded when no limit is given
+// The parser works on the whole file, so the limit bounds each worker's memory
+const DefaultMaxPDFBytes int64 = 20 << 20 // 20 MiB
+
+// ErrPDFTooLarge is returned when a PDF exceeds the size limit; it is rejected before decoding
+var ErrPDFTooLarge = errors.New("pdf too large")
+
+// ExtractTextFromPDFReader extracts plain text from a PDF document of up to DefaultMaxPDFBytes
+// Pages are read in order; layout such as columns and tables is not preserved
+func ExtractTextFromPDFReader(r io.Reader) (string, error) {
+	return ExtractTextFromPDFReaderWithLimit(r, 0)
+}
+
+// ExtractTextFromPDFReaderWithLimit extracts plain text from a PDF document, failing with
+// ErrPDFTooLarge if it is larger than maxBytes
+// Zero means DefaultMaxPDFBytes; a negative value disables the limit
+// Readers that know their size (e.g., *bytes.Reader) are checked and decoded without copying
+func ExtractTextFromPDFReaderWithLimit(r io.Reader, maxBytes int64) (string, error) {
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxPDFBytes
+	}
+
+	data, size, err := pdfData(r, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	return extractPDFText(data, size)
+}
+
+// sizedReaderAt is implemented by readers such as *bytes.Reader and *strings.Reader
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// pdfData returns r as the io.ReaderAt the PDF parser needs, enforcing maxBytes
+// Sized readers are used as is; others are read into memory, stopping one byte past the limit
+func pdfData(r io.Reader, maxBytes int64) (io.ReaderAt, int64, error) {
+	if sized, ok := r.(sizedReaderAt); ok {
+		if maxBytes >= 0 && sized.Size() > maxBytes {
+			return nil, 0, fmt.Errorf("%w: %d bytes exceeds %d", ErrPDFTooLarge, sized.Size(), maxBytes)
+		}
+		return sized, sized.Size(), nil
+	}
+
+	if maxBytes >= 0 {
+		r = io.LimitReader(r, maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read pdf: %w", err)
+	}
+	if maxBytes >= 0 && int64(len(data)) > maxBytes {
+		return nil, 0, fmt.Errorf("%w: exceeds %d bytes", ErrPDFTooLarge, maxBytes)
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// extractPDFText decodes the PDF one page at a time, so only the text extracted so far and
+// the current page are held besides the file itself
+func extractPDFText(data io.ReaderAt, size int64) (text string, err error) {
+	// The PDF parser panics on some malformed files; report those as errors
+	defer func() {
+		if p := recover(); p != nil {
+			text, err = "", fmt.Errorf("failed to parse pdf: %v", p)
+		}
+	}()
+
+	doc, err := pdf.NewReader(data, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to open pdf: %w", err)
+	}
+
+	// Fonts are shared across pages, so their character maps are parsed once
+	fonts := make(map[string]*pdf.Font)
+	var buf strings.Builder
+	for i := 1; i <= doc.NumPage(); i++ {
+		page := doc.Page(i)
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		pageText, err := page.GetPlainText(fonts)
+		if err != nil {
+			return "", fmt.Errorf("failed to extract pdf text: %w", err)
+		}
+		buf.WriteString(pageText)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,86 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// buildPDF builds a minimal single-page PDF that shows each line in Helvetica
+func buildPDF(t *testing.T, lines ...string) []byte {
+	t.Helper()
+
+	var stream strings.Builder
+	stream.WriteString("BT /F1 12 Tf 72 720 Td 14 TL\n")
+	for _, line := range lines {
+		fmt.Fprintf(&stream, "(%s) Tj T*\n", line)
+	}
+	stream.WriteString("ET")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestExtractTextFromPDFReader(t *testing.T) {
+	data := buildPDF(t, "Kafka partitions", "Consumers scale horizontally")
+
+	text, err := ExtractTextFromPDFReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ExtractTextFromPDFReader failed: %v", err)
+	}
+
+	for _, want := range []string{"Kafka partitions", "Consumers scale horizontally"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text to contain %q, got %q", want, text)
+		}
+	}
+}
+
+func TestExtractTextFromPDFReader_NotAPDF(t *testing.T) {
+	_, err := ExtractTextFromPDFReader(strings.NewReader("<html><body>Not a PDF</body></html>"))
+	if err == nil {
+		t.Error("Expected an error for non-PDF input")
+	}
+}
+
+func TestExtractTextFromPDFReaderWithLimit(t *testing.T) {
+	data := buildPDF(t, "Kafka partitions")
+
+	text, err := ExtractTextFromPDFReaderWithLimit(bytes.NewReader(data), int64(len(data)))
+	if err != nil || !strings.Contains(text, "Kafka partitions") {
+		t.Fatalf("Expected a PDF within the limit to be extracted, got %q, %v", text, err)
+	}
+
+	// Not a valid PDF either, so ErrPDFTooLarge shows it was rejected before decoding
+	oversized := strings.Repeat("x", 2048)
+	if _, err := ExtractTextFromPDFReaderWithLimit(strings.NewReader(oversized), 1024); !errors.Is(err, ErrPDFTooLarge) {
+		t.Errorf("Expected ErrPDFTooLarge for a sized reader, got %v", err)
+	}
+	if _, err := ExtractTextFromPDFReaderWithLimit(io.MultiReader(strings.NewReader(oversized)), 1024); !errors.Is(err, ErrPDFTooLarge) {
+		t.Errorf("Expected ErrPDFTooLarge for a streamed reader, got %v", err)
+	}
+}
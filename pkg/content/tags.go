@@ -0,0 +1,50 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ExtractTags returns the tags or topics an article page declares, from (in order)
+// <meta property="article:tag">, <meta name="keywords"> (comma-separated) and the text of
+// rel="tag" links
+// Tags are trimmed and deduplicated case-insensitively, keeping the first spelling seen
+// Returns nil if the page declares none or can't be parsed
+func ExtractTags(htmlContent string) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.Join(strings.Fields(tag), " ")
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+
+	doc.Find("meta[property='article:tag']").Each(func(i int, meta *goquery.Selection) {
+		add(meta.AttrOr("content", ""))
+	})
+
+	doc.Find("meta[name]").Each(func(i int, meta *goquery.Selection) {
+		if !strings.EqualFold(meta.AttrOr("name", ""), "keywords") {
+			return
+		}
+		for _, keyword := range strings.Split(meta.AttrOr("content", ""), ",") {
+			add(keyword)
+		}
+	})
+
+	doc.Find("a[rel~='tag']").Each(func(i int, link *goquery.Selection) {
+		add(link.Text())
+	})
+
+	return tags
+}
@@ -0,0 +1,60 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{
+			name: "article:tag meta",
+			html: `<html><head>
+				<meta property="article:tag" content="Kafka">
+				<meta property="article:tag" content=" Stream Processing ">
+			</head><body></body></html>`,
+			want: []string{"Kafka", "Stream Processing"},
+		},
+		{
+			name: "keywords meta",
+			html: `<html><head><meta name="Keywords" content="go, concurrency,,  channels "></head><body></body></html>`,
+			want: []string{"go", "concurrency", "channels"},
+		},
+		{
+			name: "rel=tag links",
+			html: `<html><body><article>
+				<a rel="tag" href="/tag/databases">Databases</a>
+				<a rel="category tag" href="/tag/postgres">
+					Postgres
+				</a>
+				<a href="/about">About</a>
+			</article></body></html>`,
+			want: []string{"Databases", "Postgres"},
+		},
+		{
+			name: "deduplicated across sources",
+			html: `<html><head>
+				<meta property="article:tag" content="Kafka">
+				<meta name="keywords" content="kafka, streaming">
+			</head><body><a rel="tag" href="/tag/streaming">Streaming</a></body></html>`,
+			want: []string{"Kafka", "streaming"},
+		},
+		{
+			name: "no tags",
+			html: `<html><head><meta name="description" content="A post"></head><body><p>Text</p></body></html>`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractTags(tt.html); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package content
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TranscriptLinkRules describes how to recognize a transcript link on an episode page
+type TranscriptLinkRules struct {
+	Keywords   []string // Case-insensitive words matched against the link text or href (empty = any link)
+	Extensions []string // File extensions the link path must end with (e.g., ".pdf", ".txt")
+}
+
+// DefaultTranscriptLinkRules returns the rules used by FindTranscriptURL:
+// links mentioning "transcript" that point to a .pdf or .txt file
+func DefaultTranscriptLinkRules() TranscriptLinkRules {
+	return TranscriptLinkRules{
+		Keywords:   []string{"transcript"},
+		Extensions: []string{".pdf", ".txt"},
+	}
+}
+
+// FindTranscriptURL finds a transcript link in HTML content using the default rules
+func FindTranscriptURL(htmlContent string) (string, error) {
+	return FindTranscriptURLWithRules(htmlContent, DefaultTranscriptLinkRules())
+}
+
+// FindTranscriptURLWithRules finds the first link in HTML content matching the given rules
+// A link matches when its path ends with one of the extensions and its text or href
+// contains one of the keywords
+func FindTranscriptURLWithRules(htmlContent string, rules TranscriptLinkRules) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var found string
+	doc.Find("a[href]").EachWithBreak(func(i int, link *goquery.Selection) bool {
+		href := strings.TrimSpace(link.AttrOr("href", ""))
+		if href == "" {
+			return true
+		}
+
+		if !hasTranscriptExtension(href, rules.Extensions) {
+			return true
+		}
+
+		if !containsTranscriptKeyword(link.Text()+" "+href, rules.Keywords) {
+			return true
+		}
+
+		found = href
+		return false
+	})
+
+	if found == "" {
+		return "", fmt.Errorf("transcript link not found in HTML")
+	}
+
+	return found, nil
+}
+
+// hasTranscriptExtension checks if the href path ends with one of the extensions
+func hasTranscriptExtension(href string, extensions []string) bool {
+	path := href
+	if parsed, err := url.Parse(href); err == nil {
+		path = parsed.Path
+	}
+	path = strings.ToLower(path)
+
+	for _, ext := range extensions {
+		if strings.HasSuffix(path, strings.ToLower(ext)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTranscriptKeyword checks if the text contains one of the keywords
+// An empty keyword list matches any text
+func containsTranscriptKeyword(text string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+
+	lowerText := strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(lowerText, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package content
+
+import "testing"
+
+func TestFindTranscriptURL_DefaultRules(t *testing.T) {
+	html := `<html><body>
+		<a href="/about">About</a>
+		<a href="https://example.com/files/episode-1.pdf">Download transcript</a>
+	</body></html>`
+
+	transcriptURL, err := FindTranscriptURL(html)
+	if err != nil {
+		t.Fatalf("FindTranscriptURL failed: %v", err)
+	}
+
+	if transcriptURL != "https://example.com/files/episode-1.pdf" {
+		t.Errorf("Expected PDF transcript URL, got '%s'", transcriptURL)
+	}
+}
+
+func TestFindTranscriptURLWithRules_CustomKeyword(t *testing.T) {
+	html := `<html><body>
+		<a href="https://example.com/files/episode-1.pdf">Read the full text</a>
+	</body></html>`
+
+	// Default rules don't recognize the link text
+	if _, err := FindTranscriptURL(html); err == nil {
+		t.Fatal("Expected default rules to miss a link without the 'transcript' keyword")
+	}
+
+	rules := DefaultTranscriptLinkRules()
+	rules.Keywords = []string{"read the full text"}
+
+	transcriptURL, err := FindTranscriptURLWithRules(html, rules)
+	if err != nil {
+		t.Fatalf("FindTranscriptURLWithRules failed: %v", err)
+	}
+
+	if transcriptURL != "https://example.com/files/episode-1.pdf" {
+		t.Errorf("Expected PDF transcript URL, got '%s'", transcriptURL)
+	}
+}
+
+func TestFindTranscriptURLWithRules_DOCXExtension(t *testing.T) {
+	html := `<html><body>
+		<a href="https://example.com/episode-1">Transcript page</a>
+		<a href="https://example.com/files/Episode-1.DOCX?dl=1">Transcript</a>
+	</body></html>`
+
+	rules := TranscriptLinkRules{
+		Keywords:   []string{"transcript"},
+		Extensions: []string{".docx"},
+	}
+
+	transcriptURL, err := FindTranscriptURLWithRules(html, rules)
+	if err != nil {
+		t.Fatalf("FindTranscriptURLWithRules failed: %v", err)
+	}
+
+	if transcriptURL != "https://example.com/files/Episode-1.DOCX?dl=1" {
+		t.Errorf("Expected DOCX transcript URL, got '%s'", transcriptURL)
+	}
+}
+
+func TestFindTranscriptURL_NotFound(t *testing.T) {
+	html := `<html><body><a href="/episode.mp3">Listen</a></body></html>`
+
+	if _, err := FindTranscriptURL(html); err == nil {
+		t.Fatal("Expected error when no transcript link exists, got nil")
+	}
+}
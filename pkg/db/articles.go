@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog-search/pkg/db/migrate"
+	"blog-search/pkg/domain"
+)
+
+// articleMigrations is the Postgres article schema, applied in order by migrate.Apply.
+// Append new steps with the next ID; never edit or renumber a step that has shipped.
+var articleMigrations = []migrate.Migration{
+	{
+		// Keep schema simple: url is the primary key, which also gives us uniqueness.
+		//
+		// NOTE: we default crawled_at to now() so older Mongo docs missing crawled_at
+		// can still be inserted (implementation still sets it explicitly when present).
+		ID:   1,
+		Name: "create article table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS article (
+  url TEXT PRIMARY KEY,
+  title TEXT NOT NULL DEFAULT '',
+  text TEXT NOT NULL DEFAULT '',
+  crawled_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`,
+	},
+	{
+		// Full-text search support: a generated tsvector over title + text with a GIN index.
+		ID:   2,
+		Name: "add article search column",
+		SQL: `
+ALTER TABLE article ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', title || ' ' || text)) STORED;`,
+	},
+	{
+		ID:   3,
+		Name: "create article search index",
+		SQL: `
+CREATE INDEX IF NOT EXISTS article_search_vector_idx ON article USING GIN (search_vector);`,
+	},
+}
+
+// sqliteArticleDDL is the SQLite article table.
+// SQLite has no now() and only decodes TIMESTAMP-typed columns back into time values.
+const sqliteArticleDDL = `
+CREATE TABLE IF NOT EXISTS article (
+  url TEXT PRIMARY KEY,
+  title TEXT NOT NULL DEFAULT '',
+  text TEXT NOT NULL DEFAULT '',
+  crawled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// SQLArticleRepository implements ArticleRepository over a sql.DB handle.
+// The same queries serve Postgres and Supabase; SQLite gets its own schema and has no search.
+type SQLArticleRepository struct {
+	db     *sql.DB
+	sqlite bool
+}
+
+// NewSQLArticleRepository returns a repository for the article table in a Postgres database.
+func NewSQLArticleRepository(db *sql.DB) *SQLArticleRepository {
+	return &SQLArticleRepository{db: db}
+}
+
+// EnsureSchema creates the article table and its search index if needed.
+// It returns how many schema migrations were applied; SQLite tables are created without migrations.
+func (r *SQLArticleRepository) EnsureSchema(ctx context.Context) (int, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("database not connected")
+	}
+
+	if r.sqlite {
+		if _, err := r.db.ExecContext(ctx, sqliteArticleDDL); err != nil {
+			return 0, fmt.Errorf("create article table: %w", err)
+		}
+		// Full-text search is Postgres-only
+		return 0, nil
+	}
+
+	// The steps use IF NOT EXISTS, so databases created before migrations were tracked upgrade cleanly
+	applied, err := migrate.Apply(ctx, r.db, articleMigrations)
+	if err != nil {
+		return applied, fmt.Errorf("migrate article schema: %w", err)
+	}
+	return applied, nil
+}
+
+// ExistingURLs returns which of urls are already in the article table.
+// Empty URLs are ignored.
+func (r *SQLArticleRepository) ExistingURLs(ctx context.Context, urls []string) (map[string]bool, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	args := make([]interface{}, 0, len(urls))
+	for _, url := range urls {
+		if url != "" {
+			args = append(args, url)
+		}
+	}
+	if len(args) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, buildURLInQuery(args), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query existing urls: %w", err)
+	}
+	defer rows.Close()
+
+	set := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("scan url: %w", err)
+		}
+		if url != "" {
+			set[url] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return set, nil
+}
+
+// buildURLInQuery builds a SELECT with one IN placeholder per URL.
+// Uses a unique identifier to prevent prepared statement cache conflicts in parallel execution.
+func buildURLInQuery(urls []interface{}) string {
+	// Each batch gets a unique query based on the number of URLs and a hash of the first URL
+	// This prevents pgx from trying to cache the same prepared statement across goroutines
+	var hashSuffix string
+	if urlStr, ok := urls[0].(string); ok {
+		hash := md5.Sum([]byte(urlStr))
+		hashSuffix = fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes of hash
+	}
+
+	var query strings.Builder
+	fmt.Fprintf(&query, `/* q_%d_%s */ SELECT url FROM article WHERE url IN (`, len(urls), hashSuffix)
+	for i := range urls {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "$%d", i+1)
+	}
+	query.WriteString(")")
+	return query.String()
+}
+
+// InsertBatch writes articles in one transaction and returns the number of rows written.
+// Existing URLs are left alone unless upsert is set, in which case a row is overwritten
+// when the incoming copy was crawled more recently. Articles without a URL are skipped.
+func (r *SQLArticleRepository) InsertBatch(ctx context.Context, articles []domain.Article, upsert bool) (int, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("database not connected")
+	}
+
+	const insertQuery = `
+INSERT INTO article (url, title, text, crawled_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (url) DO NOTHING`
+
+	// Only overwrite rows when the incoming copy was crawled more recently.
+	const upsertQuery = `
+INSERT INTO article (url, title, text, crawled_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (url) DO UPDATE SET title = EXCLUDED.title, text = EXCLUDED.text, crawled_at = EXCLUDED.crawled_at
+WHERE article.crawled_at < EXCLUDED.crawled_at`
+
+	query := insertQuery
+	if upsert {
+		query = upsertQuery
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	written := 0
+	for _, a := range articles {
+		if a.URL == "" {
+			continue
+		}
+		res, err := stmt.ExecContext(ctx, a.URL, a.Title, a.Text, a.CrawledAt)
+		if err != nil {
+			return 0, fmt.Errorf("insert article url=%q: %w", a.URL, err)
+		}
+		// ON CONFLICT DO NOTHING (or a non-newer upsert) affects zero rows
+		if n, err := res.RowsAffected(); err == nil {
+			written += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return written, nil
+}
+
+// MaxCrawledAt returns the newest crawled_at in the article table, or the zero time if the table is empty.
+func (r *SQLArticleRepository) MaxCrawledAt(ctx context.Context) (time.Time, error) {
+	if r.db == nil {
+		return time.Time{}, fmt.Errorf("database not connected")
+	}
+
+	// Postgres returns a time; SQLite returns the stored text since max() drops the column type.
+	var maxCrawledAt interface{}
+	if err := r.db.QueryRowContext(ctx, `SELECT max(crawled_at) FROM article`).Scan(&maxCrawledAt); err != nil {
+		return time.Time{}, fmt.Errorf("query max crawled_at: %w", err)
+	}
+
+	switch v := maxCrawledAt.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case string:
+		return parseSQLiteTime(v)
+	case []byte:
+		return parseSQLiteTime(string(v))
+	default:
+		return time.Time{}, fmt.Errorf("unexpected max crawled_at type %T", maxCrawledAt)
+	}
+}
+
+// parseSQLiteTime parses a timestamp stored by the SQLite driver (or by CURRENT_TIMESTAMP).
+func parseSQLiteTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parse crawled_at %q: unknown time format", s)
+}
+
+// Search runs a full-text search over the article table.
+// Matches are ranked by ts_rank and the top limit rows are returned; limit defaults to 10.
+//
+// Requires the search_vector column created by EnsureSchema, so it isn't available on SQLite.
+func (r *SQLArticleRepository) Search(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	if r.sqlite {
+		return nil, fmt.Errorf("full-text search is not supported on SQLite")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	const searchQuery = `
+SELECT url, title, text, crawled_at
+FROM article
+WHERE search_vector @@ plainto_tsquery('english', $1)
+ORDER BY ts_rank(search_vector, plainto_tsquery('english', $1)) DESC
+LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, searchQuery, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search articles: %w", err)
+	}
+	defer rows.Close()
+
+	var out []domain.Article
+	for rows.Next() {
+		var a domain.Article
+		if err := rows.Scan(&a.URL, &a.Title, &a.Text, &a.CrawledAt); err != nil {
+			return nil, fmt.Errorf("scan article: %w", err)
+		}
+		a.Host = domain.HostFromURL(a.URL)
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return out, nil
+}
@@ -2,49 +2,168 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"blog-search/pkg/domain"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// Client is the MongoDB-backed ArticleStore
+var _ ArticleStore = (*Client)(nil)
+
+// Client keeps the URLs that failed to fetch in FailedURLsCollection
+var _ FailedURLRecorder = (*Client)(nil)
+
+// Client saves batches of articles with one BulkWrite
+var _ BulkSaver = (*Client)(nil)
+
+// Client keeps paginated crawl checkpoints in CrawlStateCollection
+var _ CrawlCheckpointer = (*Client)(nil)
+
+// ErrArticleNotFound is returned when no article matches a lookup
+var ErrArticleNotFound = errors.New("article not found")
+
+// FailedURLsCollection is the collection, next to the articles collection, that holds URLs
+// whose content couldn't be fetched (see RecordFailedURL)
+const FailedURLsCollection = "failed_urls"
+
+// CrawlStateCollection is the collection, next to the articles collection, that holds the
+// checkpoints of paginated crawls, one per base URL (see SaveCrawlCheckpoint)
+const CrawlStateCollection = "crawl_state"
+
+// SeenURLsCollection is the collection, next to the articles collection, that holds the URLs
+// recurring crawls already attempted (see SeenURLs)
+const SeenURLsCollection = "seen_urls"
+
 // Client wraps the MongoDB client and database connection
 type Client struct {
 	mongoClient *mongo.Client
 	database    *mongo.Database
 	collection  *mongo.Collection
+	failedURLs  *mongo.Collection
+	crawlState  *mongo.Collection
+	seenURLs    *mongo.Collection
+
+	// Used by SaveArticle and Ping; the collection and mongo client in production, fakes in tests
+	writes articleWriter
+	pinger pinger
+
+	indexesEnsured atomic.Bool // Set once EnsureIndexes succeeded
+
+	initErr error // Why NewClient couldn't create the mongo client, reported by Connect
+}
+
+// articleWriter is the subset of *mongo.Collection used to save articles
+type articleWriter interface {
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+}
+
+// pinger is the subset of *mongo.Client used to check the connection
+type pinger interface {
+	Ping(ctx context.Context, rp *readpref.ReadPref) error
 }
 
 // NewClient creates a new database client
+// Errors creating the client (e.g., an invalid connection string) are reported by Connect;
+// use NewClientE to get them immediately
 func NewClient(connectionString, databaseName, collectionName string) *Client {
-	clientOptions := options.Client().ApplyURI(connectionString)
+	client, err := NewClientE(connectionString, databaseName, collectionName)
+	if err != nil {
+		return &Client{initErr: err}
+	}
+	return client
+}
+
+// NewClientE creates a new database client and returns an error if the client can't be created
+// It doesn't contact the server; call Connect to verify the connection
+func NewClientE(connectionString, databaseName, collectionName string) (*Client, error) {
+	return NewClientWithOptions(MongoOptions{
+		ConnectionString: connectionString,
+		DatabaseName:     databaseName,
+		CollectionName:   collectionName,
+	})
+}
+
+// MongoOptions configures a Client created by NewClientWithOptions
+// Zero pool settings leave the driver defaults in place
+type MongoOptions struct {
+	ConnectionString string
+	DatabaseName     string
+	CollectionName   string
+
+	MaxPoolSize     uint64        // Max connections per server (driver default: 100)
+	MinPoolSize     uint64        // Connections kept open per server even when idle
+	MaxConnIdleTime time.Duration // Close connections idle for longer than this
+}
+
+// clientOptions builds the driver options for opts
+func (opts MongoOptions) clientOptions() *options.ClientOptions {
+	clientOptions := options.Client().ApplyURI(opts.ConnectionString)
+	if opts.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(opts.MaxPoolSize)
+	}
+	if opts.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(opts.MinPoolSize)
+	}
+	if opts.MaxConnIdleTime > 0 {
+		clientOptions.SetMaxConnIdleTime(opts.MaxConnIdleTime)
+	}
+	return clientOptions
+}
+
+// NewClientWithOptions creates a new database client with connection pool settings
+// (e.g., a larger MaxPoolSize for jobs with many concurrent workers)
+func NewClientWithOptions(opts MongoOptions) (*Client, error) {
+	clientOptions := opts.clientOptions()
 	mongoClient, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
-		// Return client with nil - error will be caught during Connect()
-		return &Client{}
+		return nil, fmt.Errorf("failed to create mongo client: %w", err)
 	}
 
-	database := mongoClient.Database(databaseName)
-	collection := database.Collection(collectionName)
+	database := mongoClient.Database(opts.DatabaseName)
+	collection := database.Collection(opts.CollectionName)
 
 	return &Client{
 		mongoClient: mongoClient,
 		database:    database,
 		collection:  collection,
-	}
+		failedURLs:  database.Collection(FailedURLsCollection),
+		crawlState:  database.Collection(CrawlStateCollection),
+		seenURLs:    database.Collection(SeenURLsCollection),
+		writes:      collection,
+		pinger:      mongoClient,
+	}, nil
 }
 
 // Connect establishes connection to MongoDB
 func (c *Client) Connect(ctx context.Context) error {
+	if c.initErr != nil {
+		return fmt.Errorf("mongo client not initialized: %w", c.initErr)
+	}
 	if c.mongoClient == nil {
-		return fmt.Errorf("mongo client not initialized")
+		return fmt.Errorf("mongo client not initialized: create the client with NewClient or NewClientE")
 	}
 	return c.mongoClient.Ping(ctx, nil)
 }
 
+// Ping checks that the database is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	if c.pinger == nil {
+		return fmt.Errorf("mongo client not initialized")
+	}
+	return c.pinger.Ping(ctx, nil)
+}
+
 // Close closes the MongoDB connection
 func (c *Client) Close(ctx context.Context) error {
 	if c.mongoClient == nil {
@@ -53,19 +172,189 @@ func (c *Client) Close(ctx context.Context) error {
 	return c.mongoClient.Disconnect(ctx)
 }
 
+// withDerivedFields returns a copy of article with the fields derived at save time filled in:
+// the host, so per-host queries don't need URL scans, and the content hash. The caller's article
+// is left unchanged
+func withDerivedFields(article *domain.Article) *domain.Article {
+	derived := *article
+	if derived.Host == "" {
+		derived.Host = domain.HostFromURL(derived.URL)
+	}
+	if derived.ContentHash == "" {
+		derived.ContentHash = domain.ContentHash(derived.Text)
+	}
+	return &derived
+}
+
+// articleUpdate is one UpdateOne call of a guarded article save
+type articleUpdate struct {
+	filter bson.M
+	update bson.M
+	upsert bool
+}
+
+// guardedArticleUpdates returns the updates that save article (keyed on URL) without clobbering
+// better data: an article with empty text (e.g., a failed extraction) is only inserted, never
+// replacing a stored one, and a stored article is only replaced by one crawled at the same time or later
+// The first update replaces an existing article and the second inserts a missing one, so a save that
+// matched with the first is done
+func guardedArticleUpdates(article *domain.Article) []articleUpdate {
+	insert := articleUpdate{
+		filter: bson.M{"url": article.URL},
+		update: bson.M{"$setOnInsert": article},
+		upsert: true,
+	}
+	if strings.TrimSpace(article.Text) == "" {
+		return []articleUpdate{insert}
+	}
+
+	replace := articleUpdate{
+		filter: bson.M{"url": article.URL, "$or": bson.A{
+			bson.M{"crawled_at": bson.M{"$lte": article.CrawledAt}},
+			bson.M{"crawled_at": bson.M{"$exists": false}},
+		}},
+		update: bson.M{"$set": article},
+	}
+	return []articleUpdate{replace, insert}
+}
+
 // SaveArticle saves an article to the database
+// The URL is the unique identifier; see guardedArticleUpdates for when a stored article is replaced
 func (c *Client) SaveArticle(ctx context.Context, article *domain.Article) error {
+	if c.writes == nil {
+		return fmt.Errorf("collection not initialized")
+	}
+
+	for _, u := range guardedArticleUpdates(withDerivedFields(article)) {
+		result, err := c.writes.UpdateOne(ctx, u.filter, u.update, options.Update().SetUpsert(u.upsert))
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// SaveArticles upserts many articles (keyed on URL, like SaveArticle) in one unordered BulkWrite
+// Stored articles are only replaced under the same rules as SaveArticle
+// A failure of some writes doesn't stop the others; the returned *BulkSaveError lists the URLs
+// that weren't saved. If the whole request fails, every URL is listed, since upserts are safe to retry
+func (c *Client) SaveArticles(ctx context.Context, articles []*domain.Article) error {
 	if c.collection == nil {
 		return fmt.Errorf("collection not initialized")
 	}
+	if len(articles) == 0 {
+		return nil
+	}
+
+	// The replace and insert of an article give the same result in either order,
+	// so they can run unordered; owners maps each model back to its article
+	models := make([]mongo.WriteModel, 0, 2*len(articles))
+	owners := make([]int, 0, 2*len(articles))
+	for i, article := range articles {
+		for _, u := range guardedArticleUpdates(withDerivedFields(article)) {
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(u.filter).
+				SetUpdate(u.update).
+				SetUpsert(u.upsert))
+			owners = append(owners, i)
+		}
+	}
+
+	_, err := c.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return bulkSaveError(articles, owners, err)
+}
+
+// bulkSaveError converts a BulkWrite error into a *BulkSaveError naming the failed articles
+// owners maps each write model's index to its article's index; nil means one model per article
+func bulkSaveError(articles []*domain.Article, owners []int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		failed := make([]string, len(articles))
+		for i, article := range articles {
+			failed[i] = article.URL
+		}
+		return &BulkSaveError{FailedURLs: failed, Total: len(articles), Err: err}
+	}
 
-	// Use URL as unique identifier for upsert operation
-	filter := bson.M{"url": article.URL}
-	update := bson.M{"$set": article}
+	failed := make([]string, 0, len(bulkErr.WriteErrors))
+	reported := make(map[int]bool, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		index := writeErr.Index
+		if owners != nil {
+			if index < 0 || index >= len(owners) {
+				continue
+			}
+			index = owners[index]
+		}
+		if index >= 0 && index < len(articles) && !reported[index] {
+			reported[index] = true
+			failed = append(failed, articles[index].URL)
+		}
+	}
+	return &BulkSaveError{FailedURLs: failed, Total: len(articles), Err: err}
+}
+
+// SaveArticleWithRetry saves an article like SaveArticle, but when the save fails with a
+// network error (e.g., Mongo dropped the connection during a long crawl) it pings the
+// database and retries once
+func (c *Client) SaveArticleWithRetry(ctx context.Context, article *domain.Article) error {
+	err := c.SaveArticle(ctx, article)
+	if err == nil || !isTransientError(err) {
+		return err
+	}
+
+	if pingErr := c.Ping(ctx); pingErr != nil {
+		return fmt.Errorf("save failed and database is unreachable: %w", errors.Join(err, pingErr))
+	}
+	if err := c.SaveArticle(ctx, article); err != nil {
+		return fmt.Errorf("save failed after reconnect: %w", err)
+	}
+	return nil
+}
+
+// SaveArticleIfNewer saves an article unless it would clobber better data: articles with empty
+// text are never saved, and a stored article is only replaced by one with a newer CrawledAt
+// Returns whether the article was stored
+func (c *Client) SaveArticleIfNewer(ctx context.Context, article *domain.Article) (bool, error) {
+	if c.writes == nil {
+		return false, fmt.Errorf("collection not initialized")
+	}
+	// A failed or empty extraction must not replace a good body
+	if strings.TrimSpace(article.Text) == "" {
+		return false, nil
+	}
+
+	article = withDerivedFields(article)
+
+	// Replace the stored article only if it is older; this matches nothing for newer or missing ones
+	filter := bson.M{"url": article.URL, "crawled_at": bson.M{"$lt": article.CrawledAt}}
+	result, err := c.writes.UpdateOne(ctx, filter, bson.M{"$set": article})
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+
+	// Insert if the URL isn't stored yet; $setOnInsert leaves an existing newer article untouched
 	opts := options.Update().SetUpsert(true)
+	result, err = c.writes.UpdateOne(ctx, bson.M{"url": article.URL}, bson.M{"$setOnInsert": article}, opts)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
 
-	_, err := c.collection.UpdateOne(ctx, filter, update, opts)
-	return err
+// isTransientError reports whether err is a network error or timeout worth retrying
+func isTransientError(err error) bool {
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err)
 }
 
 // GetAllURLs fetches all URLs from the database and returns them as a map (set)
@@ -101,6 +390,141 @@ func (c *Client) GetAllURLs(ctx context.Context) (map[string]bool, error) {
 	return urlSet, nil
 }
 
+// existingURLsBatchSize caps the number of URLs sent in a single $in query
+const existingURLsBatchSize = 1000
+
+// GetExistingArticleURLs returns the subset of candidate URLs that are already stored
+// Only the candidates are queried (in batches), so this stays cheap for large collections
+func (c *Client) GetExistingArticleURLs(ctx context.Context, candidates []string) (map[string]bool, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+
+	existing := make(map[string]bool)
+	for start := 0; start < len(candidates); start += existingURLsBatchSize {
+		end := start + existingURLsBatchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+
+		filter := bson.M{"url": bson.M{"$in": candidates[start:end]}}
+		cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"url": 1, "_id": 0}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query existing URLs: %w", err)
+		}
+
+		for cursor.Next(ctx) {
+			var result struct {
+				URL string `bson:"url"`
+			}
+			if err := cursor.Decode(&result); err != nil {
+				continue // Skip invalid documents
+			}
+			if result.URL != "" {
+				existing[result.URL] = true
+			}
+		}
+
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cursor error: %w", err)
+		}
+	}
+
+	return existing, nil
+}
+
+// GetArticleCrawlTimes returns when each of the candidate URLs that is stored was crawled
+// URLs that aren't stored are missing from the map; articles saved without a crawl time map to
+// the zero time. Candidates are queried in batches like GetExistingArticleURLs
+func (c *Client) GetArticleCrawlTimes(ctx context.Context, candidates []string) (map[string]time.Time, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+
+	crawled := make(map[string]time.Time)
+	for start := 0; start < len(candidates); start += existingURLsBatchSize {
+		end := start + existingURLsBatchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+
+		filter := bson.M{"url": bson.M{"$in": candidates[start:end]}}
+		projection := bson.M{"url": 1, "crawled_at": 1, "_id": 0}
+		cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(projection))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query crawl times: %w", err)
+		}
+
+		for cursor.Next(ctx) {
+			var result struct {
+				URL       string    `bson:"url"`
+				CrawledAt time.Time `bson:"crawled_at"`
+			}
+			if err := cursor.Decode(&result); err != nil {
+				continue // Skip invalid documents
+			}
+			if result.URL != "" {
+				crawled[result.URL] = result.CrawledAt
+			}
+		}
+
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cursor error: %w", err)
+		}
+	}
+
+	return crawled, nil
+}
+
+// ArticleExistsByHash checks if an article with the given content hash is already stored under
+// a URL other than excludeURL, so re-saving an article doesn't count as a duplicate of itself
+func (c *Client) ArticleExistsByHash(ctx context.Context, hash, excludeURL string) (bool, error) {
+	if c.collection == nil {
+		return false, fmt.Errorf("collection not initialized")
+	}
+	if hash == "" {
+		return false, nil
+	}
+
+	filter := bson.M{"content_hash": hash, "url": bson.M{"$ne": excludeURL}}
+	count, err := c.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check content hash: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetArticleByURL fetches a single article by URL
+// An article stored under its canonical URL is also found by the URL it was fetched from
+// (see domain.Article.FetchedURL); an article stored under url itself takes precedence
+// Returns ErrArticleNotFound if no article is stored for the URL
+func (c *Client) GetArticleByURL(ctx context.Context, url string) (*domain.Article, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+
+	// Project all article fields, leaving out internal ones like _id
+	opts := options.FindOne().SetProjection(bson.M{"_id": 0})
+
+	for _, filter := range []bson.M{{"url": url}, {"fetched_url": url}} {
+		var article domain.Article
+		err := c.collection.FindOne(ctx, filter, opts).Decode(&article)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get article %s: %w", url, err)
+		}
+		return &article, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrArticleNotFound, url)
+}
+
 // GetAllArticles fetches all articles from the configured collection.
 //
 // NOTE: This reads everything into memory. If this becomes large, we can switch
@@ -130,3 +554,481 @@ func (c *Client) GetAllArticles(ctx context.Context) ([]domain.Article, error) {
 	}
 	return out, nil
 }
+
+// IterateArticles calls fn for each stored article, decoding them from the cursor one at a time,
+// so collections larger than memory can be processed. Documents that fail to decode are skipped,
+// as in GetAllArticles. Iteration stops at fn's first error, which is returned as is
+func (c *Client) IterateArticles(ctx context.Context, fn func(*domain.Article) error) error {
+	return c.iterateArticles(ctx, bson.M{}, options.Find(), fn)
+}
+
+// IterateArticlesSince is like IterateArticles for the articles crawled strictly after t, oldest first
+func (c *Client) IterateArticlesSince(ctx context.Context, t time.Time, fn func(*domain.Article) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "crawled_at", Value: 1}})
+	return c.iterateArticles(ctx, bson.M{"crawled_at": bson.M{"$gt": t}}, opts, fn)
+}
+
+// iterateArticles calls fn for each article matching filter
+func (c *Client) iterateArticles(ctx context.Context, filter interface{}, opts *options.FindOptions, fn func(*domain.Article) error) error {
+	if c.collection == nil {
+		return fmt.Errorf("collection not initialized")
+	}
+
+	cursor, err := c.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to query articles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var a domain.Article
+		if err := cursor.Decode(&a); err != nil {
+			continue // Skip invalid documents
+		}
+		if err := fn(&a); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+	return nil
+}
+
+// tagCollation compares tags case-insensitively ("Kafka" matches "kafka")
+var tagCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// GetArticlesByTag returns the articles tagged with tag (case-insensitive), newest first
+func (c *Client) GetArticlesByTag(ctx context.Context, tag string) ([]domain.Article, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+
+	opts := options.Find().
+		SetCollation(tagCollation).
+		SetSort(bson.D{{Key: "crawled_at", Value: -1}})
+	cursor, err := c.collection.Find(ctx, bson.M{"tags": strings.TrimSpace(tag)}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query articles tagged %q: %w", tag, err)
+	}
+	defer cursor.Close(ctx)
+
+	var out []domain.Article
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode articles: %w", err)
+	}
+	return out, nil
+}
+
+// GetArticlesPaged fetches one page of articles, newest first (by crawled_at)
+// Ties are broken by _id so page boundaries are stable across calls
+func (c *Client) GetArticlesPaged(ctx context.Context, offset, limit int) ([]domain.Article, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must be non-negative, got %d", offset)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "crawled_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := c.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query articles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var out []domain.Article
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode articles: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteArticle deletes the article with the given URL
+// Deleting a URL that isn't stored is not an error
+func (c *Client) DeleteArticle(ctx context.Context, url string) error {
+	if c.collection == nil {
+		return fmt.Errorf("collection not initialized")
+	}
+
+	if _, err := c.collection.DeleteOne(ctx, bson.M{"url": url}); err != nil {
+		return fmt.Errorf("failed to delete article %s: %w", url, err)
+	}
+	return nil
+}
+
+// DeleteArticlesOlderThan deletes articles crawled before t and returns how many were removed
+// Returns 0 (not an error) when nothing matches
+func (c *Client) DeleteArticlesOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	if c.collection == nil {
+		return 0, fmt.Errorf("collection not initialized")
+	}
+
+	result, err := c.collection.DeleteMany(ctx, bson.M{"crawled_at": bson.M{"$lt": t}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale articles: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates the indexes used by article queries (idempotent)
+func (c *Client) EnsureIndexes(ctx context.Context) error {
+	if c.collection == nil {
+		return fmt.Errorf("collection not initialized")
+	}
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "host", Value: 1}}},
+		{Keys: bson.D{{Key: "content_hash", Value: 1}}},
+		// Backs GetArticleByURL's lookup of articles stored under their canonical URL
+		{Keys: bson.D{{Key: "fetched_url", Value: 1}}, Options: options.Index().SetSparse(true)},
+		// Uses tagCollation so GetArticlesByTag's case-insensitive lookups can use it
+		{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetCollation(tagCollation)},
+		// Text index backing SearchArticles
+		{Keys: bson.D{{Key: "title", Value: "text"}, {Key: "text", Value: "text"}}},
+	}
+
+	if _, err := c.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	c.indexesEnsured.Store(true)
+	return nil
+}
+
+// SearchArticles runs a full-text search over article titles and text
+// Returns up to limit articles sorted by relevance (text score)
+func (c *Client) SearchArticles(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	// $text queries require the text index
+	if !c.indexesEnsured.Load() {
+		if err := c.EnsureIndexes(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+	opts := options.Find().
+		SetProjection(score).
+		SetSort(score).
+		SetLimit(int64(limit))
+
+	cursor, err := c.collection.Find(ctx, bson.M{"$text": bson.M{"$search": query}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var out []domain.Article
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+	return out, nil
+}
+
+// CountArticles counts the stored articles, or with a non-empty hostFilter, those of that host
+// (case-insensitive, ignoring "www."); articles saved before the host field existed are matched
+// on their URL's host. It counts on the server, so nothing is loaded
+func (c *Client) CountArticles(ctx context.Context, hostFilter string) (int64, error) {
+	if c.collection == nil {
+		return 0, fmt.Errorf("collection not initialized")
+	}
+
+	filter := bson.M{}
+	if host := domain.NormalizeHost(hostFilter); host != "" {
+		filter = bson.M{"$or": bson.A{
+			bson.M{"host": host},
+			bson.M{
+				"host": bson.M{"$in": bson.A{nil, ""}},
+				"url":  primitive.Regex{Pattern: hostURLPattern(host), Options: "i"},
+			},
+		}}
+	}
+
+	count, err := c.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count articles: %w", err)
+	}
+	return count, nil
+}
+
+// hostURLPattern returns a regex matching URLs on host, with or without "www." and a port
+func hostURLPattern(host string) string {
+	return `^[a-z][a-z0-9+.-]*://(?:[^@/]*@)?(?:www\.)?` + regexp.QuoteMeta(host) + `(?:[/:?#]|$)`
+}
+
+// articleHostExpr is an aggregation expression for an article's host: the stored host field,
+// or for articles saved before it existed, the host parsed from the URL (lowercased, without "www.")
+var articleHostExpr = bson.M{"$cond": bson.A{
+	bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$host", ""}}, ""}},
+	"$host",
+	bson.M{"$let": bson.M{
+		"vars": bson.M{"match": bson.M{"$regexFind": bson.M{
+			"input":   "$url",
+			"regex":   `^[a-z][a-z0-9+.-]*://(?:[^@/]*@)?(?:www\.)?([^/:?#]+)`,
+			"options": "i",
+		}}},
+		"in": bson.M{"$toLower": bson.M{"$arrayElemAt": bson.A{"$$match.captures", 0}}},
+	}},
+}}
+
+// CountByHost returns the number of stored articles per host
+// Articles whose host can't be determined are left out
+func (c *Client) CountByHost(ctx context.Context) (map[string]int, error) {
+	return c.countGrouped(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": articleHostExpr, "count": bson.M{"$sum": 1}}}},
+	})
+}
+
+// CountByDay returns the number of stored articles per crawl day, keyed by UTC date ("2006-01-02")
+// Articles without a crawled_at date are left out
+func (c *Client) CountByDay(ctx context.Context) (map[string]int, error) {
+	return c.countGrouped(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"crawled_at": bson.M{"$type": "date"}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$crawled_at"}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+}
+
+// countGrouped runs an aggregation that ends in a $group with a string _id and a count field,
+// and returns the counts keyed by _id; groups with an empty or missing key are skipped
+func (c *Client) countGrouped(ctx context.Context, pipeline mongo.Pipeline) (map[string]int, error) {
+	if c.collection == nil {
+		return nil, fmt.Errorf("collection not initialized")
+	}
+
+	cursor, err := c.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate articles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[string]int)
+	for cursor.Next(ctx) {
+		var result struct {
+			Key   *string `bson:"_id"`
+			Count int     `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue // Skip invalid documents
+		}
+		if result.Key != nil && *result.Key != "" {
+			counts[*result.Key] = result.Count
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// BackfillArticleHosts sets the host field on articles saved before it existed
+// Returns the number of updated articles
+func (c *Client) BackfillArticleHosts(ctx context.Context) (int64, error) {
+	if c.collection == nil {
+		return 0, fmt.Errorf("collection not initialized")
+	}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"host": bson.M{"$exists": false}},
+		bson.M{"host": ""},
+	}}
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"url": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query articles without host: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var updated int64
+	for cursor.Next(ctx) {
+		var result struct {
+			ID  interface{} `bson:"_id"`
+			URL string      `bson:"url"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue // Skip invalid documents
+		}
+
+		host := domain.HostFromURL(result.URL)
+		if host == "" {
+			continue
+		}
+
+		if _, err := c.collection.UpdateByID(ctx, result.ID, bson.M{"$set": bson.M{"host": host}}); err != nil {
+			return updated, fmt.Errorf("failed to update host for %s: %w", result.URL, err)
+		}
+		updated++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return updated, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return updated, nil
+}
+
+// RecordFailedURL records that fetching url failed with fetchErr
+// Repeated failures of the same URL increment its attempt count and keep the latest error
+func (c *Client) RecordFailedURL(ctx context.Context, url string, fetchErr error) error {
+	if c.failedURLs == nil {
+		return fmt.Errorf("failed URLs collection not initialized")
+	}
+
+	message := ""
+	if fetchErr != nil {
+		message = fetchErr.Error()
+	}
+	update := bson.M{
+		"$set": bson.M{"error": message, "last_tried": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.Update().SetUpsert(true)
+	if _, err := c.failedURLs.UpdateOne(ctx, bson.M{"url": url}, update, opts); err != nil {
+		return fmt.Errorf("failed to record failed URL %s: %w", url, err)
+	}
+	return nil
+}
+
+// GetFailedURLs returns every recorded failed URL, least recently tried first
+func (c *Client) GetFailedURLs(ctx context.Context) ([]domain.FailedURL, error) {
+	if c.failedURLs == nil {
+		return nil, fmt.Errorf("failed URLs collection not initialized")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_tried", Value: 1}}).
+		SetProjection(bson.M{"_id": 0})
+	cursor, err := c.failedURLs.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed URLs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var out []domain.FailedURL
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode failed URLs: %w", err)
+	}
+	return out, nil
+}
+
+// ClearFailedURL removes url from the failed URLs, e.g., after a successful retry
+// Clearing a URL that isn't recorded is not an error
+func (c *Client) ClearFailedURL(ctx context.Context, url string) error {
+	if c.failedURLs == nil {
+		return fmt.Errorf("failed URLs collection not initialized")
+	}
+
+	if _, err := c.failedURLs.DeleteOne(ctx, bson.M{"url": url}); err != nil {
+		return fmt.Errorf("failed to clear failed URL %s: %w", url, err)
+	}
+	return nil
+}
+
+// SaveCrawlCheckpoint records lastPage as the last fully processed page of the paginated crawl
+// of baseURL (usually its page URL pattern), replacing the previous checkpoint
+func (c *Client) SaveCrawlCheckpoint(ctx context.Context, baseURL string, lastPage int) error {
+	if c.crawlState == nil {
+		return fmt.Errorf("crawl state collection not initialized")
+	}
+
+	update := bson.M{"$set": bson.M{"last_page": lastPage, "updated_at": time.Now()}}
+	opts := options.Update().SetUpsert(true)
+	if _, err := c.crawlState.UpdateOne(ctx, bson.M{"base_url": baseURL}, update, opts); err != nil {
+		return fmt.Errorf("failed to save crawl checkpoint for %s: %w", baseURL, err)
+	}
+	return nil
+}
+
+// LoadCrawlCheckpoint returns the last fully processed page saved for baseURL
+// found is false (and err nil) if no checkpoint is saved
+func (c *Client) LoadCrawlCheckpoint(ctx context.Context, baseURL string) (lastPage int, found bool, err error) {
+	if c.crawlState == nil {
+		return 0, false, fmt.Errorf("crawl state collection not initialized")
+	}
+
+	var result struct {
+		LastPage int `bson:"last_page"`
+	}
+	err = c.crawlState.FindOne(ctx, bson.M{"base_url": baseURL}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to load crawl checkpoint for %s: %w", baseURL, err)
+	}
+	return result.LastPage, true, nil
+}
+
+// ClearCrawlCheckpoint removes the checkpoint of baseURL, so its next crawl starts from the first page
+// Clearing a base URL without a checkpoint is not an error
+func (c *Client) ClearCrawlCheckpoint(ctx context.Context, baseURL string) error {
+	if c.crawlState == nil {
+		return fmt.Errorf("crawl state collection not initialized")
+	}
+
+	if _, err := c.crawlState.DeleteOne(ctx, bson.M{"base_url": baseURL}); err != nil {
+		return fmt.Errorf("failed to clear crawl checkpoint for %s: %w", baseURL, err)
+	}
+	return nil
+}
+
+// SeenURLStore records the URLs attempted by recurring crawls in SeenURLsCollection
+// It implements urls.SeenStore; each MarkSeen is written immediately, so Flush does nothing
+type SeenURLStore struct {
+	collection *mongo.Collection
+	now        func() time.Time
+}
+
+// SeenURLs returns the store of URLs attempted by recurring crawls
+func (c *Client) SeenURLs() *SeenURLStore {
+	return &SeenURLStore{collection: c.seenURLs, now: time.Now}
+}
+
+// MarkSeen records that url was attempted now
+func (s *SeenURLStore) MarkSeen(ctx context.Context, url string) error {
+	if s.collection == nil {
+		return fmt.Errorf("seen URLs collection not initialized")
+	}
+
+	update := bson.M{"$set": bson.M{"seen_at": s.now()}}
+	opts := options.Update().SetUpsert(true)
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"url": url}, update, opts); err != nil {
+		return fmt.Errorf("failed to mark %s as seen: %w", url, err)
+	}
+	return nil
+}
+
+// SeenWithin returns true if url was attempted less than ttl ago
+func (s *SeenURLStore) SeenWithin(ctx context.Context, url string, ttl time.Duration) (bool, error) {
+	if s.collection == nil {
+		return false, fmt.Errorf("seen URLs collection not initialized")
+	}
+
+	filter := bson.M{"url": url, "seen_at": bson.M{"$gt": s.now().Add(-ttl)}}
+	count, err := s.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to look up seen URL %s: %w", url, err)
+	}
+	return count > 0, nil
+}
+
+// Flush does nothing; MarkSeen writes each URL immediately
+func (s *SeenURLStore) Flush(ctx context.Context) error {
+	return nil
+}
@@ -1,11 +1,103 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"blog-search/pkg/domain"
+)
 
 // DBProvider is an interface for database clients that provide access to a sql.DB handle.
 // This allows both PostgresClient and SupabaseClient to be used interchangeably.
 type DBProvider interface {
 	DB() *sql.DB
+
+	// Articles returns the article table queries for the database behind DB.
+	Articles() ArticleRepository
+}
+
+// ArticleRepository reads and writes the replicated article table.
+// SQLArticleRepository implements it, so every DBProvider shares the same SQL.
+type ArticleRepository interface {
+	// EnsureSchema creates or migrates the article table and returns how many migrations were applied.
+	EnsureSchema(ctx context.Context) (int, error)
+	// ExistingURLs returns which of urls are already stored.
+	ExistingURLs(ctx context.Context, urls []string) (map[string]bool, error)
+	// InsertBatch writes articles and returns how many rows were written.
+	// With upsert, existing rows are overwritten by copies crawled more recently.
+	InsertBatch(ctx context.Context, articles []domain.Article, upsert bool) (int, error)
+	// MaxCrawledAt returns the newest crawled_at, or the zero time if there are no articles.
+	MaxCrawledAt(ctx context.Context) (time.Time, error)
+	// Search returns up to limit articles matching query, best match first.
+	Search(ctx context.Context, query string, limit int) ([]domain.Article, error)
+}
+
+// ArticleStore is the subset of Client used by crawlers to skip known URLs and save articles.
+// Consumers depend on it instead of *Client so they can be unit tested without MongoDB.
+type ArticleStore interface {
+	SaveArticle(ctx context.Context, article *domain.Article) error
+	GetAllURLs(ctx context.Context) (map[string]bool, error)
+	GetExistingArticleURLs(ctx context.Context, candidates []string) (map[string]bool, error)
+}
+
+// RetryingSaver is implemented by stores that can retry a save after a dropped connection.
+// Client implements it.
+type RetryingSaver interface {
+	SaveArticleWithRetry(ctx context.Context, article *domain.Article) error
+}
+
+// SaveWithRetry saves article to store, using SaveArticleWithRetry when the store supports it.
+func SaveWithRetry(ctx context.Context, store ArticleStore, article *domain.Article) error {
+	if retrying, ok := store.(RetryingSaver); ok {
+		return retrying.SaveArticleWithRetry(ctx, article)
+	}
+	return store.SaveArticle(ctx, article)
+}
+
+
+
+// BulkSaver is implemented by stores that can save many articles in one round trip.
+// Client implements it.
+type BulkSaver interface {
+	SaveArticles(ctx context.Context, articles []*domain.Article) error
+}
+
+// BulkSaveError is returned by SaveArticles when some articles weren't saved; the rest were.
+type BulkSaveError struct {
+	FailedURLs []string // URLs of the articles that weren't saved
+	Total      int      // Number of articles in the call
+	Err        error    // The underlying write error
+}
+
+func (e *BulkSaveError) Error() string {
+	return fmt.Sprintf("failed to save %d of %d articles: %v", len(e.FailedURLs), e.Total, e.Err)
 }
 
+func (e *BulkSaveError) Unwrap() error {
+	return e.Err
+}
+
+// FailedURLRecorder is implemented by stores that keep URLs whose content couldn't be fetched,
+// so they can be retried later. Client implements it.
+type FailedURLRecorder interface {
+	RecordFailedURL(ctx context.Context, url string, fetchErr error) error
+}
 
+// RecordFailure records url as failed with fetchErr if store keeps failed URLs.
+// Does nothing (and returns nil) for stores that don't.
+func RecordFailure(ctx context.Context, store ArticleStore, url string, fetchErr error) error {
+	if recorder, ok := store.(FailedURLRecorder); ok {
+		return recorder.RecordFailedURL(ctx, url, fetchErr)
+	}
+	return nil
+}
+
+// CrawlCheckpointer is implemented by stores that remember how far a paginated crawl got,
+// so an interrupted crawl can resume instead of starting over. Client implements it.
+type CrawlCheckpointer interface {
+	SaveCrawlCheckpoint(ctx context.Context, baseURL string, lastPage int) error
+	LoadCrawlCheckpoint(ctx context.Context, baseURL string) (lastPage int, found bool, err error)
+	ClearCrawlCheckpoint(ctx context.Context, baseURL string) error
+}
@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Migration is one schema change.
+// IDs order the migrations and identify them once applied, so they must never be reused or renumbered.
+type Migration struct {
+	ID   int
+	Name string // Human-readable description, recorded with the ID
+	SQL  string
+}
+
+// createTableDDL creates the table that records applied migrations.
+const createTableDDL = `
+CREATE TABLE IF NOT EXISTS migrations (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// Apply applies, in ID order, each migration that isn't recorded in the migrations table yet
+// and returns how many it applied. It is safe to call on every startup: a database that is
+// up to date is left untouched.
+// Each migration runs in its own transaction together with its record, so a failing step
+// leaves neither a partial change nor a record behind; earlier steps stay applied.
+func Apply(ctx context.Context, db *sql.DB, migrations []Migration) (int, error) {
+	ordered, err := sortMigrations(migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := db.ExecContext(ctx, createTableDDL); err != nil {
+		return 0, fmt.Errorf("create migrations table: %w", err)
+	}
+
+	done, err := appliedIDs(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range ordered {
+		if done[m.ID] {
+			continue
+		}
+		if err := applyOne(ctx, db, m); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// sortMigrations returns the migrations sorted by ID, rejecting duplicate IDs.
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	ordered := append([]Migration(nil), migrations...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ID < ordered[j].ID })
+
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i].ID == ordered[i-1].ID {
+			return nil, fmt.Errorf("duplicate migration id %d (%q and %q)", ordered[i].ID, ordered[i-1].Name, ordered[i].Name)
+		}
+	}
+	return ordered, nil
+}
+
+// appliedIDs returns the IDs recorded in the migrations table.
+func appliedIDs(ctx context.Context, db *sql.DB) (map[int]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id FROM migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		done[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+	return done, nil
+}
+
+// applyOne runs a migration and records it in one transaction.
+func applyOne(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.ID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("apply migration %d (%s): %w", m.ID, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO migrations (id, name) VALUES ($1, $2)`, m.ID, m.Name); err != nil {
+		return fmt.Errorf("record migration %d: %w", m.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", m.ID, err)
+	}
+	return nil
+}
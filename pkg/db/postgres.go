@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"blog-search/pkg/domain"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
@@ -91,6 +93,19 @@ func (c *PostgresClient) DB() *sql.DB {
 	return c.db
 }
 
+// Articles returns the article table queries for this database.
+func (c *PostgresClient) Articles() ArticleRepository {
+	return NewSQLArticleRepository(c.db)
+}
+
+// SearchArticles runs a full-text search over the replicated article table.
+// Matches are ranked by ts_rank and the top limit rows are returned.
+//
+// Requires the search_vector column created by the replicator's schema setup.
+func (c *PostgresClient) SearchArticles(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+	return c.Articles().Search(ctx, query, limit)
+}
+
 // addConnectionParam adds a query parameter to the connection string if not already present.
 func (c *PostgresClient) addConnectionParam(connStr, key, value string) string {
 	if strings.Contains(connStr, key+"=") {
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteConfig holds configuration required to open a SQLite database file.
+type SQLiteConfig struct {
+	// Path to the database file, e.g. "./articles.db". Created if missing.
+	Path string
+}
+
+// SQLiteClient is a thin wrapper around a sql.DB handle backed by a single SQLite file.
+// It implements DBProvider so it can be used as a replication target.
+type SQLiteClient struct {
+	db  *sql.DB
+	cfg SQLiteConfig
+}
+
+// NewSQLiteClient constructs a SQLite client.
+func NewSQLiteClient(cfg SQLiteConfig) *SQLiteClient {
+	return &SQLiteClient{cfg: cfg}
+}
+
+// Connect opens the database file and verifies it is usable.
+func (c *SQLiteClient) Connect(ctx context.Context) error {
+	if c.cfg.Path == "" {
+		return fmt.Errorf("sqlite path is required")
+	}
+
+	// Wait on locks instead of failing immediately, and store times in a sortable format
+	dsn := "file:" + c.cfg.Path + "?_pragma=busy_timeout(5000)&_time_format=sqlite"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+
+	// SQLite allows a single writer; serialize access instead of hitting SQLITE_BUSY
+	// when the replicator writes batches in parallel.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("ping sqlite: %w", err)
+	}
+
+	c.db = db
+	return nil
+}
+
+// Close closes the underlying sql.DB handle.
+func (c *SQLiteClient) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// DB exposes the underlying handle for query/exec operations.
+func (c *SQLiteClient) DB() *sql.DB {
+	return c.db
+}
+
+// Articles returns the article table queries for this file, using the SQLite schema.
+func (c *SQLiteClient) Articles() ArticleRepository {
+	return &SQLArticleRepository{db: c.db, sqlite: true}
+}
@@ -140,6 +140,15 @@ func (c *SupabaseClient) DB() *sql.DB {
 	return c.db
 }
 
+// Articles returns the article table queries over the direct database connection.
+// In REST API mode they go through the Supabase REST API instead, which can't create the table or search.
+func (c *SupabaseClient) Articles() ArticleRepository {
+	if c.db == nil && c.supabaseSDK != nil {
+		return &supabaseRESTArticles{client: c}
+	}
+	return NewSQLArticleRepository(c.db)
+}
+
 // HasDirectDB returns true if direct database connection is available.
 func (c *SupabaseClient) HasDirectDB() bool {
 	return c.db != nil
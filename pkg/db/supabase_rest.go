@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"blog-search/pkg/domain"
+
+	"github.com/supabase-community/postgrest-go"
+)
+
+// articleRow is an article table row as sent to and read from the Supabase REST API.
+type articleRow struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	CrawledAt time.Time `json:"crawled_at"`
+}
+
+// articleRows converts articles to REST rows, skipping articles without a URL.
+func articleRows(articles []domain.Article) []articleRow {
+	rows := make([]articleRow, 0, len(articles))
+	for _, a := range articles {
+		if a.URL == "" {
+			continue
+		}
+		rows = append(rows, articleRow{URL: a.URL, Title: a.Title, Text: a.Text, CrawledAt: a.CrawledAt})
+	}
+	return rows
+}
+
+// InsertArticlesREST inserts articles into the article table through the Supabase REST API and
+// returns how many rows were inserted. It works without the database password, but is slower than
+// a direct connection. The rows go in one request, so a URL that already exists fails the whole call.
+// Articles without a URL are skipped.
+func (c *SupabaseClient) InsertArticlesREST(ctx context.Context, articles []domain.Article) (int, error) {
+	return c.writeArticlesREST(ctx, articles, false)
+}
+
+// writeArticlesREST inserts articles over the REST API; with upsert, rows with an existing URL are overwritten.
+func (c *SupabaseClient) writeArticlesREST(ctx context.Context, articles []domain.Article, upsert bool) (int, error) {
+	if c.supabaseSDK == nil {
+		return 0, fmt.Errorf("supabase SDK not initialized: SUPABASE_URL and SUPABASE_KEY are required")
+	}
+
+	rows := articleRows(articles)
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	// The SDK doesn't take a context, so cancellation is only checked before the request
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	query := c.supabaseSDK.From("article")
+	var err error
+	if upsert {
+		_, _, err = query.Upsert(rows, "url", "minimal", "").Execute()
+	} else {
+		_, _, err = query.Insert(rows, false, "", "minimal", "").Execute()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("insert articles via supabase REST: %w", err)
+	}
+	return len(rows), nil
+}
+
+// supabaseRESTArticles implements ArticleRepository over the Supabase REST API, for clients
+// connected with only a URL and key.
+type supabaseRESTArticles struct {
+	client *SupabaseClient
+}
+
+// EnsureSchema checks that the article table is reachable; tables can't be created over REST,
+// so it has to be created beforehand (e.g., in the Supabase SQL editor).
+func (r *supabaseRESTArticles) EnsureSchema(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if _, _, err := r.client.supabaseSDK.From("article").Select("url", "", false).Limit(1, "").Execute(); err != nil {
+		return 0, fmt.Errorf("article table not reachable via supabase REST (it must be created with a direct connection or in the SQL editor): %w", err)
+	}
+	return 0, nil
+}
+
+// ExistingURLs returns which of urls are already in the article table.
+func (r *supabaseRESTArticles) ExistingURLs(ctx context.Context, urls []string) (map[string]bool, error) {
+	candidates := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if url != "" {
+			candidates = append(candidates, url)
+		}
+	}
+	if len(candidates) == 0 {
+		return map[string]bool{}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var rows []articleRow
+	if _, err := r.client.supabaseSDK.From("article").Select("url", "", false).In("url", candidates).ExecuteTo(&rows); err != nil {
+		return nil, fmt.Errorf("query existing urls via supabase REST: %w", err)
+	}
+
+	set := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.URL != "" {
+			set[row.URL] = true
+		}
+	}
+	return set, nil
+}
+
+// InsertBatch writes articles with InsertArticlesREST.
+// Upserts overwrite existing rows regardless of crawled_at, since REST can't express the condition.
+func (r *supabaseRESTArticles) InsertBatch(ctx context.Context, articles []domain.Article, upsert bool) (int, error) {
+	return r.client.writeArticlesREST(ctx, articles, upsert)
+}
+
+// MaxCrawledAt returns the newest crawled_at in the article table, or the zero time if the table is empty.
+func (r *supabaseRESTArticles) MaxCrawledAt(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	var rows []articleRow
+	_, err := r.client.supabaseSDK.From("article").
+		Select("crawled_at", "", false).
+		Order("crawled_at", &postgrest.OrderOpts{Ascending: false}).
+		Limit(1, "").
+		ExecuteTo(&rows)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query max crawled_at via supabase REST: %w", err)
+	}
+	if len(rows) == 0 {
+		return time.Time{}, nil
+	}
+	return rows[0].CrawledAt, nil
+}
+
+// Search is not available over REST; the search server needs a direct connection.
+func (r *supabaseRESTArticles) Search(ctx context.Context, query string, limit int) ([]domain.Article, error) {
+	return nil, fmt.Errorf("full-text search is not supported via supabase REST")
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+// fakeSupabaseREST serves the subset of the PostgREST API used for the article table
+type fakeSupabaseREST struct {
+	mu      sync.Mutex
+	rows    map[string]articleRow
+	prefers []string
+}
+
+func (f *fakeSupabaseREST) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/rest/v1/article" || r.Header.Get("apikey") != "test-key" {
+		http.Error(w, `{"code":"404","message":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		f.prefers = append(f.prefers, r.Header.Get("Prefer"))
+		var rows []articleRow
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &rows); err != nil {
+			http.Error(w, `{"code":"PGRST102","message":"invalid body"}`, http.StatusBadRequest)
+			return
+		}
+		upsert := strings.Contains(r.Header.Get("Prefer"), "resolution=merge-duplicates")
+		for _, row := range rows {
+			if _, exists := f.rows[row.URL]; exists && !upsert {
+				http.Error(w, `{"code":"23505","message":"duplicate key value violates unique constraint"}`, http.StatusConflict)
+				return
+			}
+		}
+		for _, row := range rows {
+			f.rows[row.URL] = row
+		}
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		var out []articleRow
+		if in := r.URL.Query().Get("url"); in != "" {
+			for _, url := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(in, "in.("), ")"), ",") {
+				if row, ok := f.rows[strings.Trim(url, `"`)]; ok {
+					out = append(out, row)
+				}
+			}
+		} else {
+			for _, row := range f.rows {
+				if len(out) == 0 || row.CrawledAt.After(out[0].CrawledAt) {
+					out = []articleRow{row}
+				}
+			}
+		}
+		if out == nil {
+			out = []articleRow{}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// setupRESTSupabaseClient connects a SupabaseClient in REST API mode to a fake REST endpoint
+func setupRESTSupabaseClient(t *testing.T) (*SupabaseClient, *fakeSupabaseREST) {
+	t.Helper()
+
+	fake := &fakeSupabaseREST{rows: make(map[string]articleRow)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewSupabaseClient(SupabaseConfig{SupabaseURL: server.URL, SupabaseKey: "test-key"})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if client.HasDirectDB() {
+		t.Fatal("Expected REST API mode without a password")
+	}
+	return client, fake
+}
+
+func TestSupabaseClient_InsertArticlesREST(t *testing.T) {
+	client, fake := setupRESTSupabaseClient(t)
+	ctx := context.Background()
+
+	crawledAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	articles := []domain.Article{
+		{URL: "https://example.com/a", Title: "A", Text: "first", CrawledAt: crawledAt},
+		{URL: "https://example.com/b,c", Title: "B", Text: "second", CrawledAt: crawledAt},
+		{URL: "", Title: "no url"},
+	}
+
+	inserted, err := client.InsertArticlesREST(ctx, articles)
+	if err != nil {
+		t.Fatalf("InsertArticlesREST failed: %v", err)
+	}
+	if inserted != 2 {
+		t.Errorf("Expected 2 inserted articles, got %d", inserted)
+	}
+	want := articleRow{URL: "https://example.com/a", Title: "A", Text: "first", CrawledAt: crawledAt}
+	if got := fake.rows["https://example.com/a"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected stored row %+v, got %+v", want, got)
+	}
+	if len(fake.prefers) != 1 || strings.Contains(fake.prefers[0], "merge-duplicates") {
+		t.Errorf("Expected one plain insert request, got Prefer headers %q", fake.prefers)
+	}
+
+	// Duplicates are rejected by the server and reported
+	if _, err := client.InsertArticlesREST(ctx, articles[:1]); err == nil {
+		t.Error("Expected inserting an existing URL to fail")
+	}
+}
+
+func TestSupabaseClient_InsertArticlesREST_RequiresSDK(t *testing.T) {
+	client := NewSupabaseClient(SupabaseConfig{})
+	if _, err := client.InsertArticlesREST(context.Background(), []domain.Article{{URL: "https://example.com/a"}}); err == nil {
+		t.Fatal("Expected an error without a Supabase URL and key")
+	}
+}
+
+func TestSupabaseClient_Articles_UsesRESTWithoutDirectDB(t *testing.T) {
+	client, _ := setupRESTSupabaseClient(t)
+	ctx := context.Background()
+	repo := client.Articles()
+
+	if _, err := repo.EnsureSchema(ctx); err != nil {
+		t.Fatalf("EnsureSchema failed: %v", err)
+	}
+	if highWaterMark, err := repo.MaxCrawledAt(ctx); err != nil || !highWaterMark.IsZero() {
+		t.Fatalf("Expected zero time for an empty table, got %s, %v", highWaterMark, err)
+	}
+
+	older := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	articles := []domain.Article{
+		{URL: "https://example.com/a", Title: "A", CrawledAt: older},
+		{URL: "https://example.com/b", Title: "B", CrawledAt: newer},
+	}
+	if written, err := repo.InsertBatch(ctx, articles, false); err != nil || written != 2 {
+		t.Fatalf("Expected 2 rows written, got %d, %v", written, err)
+	}
+	// Upserts overwrite existing rows instead of failing
+	if written, err := repo.InsertBatch(ctx, articles[:1], true); err != nil || written != 1 {
+		t.Errorf("Expected upsert to write 1 row, got %d, %v", written, err)
+	}
+
+	existing, err := repo.ExistingURLs(ctx, []string{"https://example.com/a", "https://example.com/missing", ""})
+	if err != nil {
+		t.Fatalf("ExistingURLs failed: %v", err)
+	}
+	if want := map[string]bool{"https://example.com/a": true}; !reflect.DeepEqual(existing, want) {
+		t.Errorf("Expected %v, got %v", want, existing)
+	}
+
+	highWaterMark, err := repo.MaxCrawledAt(ctx)
+	if err != nil {
+		t.Fatalf("MaxCrawledAt failed: %v", err)
+	}
+	if !highWaterMark.Equal(newer) {
+		t.Errorf("Expected high-water mark %s, got %s", newer, highWaterMark)
+	}
+
+	if _, err := repo.Search(ctx, "a", 10); err == nil {
+		t.Error("Expected Search to fail over REST")
+	}
+}
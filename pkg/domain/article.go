@@ -1,12 +1,75 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+)
 
 // Article represents a blog article stored in the database
 type Article struct {
-	URL       string    `bson:"url"`
-	Title     string    `bson:"title"`
-	Text      string    `bson:"text"`
-	CrawledAt time.Time `bson:"crawled_at"`
+	URL       string    `bson:"url" json:"url"`
+	Host      string    `bson:"host" json:"host"` // Normalized host derived from URL (e.g., "example.com")
+	Title     string    `bson:"title" json:"title"`
+	Text      string    `bson:"text" json:"text"`
+	CrawledAt time.Time `bson:"crawled_at" json:"crawled_at"`
+	Summary   string    `bson:"summary,omitempty" json:"summary,omitempty"` // Feed description, when the URL came from a feed
+	Tags      []string  `bson:"tags,omitempty" json:"tags,omitempty"`       // Tags or topics the page declares (see content.ExtractTags)
+
+	ContentHash string `bson:"content_hash,omitempty" json:"content_hash,omitempty"` // SHA-256 of whitespace-normalized text
+
+	// FetchedURL is the URL the page was fetched from, when it is stored under another URL
+	// (its canonical URL), so a re-crawl of the fetched URL still finds the stored article
+	FetchedURL string `bson:"fetched_url,omitempty" json:"fetched_url,omitempty"`
+
+	// RawHTML is the fetched page, kept only when the processor is asked to (for re-extraction
+	// without re-crawling). Not included in JSON responses.
+	RawHTML string `bson:"raw_html,omitempty" json:"-"`
+
+	// ETag and LastModified are the validators of the fetched page's response, sent back as
+	// If-None-Match/If-Modified-Since when the page is re-crawled
+	ETag         string `bson:"etag,omitempty" json:"-"`
+	LastModified string `bson:"last_modified,omitempty" json:"-"`
 	// Add more fields as needed (LastMod, Priority, etc.)
 }
+
+// FailedURL is a URL whose content couldn't be fetched or extracted, kept so it can be retried
+type FailedURL struct {
+	URL       string    `bson:"url" json:"url"`
+	Error     string    `bson:"error" json:"error"`           // Error of the most recent attempt
+	Attempts  int       `bson:"attempts" json:"attempts"`     // Failed attempts so far
+	LastTried time.Time `bson:"last_tried" json:"last_tried"` // When the most recent attempt failed
+}
+
+// HostFromURL returns the normalized host of a URL: lowercased, without port and "www." prefix
+// Returns an empty string if the URL can't be parsed or has no host
+func HostFromURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+
+	return NormalizeHost(parsed.Hostname())
+}
+
+// NormalizeHost lowercases a host name and strips the "www." prefix
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	return strings.TrimPrefix(host, "www.")
+}
+
+// ContentHash returns the hex SHA-256 of the text with whitespace normalized
+// (runs of whitespace collapsed to a single space, leading/trailing trimmed),
+// so the same body served with different formatting hashes identically
+// Returns an empty string for empty text
+func ContentHash(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	if normalized == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "testing"
+
+func TestHostFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://engineering.fb.com/2024/01/post", "engineering.fb.com"},
+		{"https://www.dataengineeringpodcast.com/episodepage/1", "dataengineeringpodcast.com"},
+		{"https://Example.COM:8443/blog?x=1", "example.com"},
+		{"http://localhost:8080/a", "localhost"},
+		{"/relative/path", ""},
+		{"u1", ""},
+		{"://bad-url", ""},
+	}
+
+	for _, tt := range tests {
+		if got := HostFromURL(tt.url); got != tt.expected {
+			t.Errorf("HostFromURL(%q) = %q, expected %q", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestContentHash_StableAcrossWhitespace(t *testing.T) {
+	base := ContentHash("Hello world. This is an article.")
+
+	variants := []string{
+		"Hello world. This is an article.",
+		"  Hello   world.\nThis is an article.\n",
+		"Hello\tworld.\r\n\r\nThis  is an\u00a0article.",
+	}
+	for _, v := range variants {
+		if got := ContentHash(v); got != base {
+			t.Errorf("ContentHash(%q) = %s, expected %s", v, got, base)
+		}
+	}
+
+	if ContentHash("Hello world. This is another article.") == base {
+		t.Error("Expected different text to produce a different hash")
+	}
+
+	if ContentHash("   \n\t ") != "" {
+		t.Error("Expected empty hash for whitespace-only text")
+	}
+}
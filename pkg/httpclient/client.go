@@ -1,7 +1,15 @@
 package httpclient
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // ClientType represents the type of HTTP client configuration
@@ -17,32 +25,144 @@ const (
 	CloudflareClient ClientType = "cloudflare"
 )
 
+// Accept header values for the kind of document a fetch expects, for CDNs that pick the
+// representation (e.g., JSON or HTML) from the Accept header
+const (
+	AcceptHTML = "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8"
+	AcceptXML  = "application/xml,text/xml;q=0.9,*/*;q=0.8"
+)
+
+// DefaultMaxBodyBytes caps response bodies read via ReadBody when no limit is configured
+const DefaultMaxBodyBytes int64 = 20 << 20 // 20 MiB
+
+// ErrBodyTooLarge is returned by ReadBody when a response body exceeds the configured limit
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ClientOptions holds optional settings for an HTTPClient
+type ClientOptions struct {
+	// MaxBodyBytes limits how much of a response body ReadBody will read
+	// Zero means DefaultMaxBodyBytes; a negative value disables the limit
+	MaxBodyBytes int64
+
+	// ExtraHeaders are set on every request to ExtraHeadersHost after the client type's headers,
+	// so they only replace those (e.g., the User-Agent) for the keys they contain
+	ExtraHeaders map[string]string
+
+	// ExtraHeadersHost limits ExtraHeaders to requests for this host (host[:port], as in a URL),
+	// e.g., the crawl's base host, so a login cookie or token never reaches other sites, even
+	// through a redirect. Empty sends them to every host
+	ExtraHeadersHost string
+
+	// UserAgent replaces the client type's User-Agent, e.g., to name the crawler and give a
+	// contact URL. Empty keeps the preset
+	// A FallbackHTTPClient only sends it with the primary type; the retry uses the other preset
+	UserAgent string
+
+	// UseCookieJar keeps the cookies set by responses and sends them with later requests to the
+	// same host, for sites that set a session cookie on the first request (e.g., Cloudflare
+	// clearance or a consent banner). Each client has its own jar
+	UseCookieJar bool
+
+	// PerHostDelay is the minimum gap between the starts of consecutive requests to the same
+	// host, for sites that ask for a fixed crawl delay rather than a request rate
+	// Requests wait for their turn (or for the request context to be done); zero disables it
+	PerHostDelay time.Duration
+}
+
+// copyHeaders returns a copy of headers, so later changes to the caller's map don't leak in
+func copyHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(headers))
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
+
 // HTTPClient wraps an http.Client with configuration
 type HTTPClient struct {
 	client    *http.Client
 	clientType ClientType
+	maxBodyBytes int64
+	extraHeaders map[string]string
+	extraHeadersHost string
+	userAgent string
+	throttle *hostThrottle
 }
 
 // NewClient creates a new HTTP client with the specified type
 func NewClient(clientType ClientType) *HTTPClient {
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Follow up to 10 redirects
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		},
+	return NewClientWithOptions(clientType, ClientOptions{})
+}
+
+// NewClientWithOptions creates a new HTTP client with the specified type and options
+func NewClientWithOptions(clientType ClientType, opts ClientOptions) *HTTPClient {
+	maxBodyBytes := opts.MaxBodyBytes
+	if maxBodyBytes == 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	c := &HTTPClient{
+		client:           &http.Client{},
+		clientType:       clientType,
+		maxBodyBytes:     maxBodyBytes,
+		extraHeaders:     copyHeaders(opts.ExtraHeaders),
+		extraHeadersHost: opts.ExtraHeadersHost,
+		userAgent:        opts.UserAgent,
+		throttle:         newHostThrottle(opts.PerHostDelay),
+	}
+	c.client.CheckRedirect = c.checkRedirect
+	if opts.UseCookieJar {
+		// cookiejar.New only fails for a broken PublicSuffixList, and none is given
+		jar, _ := cookiejar.New(nil)
+		c.client.Jar = jar
+	}
+	return c
+}
+
+// checkRedirect follows up to 10 redirects; the http.Client copies the first request's headers
+// to each redirect, so ExtraHeaders are taken off again for hosts they aren't meant for
+func (c *HTTPClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	// Follow up to 10 redirects
+	if len(via) >= 10 {
+		return http.ErrUseLastResponse
+	}
+	if !c.sendsExtraHeadersTo(req.URL) {
+		for key := range c.extraHeaders {
+			req.Header.Del(key)
+		}
+		// Restores the client type's headers that ExtraHeaders replaced
+		c.setHeaders(req)
+	}
+	return nil
+}
+
+// ReadBody reads the response body, failing with ErrBodyTooLarge once it exceeds the client's limit
+// It stops reading at the limit, so oversized responses are never fully buffered
+func (c *HTTPClient) ReadBody(resp *http.Response) ([]byte, error) {
+	if c.maxBodyBytes < 0 {
+		return io.ReadAll(resp.Body)
 	}
 
-	return &HTTPClient{
-		client:     client,
-		clientType: clientType,
+	// Read one byte past the limit to tell "exactly at the limit" from "over it"
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > c.maxBodyBytes {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, c.maxBodyBytes)
 	}
+	return body, nil
 }
 
 // Do executes an HTTP request with the appropriate headers for the client type
+// With ClientOptions.PerHostDelay set, it first waits until the request's host is due
 func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.throttle.wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
 	c.setHeaders(req)
 	return c.client.Do(req)
 }
@@ -56,6 +176,26 @@ func (c *HTTPClient) Get(url string) (*http.Response, error) {
 	return c.Do(req)
 }
 
+// GetWithContext is like Get, but the request is aborted when ctx is done
+func (c *HTTPClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// GetWithAccept is like GetWithContext, but asks for the given representation (e.g., AcceptHTML)
+// It replaces the client type's Accept header; ExtraHeaders still win over it
+func (c *HTTPClient) GetWithAccept(ctx context.Context, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	return c.Do(req)
+}
+
 // Head is a convenience method for HEAD requests
 func (c *HTTPClient) Head(url string) (*http.Response, error) {
 	req, err := http.NewRequest("HEAD", url, nil)
@@ -65,13 +205,49 @@ func (c *HTTPClient) Head(url string) (*http.Response, error) {
 	return c.Do(req)
 }
 
+// HeadWithContext is like Head, but the request is aborted when ctx is done
+func (c *HTTPClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// WithClientType returns a copy of the client that sends the headers of clientType, keeping its
+// options; the copy shares the connection pool, cookie jar and per-host throttle
+func (c *HTTPClient) WithClientType(clientType ClientType) *HTTPClient {
+	copied := *c
+	copied.clientType = clientType
+
+	// The copy's redirects must restore its own client type's headers
+	client := *c.client
+	client.CheckRedirect = copied.checkRedirect
+	copied.client = &client
+	return &copied
+}
+
+// WithOptions returns a new client of the same type with opts in place of the client's options
+// Unlike WithClientType, the new client has its own cookie jar and per-host throttle
+func (c *HTTPClient) WithOptions(opts ClientOptions) *HTTPClient {
+	return NewClientWithOptions(c.clientType, opts)
+}
+
+// MaxBodyBytes returns the body size limit applied by ReadBody; negative means unlimited
+func (c *HTTPClient) MaxBodyBytes() int64 {
+	return c.maxBodyBytes
+}
+
 // setHeaders sets the appropriate headers based on client type
+// An Accept header already on the request (the caller's expected content type) is kept
 func (c *HTTPClient) setHeaders(req *http.Request) {
 	switch c.clientType {
 	case BrowserClient:
 		// Browser-like headers to avoid 406 (Not Acceptable) errors
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		if req.Header.Get("Accept") == "" {
+			req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		}
 		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 		req.Header.Set("Connection", "keep-alive")
 		req.Header.Set("Upgrade-Insecure-Requests", "1")
@@ -84,5 +260,22 @@ func (c *HTTPClient) setHeaders(req *http.Request) {
 	default:
 		// Default: use Go's default User-Agent
 	}
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	// User-provided headers (cookies, tokens, a Referer) win over the defaults above
+	if !c.sendsExtraHeadersTo(req.URL) {
+		return
+	}
+	for key, value := range c.extraHeaders {
+		req.Header.Set(key, value)
+	}
+}
+
+// sendsExtraHeadersTo reports whether ExtraHeaders may be sent with a request for u
+func (c *HTTPClient) sendsExtraHeadersTo(u *url.URL) bool {
+	return c.extraHeadersHost == "" || strings.EqualFold(u.Host, c.extraHeadersHost)
 }
 
@@ -0,0 +1,307 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bodyServer serves a body of n bytes
+func bodyServer(t *testing.T, n int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", n)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPClient_ReadBody_UnderLimit(t *testing.T) {
+	server := bodyServer(t, 100)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{MaxBodyBytes: 100})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := client.ReadBody(resp)
+	if err != nil {
+		t.Fatalf("Expected body at the limit to be read, got %v", err)
+	}
+	if len(body) != 100 {
+		t.Errorf("Expected 100 bytes, got %d", len(body))
+	}
+}
+
+func TestHTTPClient_ReadBody_OverLimit(t *testing.T) {
+	server := bodyServer(t, 101)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{MaxBodyBytes: 100})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	_, err = client.ReadBody(resp)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestHTTPClient_ReadBody_NegativeLimitDisablesGuard(t *testing.T) {
+	server := bodyServer(t, 1000)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{MaxBodyBytes: -1})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := client.ReadBody(resp)
+	if err != nil {
+		t.Fatalf("Expected unlimited read, got %v", err)
+	}
+	if len(body) != 1000 {
+		t.Errorf("Expected 1000 bytes, got %d", len(body))
+	}
+}
+
+// headerEchoServer records the headers of the last request it received
+func headerEchoServer(t *testing.T) (*httptest.Server, *http.Header) {
+	t.Helper()
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestHTTPClient_ExtraHeadersReachServer(t *testing.T) {
+	server, received := headerEchoServer(t)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{ExtraHeaders: map[string]string{
+		"Cookie":  "session=abc123",
+		"referer": "https://example.com/",
+	}})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := received.Get("Cookie"); got != "session=abc123" {
+		t.Errorf("Expected the Cookie header, got %q", got)
+	}
+	if got := received.Get("Referer"); got != "https://example.com/" {
+		t.Errorf("Expected the Referer header, got %q", got)
+	}
+	if got := received.Get("User-Agent"); got != "curl/8.7.1" {
+		t.Errorf("Expected the client type's User-Agent to be kept, got %q", got)
+	}
+}
+
+func TestHTTPClient_ExtraHeadersCanOverrideUserAgent(t *testing.T) {
+	server, received := headerEchoServer(t)
+	client := NewClientWithOptions(BrowserClient, ClientOptions{ExtraHeaders: map[string]string{"User-Agent": "blog-search/1.0"}})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := received.Get("User-Agent"); got != "blog-search/1.0" {
+		t.Errorf("Expected the explicit User-Agent, got %q", got)
+	}
+	if got := received.Get("Accept-Language"); got != "en-US,en;q=0.9" {
+		t.Errorf("Expected the other browser headers to be kept, got %q", got)
+	}
+}
+
+func TestHTTPClient_ExtraHeadersOnlyReachTheirHost(t *testing.T) {
+	site, siteReceived := headerEchoServer(t)
+	other, otherReceived := headerEchoServer(t)
+	headers := map[string]string{"X-Api-Token": "secret", "User-Agent": "blog-search/1.0"}
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{
+		ExtraHeaders:     headers,
+		ExtraHeadersHost: strings.TrimPrefix(site.URL, "http://"),
+	})
+	headers["X-Api-Token"] = "changed after the call"
+
+	for _, target := range []string{site.URL, other.URL} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if got := siteReceived.Get("X-Api-Token"); got != "secret" {
+		t.Errorf("Expected the token on the crawled site, got %q", got)
+	}
+	if got := otherReceived.Get("X-Api-Token"); got != "" {
+		t.Errorf("Expected no token on another host, got %q", got)
+	}
+	if got := otherReceived.Get("User-Agent"); got != "curl/8.7.1" {
+		t.Errorf("Expected the client type's User-Agent on another host, got %q", got)
+	}
+}
+
+func TestHTTPClient_ExtraHeadersDroppedOnRedirectToOtherHost(t *testing.T) {
+	other, otherReceived := headerEchoServer(t)
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/landing", http.StatusFound)
+	}))
+	defer site.Close()
+
+	client := NewClientWithOptions(BrowserClient, ClientOptions{
+		ExtraHeaders:     map[string]string{"X-Api-Token": "secret", "User-Agent": "blog-search/1.0"},
+		ExtraHeadersHost: strings.TrimPrefix(site.URL, "http://"),
+	})
+	resp, err := client.Get(site.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := otherReceived.Get("X-Api-Token"); got != "" {
+		t.Errorf("Expected the redirect to another host to drop the token, got %q", got)
+	}
+	if got := otherReceived.Get("User-Agent"); !strings.HasPrefix(got, "Mozilla/5.0") {
+		t.Errorf("Expected the redirect to restore the browser User-Agent, got %q", got)
+	}
+}
+
+func TestHTTPClient_UserAgent(t *testing.T) {
+	const crawlerUA = "blog-search/1.0 (+https://example.com/crawler)"
+	tests := []struct {
+		name       string
+		clientType ClientType
+		userAgent  string
+		want       string
+	}{
+		{"cloudflare preset", CloudflareClient, "", "curl/8.7.1"},
+		{"browser preset", BrowserClient, "", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"},
+		{"cloudflare override", CloudflareClient, crawlerUA, crawlerUA},
+		{"browser override", BrowserClient, crawlerUA, crawlerUA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := headerEchoServer(t)
+			resp, err := NewClientWithOptions(tt.clientType, ClientOptions{UserAgent: tt.userAgent}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := received.Get("User-Agent"); got != tt.want {
+				t.Errorf("Expected User-Agent %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_CookieJarKeepsSessionCookies(t *testing.T) {
+	requests := 0
+	var echoed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "cf_clearance", Value: "abc123", Path: "/"})
+			return
+		}
+		echoed = append(echoed, r.Header.Get("Cookie"))
+	}))
+	defer server.Close()
+
+	for _, useJar := range []bool{true, false} {
+		requests = 0
+		client := NewClientWithOptions(CloudflareClient, ClientOptions{UseCookieJar: useJar})
+		for i := 0; i < 2; i++ {
+			resp, err := client.Get(server.URL + "/post")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			resp.Body.Close()
+		}
+	}
+
+	if len(echoed) != 2 || echoed[0] != "cf_clearance=abc123" {
+		t.Errorf("Expected the cookie from request 1 to be sent with request 2, got %q", echoed)
+	}
+	if len(echoed) == 2 && echoed[1] != "" {
+		t.Errorf("Expected no cookies to be kept without a jar, got %q", echoed[1])
+	}
+}
+
+func TestHTTPClient_PerHostDelaySpacesRequests(t *testing.T) {
+	server := bodyServer(t, 1)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{PerHostDelay: 100 * time.Millisecond})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/post")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected three requests to take at least 200ms, took %v", elapsed)
+	}
+}
+
+func TestHTTPClient_PerHostDelayStopsWaitingWhenContextDone(t *testing.T) {
+	server := bodyServer(t, 1)
+	client := NewClientWithOptions(CloudflareClient, ClientOptions{PerHostDelay: time.Hour})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetWithContext(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded while waiting for the host, got %v", err)
+	}
+}
+
+func TestHTTPClient_GetWithAcceptReplacesClientTypeAccept(t *testing.T) {
+	server, received := headerEchoServer(t)
+	client := NewClient(BrowserClient)
+
+	resp, err := client.GetWithAccept(context.Background(), server.URL, AcceptXML)
+	if err != nil {
+		t.Fatalf("GetWithAccept failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := received.Get("Accept"); got != AcceptXML {
+		t.Errorf("Expected Accept %q, got %q", AcceptXML, got)
+	}
+	if got := received.Get("Accept-Language"); got != "en-US,en;q=0.9" {
+		t.Errorf("Expected the other browser headers to be kept, got %q", got)
+	}
+
+	// Without an expectation, the client type's Accept is sent
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if got := received.Get("Accept"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Expected the browser Accept header, got %q", got)
+	}
+}
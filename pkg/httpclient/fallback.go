@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// FallbackHTTPClient sends each request with a primary client type and, when the server refuses
+// those headers with 403 (Forbidden) or 406 (Not Acceptable), sends it once more with the other type
+// Cloudflare tends to answer 403 to browser-like requests while other sites answer 406 to curl-like
+// ones, so neither type works everywhere on its own
+type FallbackHTTPClient struct {
+	primary   *HTTPClient
+	secondary *HTTPClient
+}
+
+// NewFallbackClient creates a fallback client that tries primaryType first
+func NewFallbackClient(primaryType ClientType) *FallbackHTTPClient {
+	return NewFallbackClientWithOptions(primaryType, ClientOptions{})
+}
+
+// NewFallbackClientWithOptions creates a fallback client that tries primaryType first; both
+// client types share opts, except that opts.UserAgent is only sent by primaryType
+func NewFallbackClientWithOptions(primaryType ClientType, opts ClientOptions) *FallbackHTTPClient {
+	primary := NewClientWithOptions(primaryType, opts)
+	return &FallbackHTTPClient{
+		primary:   primary,
+		secondary: secondaryClient(primary),
+	}
+}
+
+// secondaryClient returns the client tried after primary is refused
+// A custom User-Agent may be what the server refused, so it sends the other type's preset instead
+func secondaryClient(primary *HTTPClient) *HTTPClient {
+	secondary := primary.WithClientType(otherClientType(primary.clientType))
+	secondary.userAgent = ""
+	return secondary
+}
+
+// otherClientType returns the client type tried after clientType is refused
+func otherClientType(clientType ClientType) ClientType {
+	if clientType == BrowserClient {
+		return CloudflareClient
+	}
+	return BrowserClient
+}
+
+// isRefusal reports whether a status code means the server rejected the client's headers
+func isRefusal(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusNotAcceptable
+}
+
+// Do executes req with the primary client type, retrying once with the other type on a 403 or 406
+// Requests with a body that can't be replayed (no GetBody) are not retried
+func (c *FallbackHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	// Cloned before the primary client sets its headers on req
+	retry := req.Clone(req.Context())
+
+	resp, err := c.primary.Do(req)
+	if err != nil || !isRefusal(resp.StatusCode) {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	resp.Body.Close()
+	return c.secondary.Do(retry)
+}
+
+// GetWithContext is like HTTPClient.GetWithContext, with the fallback of Do
+func (c *FallbackHTTPClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// HeadWithContext is like HTTPClient.HeadWithContext, with the fallback of Do
+func (c *FallbackHTTPClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// ReadBody reads the response body like HTTPClient.ReadBody; both client types share the limit
+func (c *FallbackHTTPClient) ReadBody(resp *http.Response) ([]byte, error) {
+	return c.primary.ReadBody(resp)
+}
+
+// MaxBodyBytes returns the body size limit applied by ReadBody; negative means unlimited
+func (c *FallbackHTTPClient) MaxBodyBytes() int64 {
+	return c.primary.MaxBodyBytes()
+}
+
+// WithClientType returns a copy of the client that tries clientType first and falls back to the other type
+func (c *FallbackHTTPClient) WithClientType(clientType ClientType) *FallbackHTTPClient {
+	primary := c.primary.WithClientType(clientType)
+	return &FallbackHTTPClient{
+		primary:   primary,
+		secondary: secondaryClient(primary),
+	}
+}
+
+// WithOptions returns a new fallback client with the same primary type and opts in place of the
+// client's options
+func (c *FallbackHTTPClient) WithOptions(opts ClientOptions) *FallbackHTTPClient {
+	return NewFallbackClientWithOptions(c.primary.clientType, opts)
+}
@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// refusingServer answers refusal to requests whose User-Agent starts with refusedPrefix and 200
+// otherwise, and records the User-Agents it saw
+func refusingServer(t *testing.T, refusedPrefix string, refusal int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		if strings.HasPrefix(r.Header.Get("User-Agent"), refusedPrefix) {
+			w.WriteHeader(refusal)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &userAgents
+}
+
+func TestFallbackHTTPClient_RetriesWithBrowserOn403(t *testing.T) {
+	server, userAgents := refusingServer(t, "curl/", http.StatusForbidden)
+
+	resp, err := NewFallbackClient(CloudflareClient).GetWithContext(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetWithContext failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the browser retry to succeed, got status %d", resp.StatusCode)
+	}
+	if len(*userAgents) != 2 || !strings.HasPrefix((*userAgents)[0], "curl/") || !strings.HasPrefix((*userAgents)[1], "Mozilla/") {
+		t.Errorf("Expected a curl request then a browser request, got %q", *userAgents)
+	}
+}
+
+func TestFallbackHTTPClient_RetriesWithCloudflareOn406(t *testing.T) {
+	server, userAgents := refusingServer(t, "Mozilla/", http.StatusNotAcceptable)
+
+	resp, err := NewFallbackClient(BrowserClient).HeadWithContext(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("HeadWithContext failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the curl-like retry to succeed, got status %d", resp.StatusCode)
+	}
+	if len(*userAgents) != 2 || (*userAgents)[1] != "curl/8.7.1" {
+		t.Errorf("Expected a browser request then a curl request, got %q", *userAgents)
+	}
+}
+
+func TestFallbackHTTPClient_RetryUsesPresetUserAgent(t *testing.T) {
+	server, userAgents := refusingServer(t, "blog-search/", http.StatusForbidden)
+	client := NewFallbackClientWithOptions(CloudflareClient, ClientOptions{UserAgent: "blog-search/1.0"})
+
+	for _, c := range []*FallbackHTTPClient{client, client.WithClientType(BrowserClient)} {
+		*userAgents = nil
+		resp, err := c.GetWithContext(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("GetWithContext failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected the retry with the preset User-Agent to succeed, got status %d", resp.StatusCode)
+		}
+		if len(*userAgents) != 2 || (*userAgents)[0] != "blog-search/1.0" || strings.HasPrefix((*userAgents)[1], "blog-search/") {
+			t.Errorf("Expected the custom User-Agent then a preset one, got %q", *userAgents)
+		}
+	}
+}
+
+func TestFallbackHTTPClient_NoRetryWhenAccepted(t *testing.T) {
+	server, userAgents := refusingServer(t, "Mozilla/", http.StatusForbidden)
+
+	resp, err := NewFallbackClient(CloudflareClient).GetWithContext(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetWithContext failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(*userAgents) != 1 {
+		t.Errorf("Expected a single request, got %q", *userAgents)
+	}
+}
+
+func TestFallbackHTTPClient_RetriesOnlyOnce(t *testing.T) {
+	server, userAgents := refusingServer(t, "", http.StatusForbidden)
+
+	resp, err := NewFallbackClient(CloudflareClient).GetWithContext(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetWithContext failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the retry's refusal, got status %d", resp.StatusCode)
+	}
+	if len(*userAgents) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(*userAgents))
+	}
+}
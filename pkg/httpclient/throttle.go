@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostThrottle spaces out requests to the same host by a fixed minimum gap
+// Unlike a token bucket it allows no bursts: every request waits for the one before it
+type hostThrottle struct {
+	delay time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest time the next request to the host may start
+}
+
+// newHostThrottle returns a throttle enforcing delay between requests, or nil if delay is not positive
+func newHostThrottle(delay time.Duration) *hostThrottle {
+	if delay <= 0 {
+		return nil
+	}
+	return &hostThrottle{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may start, or returns ctx's error if ctx is done first
+// The slot is reserved before waiting, so concurrent callers for one host are queued in turn
+func (t *hostThrottle) wait(ctx context.Context, host string) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	start := t.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	t.next[host] = start.Add(t.delay)
+	t.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		t.release(host, start)
+		return ctx.Err()
+	}
+}
+
+// release gives back a reserved slot that was never used, if no later request has claimed the one after it
+func (t *hostThrottle) release(host string, start time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next[host].Equal(start.Add(t.delay)) {
+		t.next[host] = start
+	}
+}
@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRollupInterval is how often an ErrorAggregator logs rollups when no interval is given
+const DefaultRollupInterval = 30 * time.Second
+
+// maxAggregatedErrors caps the distinct messages an ErrorAggregator tracks, so errors that
+// differ every time can't grow it without bound; further new messages are logged as they occur
+const maxAggregatedErrors = 1000
+
+// ErrorAggregator keeps repeated errors from flooding the log during big crawls
+// The first occurrence of each distinct error message is logged immediately with its context;
+// repeats are only counted and logged as periodic rollups like "unexpected status code: 403 x 1243"
+// A nil *ErrorAggregator logs every error as it occurs, so callers don't need to check for one
+type ErrorAggregator struct {
+	name     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	errors  map[string]*aggregatedError
+	order   []string // Keys of errors in first-seen order, so rollups are stable
+	lastLog time.Time
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// aggregatedError counts one distinct error message since the last rollup
+type aggregatedError struct {
+	count      int  // Occurrences since the last rollup, including any logged immediately
+	suppressed bool // At least one of them wasn't logged, so a rollup is due
+}
+
+// NewErrorAggregator creates an ErrorAggregator whose log lines are prefixed with name
+// (e.g., "Pipeline"). A non-positive interval uses DefaultRollupInterval
+func NewErrorAggregator(name string, interval time.Duration) *ErrorAggregator {
+	if interval <= 0 {
+		interval = DefaultRollupInterval
+	}
+	return &ErrorAggregator{
+		name:     name,
+		interval: interval,
+		errors:   make(map[string]*aggregatedError),
+		lastLog:  time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Warnf logs like the package-level Warnf the first time err's message is seen; later errors
+// with the same message are counted for the next rollup instead
+func (a *ErrorAggregator) Warnf(err error, format string, args ...any) {
+	if a == nil || err == nil {
+		Warnf(format, args...)
+		return
+	}
+
+	key := errorKey(err)
+	a.mu.Lock()
+	entry, seen := a.errors[key]
+	if !seen && len(a.errors) >= maxAggregatedErrors {
+		a.mu.Unlock()
+		Warnf(format, args...)
+		return
+	}
+	if !seen {
+		entry = &aggregatedError{}
+		a.errors[key] = entry
+		a.order = append(a.order, key)
+	}
+	entry.count++
+	if seen {
+		entry.suppressed = true
+	}
+	a.mu.Unlock()
+
+	if !seen {
+		Warnf(format, args...)
+	}
+}
+
+// Flush logs one rollup line for each message repeated since the last flush and starts a new
+// counting window. Messages seen before are still counted, not logged, when they recur
+func (a *ErrorAggregator) Flush() {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	since := time.Since(a.lastLog).Round(time.Second)
+	a.lastLog = time.Now()
+	var lines []string
+	for _, key := range a.order {
+		entry := a.errors[key]
+		if entry.suppressed {
+			lines = append(lines, fmt.Sprintf("%s x %d", key, entry.count))
+		}
+		entry.count = 0
+		entry.suppressed = false
+	}
+	a.mu.Unlock()
+
+	for _, line := range lines {
+		Warnf("%s: Repeated error in the last %s: %s", a.name, since, line)
+	}
+}
+
+// Start starts logging rollups every interval
+func (a *ErrorAggregator) Start() {
+	if a == nil {
+		return
+	}
+	go a.run()
+}
+
+// Stop logs a final rollup and waits for the rollup goroutine to exit
+// Must be called after Start; calling it more than once is safe
+func (a *ErrorAggregator) Stop() {
+	if a == nil {
+		return
+	}
+	a.stopOnce.Do(func() { close(a.stop) })
+	<-a.done
+}
+
+// run logs rollups until stopped
+func (a *ErrorAggregator) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.Flush()
+		case <-a.stop:
+			a.Flush()
+			return
+		}
+	}
+}
+
+// errorKey is the message errors are grouped by
+// The URL of a failed request (e.g., `Get "https://site.com/post": EOF`) is replaced with a
+// placeholder so the same failure on different pages is counted together
+func errorKey(err error) string {
+	msg := err.Error()
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.URL != "" {
+		msg = strings.ReplaceAll(msg, urlErr.URL, "<url>")
+	}
+	return msg
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorAggregator_RollsUpRepeatedErrors(t *testing.T) {
+	buf := captureOutput(t)
+	a := NewErrorAggregator("Pipeline", time.Hour)
+
+	for i := 0; i < 1243; i++ {
+		err := errors.New("unexpected status code: 403")
+		a.Warnf(err, "Content worker 1: ERROR processing URL https://example.com/%d: %v", i, err)
+	}
+	other := errors.New("unexpected status code: 404")
+	a.Warnf(other, "Content worker 2: ERROR processing URL https://example.com/missing: %v", other)
+
+	out := buf.String()
+	if strings.Count(out, "ERROR processing URL") != 2 {
+		t.Fatalf("Expected only the first occurrence of each error to be logged, got:\n%s", out)
+	}
+	if !strings.Contains(out, "https://example.com/0:") {
+		t.Errorf("Expected the first 403 to be logged with its context, got:\n%s", out)
+	}
+
+	buf.Reset()
+	a.Flush()
+	out = buf.String()
+	if strings.Count(out, "Repeated error") != 1 || !strings.Contains(out, "unexpected status code: 403 x 1243") {
+		t.Errorf("Expected a single rollup for the 403s, got:\n%s", out)
+	}
+
+	// Known errors stay quiet after a rollup and are counted in the next one
+	buf.Reset()
+	err := errors.New("unexpected status code: 403")
+	a.Warnf(err, "ERROR processing URL: %v", err)
+	if buf.Len() != 0 {
+		t.Errorf("Expected a known error not to be logged again, got:\n%s", buf.String())
+	}
+	a.Flush()
+	if !strings.Contains(buf.String(), "unexpected status code: 403 x 1") {
+		t.Errorf("Expected the next rollup to count it, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	a.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("Expected no rollup without new errors, got:\n%s", buf.String())
+	}
+}
+
+func TestErrorAggregator_GroupsRequestErrorsAcrossURLs(t *testing.T) {
+	buf := captureOutput(t)
+	a := NewErrorAggregator("Pipeline", time.Hour)
+
+	for _, page := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		err := fmt.Errorf("failed to fetch URL: %w", &url.Error{Op: "Get", URL: page, Err: errors.New("EOF")})
+		a.Warnf(err, "ERROR processing URL %s: %v", page, err)
+	}
+	a.Flush()
+
+	out := buf.String()
+	if strings.Count(out, "ERROR processing URL") != 1 || !strings.Contains(out, `failed to fetch URL: Get \"<url>\": EOF x 3`) {
+		t.Errorf("Expected the request errors to be grouped, got:\n%s", out)
+	}
+}
+
+func TestErrorAggregator_StopLogsFinalRollup(t *testing.T) {
+	buf := captureOutput(t)
+	a := NewErrorAggregator("TwoLevelManager", time.Hour)
+	a.Start()
+
+	err := errors.New("unexpected status code: 503")
+	a.Warnf(err, "ERROR: %v", err)
+	a.Warnf(err, "ERROR: %v", err)
+	a.Stop()
+	a.Stop()
+
+	if !strings.Contains(buf.String(), "TwoLevelManager: Repeated error") || !strings.Contains(buf.String(), "503 x 2") {
+		t.Errorf("Expected a final rollup on Stop, got:\n%s", buf.String())
+	}
+
+	var nilAggregator *ErrorAggregator
+	buf.Reset()
+	nilAggregator.Warnf(err, "ERROR: %v", err)
+	nilAggregator.Flush()
+	if !strings.Contains(buf.String(), "ERROR: unexpected status code: 503") {
+		t.Errorf("Expected a nil aggregator to log every error, got:\n%s", buf.String())
+	}
+}
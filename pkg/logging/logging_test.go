@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureOutput routes logging into a buffer through an slog handler for the duration of the test
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: Level()}))
+
+	previous := Level().Level()
+	t.Cleanup(func() {
+		SetLevel(previous)
+		SetOutput(os.Stderr)
+	})
+	return &buf
+}
+
+func TestDebugSuppressedAtInfoLevel(t *testing.T) {
+	buf := captureOutput(t)
+	SetLevel(slog.LevelInfo)
+
+	Debugf("fetched %s", "https://example.com/a")
+	Infof("step %s done", "Sitemap Fetcher")
+
+	out := buf.String()
+	if strings.Contains(out, "https://example.com/a") {
+		t.Errorf("Expected Debug line to be suppressed at Info level, got:\n%s", out)
+	}
+	if !strings.Contains(out, "step Sitemap Fetcher done") || !strings.Contains(out, "level=INFO") {
+		t.Errorf("Expected Info line in output, got:\n%s", out)
+	}
+}
+
+func TestDebugWrittenAtDebugLevel(t *testing.T) {
+	buf := captureOutput(t)
+	SetLevel(slog.LevelDebug)
+
+	Debugf("fetched %s", "https://example.com/a")
+
+	if !strings.Contains(buf.String(), "level=DEBUG") {
+		t.Errorf("Expected Debug line at Debug level, got:\n%s", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level, got nil")
+	}
+}
@@ -0,0 +1,175 @@
+// Package metrics tracks crawl throughput and serves it in the Prometheus text format
+//
+// The metrics are process-wide; crawlers increment them and MetricsHandler exposes them.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ArticlesSaved counts articles successfully written to storage
+	ArticlesSaved = newCounter("articles_saved_total", "Articles successfully saved to storage.")
+
+	// FetchErrors counts content URLs that could not be fetched or extracted
+	FetchErrors = newCounter("fetch_errors_total", "Content URLs that failed to fetch or extract.")
+
+	// URLsDiscovered counts URLs produced by URL-discovery steps
+	URLsDiscovered = newCounter("urls_discovered_total", "URLs produced by URL-discovery steps.")
+
+	// FetchLatency observes how long content page fetches take, in seconds
+	FetchLatency = newHistogram("fetch_duration_seconds", "Content page fetch latency in seconds.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30})
+)
+
+// metric is anything that can write itself in the Prometheus text format
+type metric interface {
+	name() string
+	writeTo(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []metric
+)
+
+func register(m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, m)
+}
+
+// Counter is a monotonically increasing count
+type Counter struct {
+	metricName string
+	help       string
+	value      atomic.Uint64
+}
+
+func newCounter(name, help string) *Counter {
+	c := &Counter{metricName: name, help: help}
+	register(c)
+	return c
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add adds n to the counter
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+func (c *Counter) name() string {
+	return c.metricName
+}
+
+func (c *Counter) writeTo(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.metricName, c.help, c.metricName, c.metricName, c.Value())
+}
+
+// Histogram counts observations into cumulative upper-bound buckets
+type Histogram struct {
+	metricName string
+	help       string
+	bounds     []float64
+
+	mu     sync.Mutex
+	counts []uint64 // Per bucket, non-cumulative; the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(name, help string, bounds []float64) *Histogram {
+	sorted := make([]float64, len(bounds))
+	copy(sorted, bounds)
+	sort.Float64s(sorted)
+	h := &Histogram{metricName: name, help: help, bounds: sorted, counts: make([]uint64, len(sorted)+1)}
+	register(h)
+	return h
+}
+
+// Observe records a single value
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// ObserveSince records the seconds elapsed since start
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) name() string {
+	return h.metricName
+}
+
+func (h *Histogram) writeTo(w io.Writer) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.metricName, h.help, h.metricName)
+	var cumulative uint64
+	for i, bound := range append(h.bounds, math.Inf(1)) {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.metricName, formatBound(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", h.metricName, strconv.FormatFloat(sum, 'g', -1, 64), h.metricName, count)
+}
+
+// formatBound formats a bucket bound the way Prometheus clients do ("+Inf" for the last bucket)
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
+
+// MetricsHandler serves all metrics in the Prometheus text exposition format
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+// WriteText writes all metrics, sorted by name, in the Prometheus text exposition format
+func WriteText(w io.Writer) {
+	registryMu.Lock()
+	metrics := append([]metric(nil), registry...)
+	registryMu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	for _, m := range metrics {
+		m.writeTo(w)
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler_ServesCountersAndHistogram(t *testing.T) {
+	before := ArticlesSaved.Value()
+	ArticlesSaved.Inc()
+	FetchLatency.Observe(0.3)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected text/plain content type, got %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE articles_saved_total counter",
+		"articles_saved_total " + strconv.FormatUint(before+1, 10),
+		"# TYPE fetch_errors_total counter",
+		"# TYPE urls_discovered_total counter",
+		"# TYPE fetch_duration_seconds histogram",
+		`fetch_duration_seconds_bucket{le="+Inf"}`,
+		"fetch_duration_seconds_count",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in output:\n%s", want, body)
+		}
+	}
+}
+
+func TestHistogram_BucketsAreCumulative(t *testing.T) {
+	h := &Histogram{metricName: "test_seconds", bounds: []float64{1, 5}, counts: make([]uint64, 3)}
+	h.Observe(0.5)
+	h.Observe(1)
+	h.Observe(3)
+	h.Observe(60)
+
+	var sb strings.Builder
+	h.writeTo(&sb)
+	out := sb.String()
+
+	for _, want := range []string{
+		`test_seconds_bucket{le="1"} 2`,
+		`test_seconds_bucket{le="5"} 3`,
+		`test_seconds_bucket{le="+Inf"} 4`,
+		"test_seconds_sum 64.5",
+		"test_seconds_count 4",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -1,13 +1,25 @@
 package pipeline
 
 import (
+	"time"
+
 	"blog-search/pkg/content"
 	"blog-search/pkg/db"
 	"blog-search/pkg/urls"
 )
 
+const (
+	// defaultMaxConsecutiveSaveFailures stops the built pipelines when the database stops accepting writes
+	defaultMaxConsecutiveSaveFailures = 20
+
+	// defaultContentRetries and defaultContentRetryBackoff retry transient fetch failures (e.g., 503s)
+	defaultContentRetries      = 2
+	defaultContentRetryBackoff = 2 * time.Second
+)
+
 // RSSPipelineBuilder builds a pipeline for RSS feeds
 // Pipeline: BaseURL → [RSS Fetcher] → [Content Consumer]
+// Items that embed the full post (content:encoded) are saved without fetching the page
 func RSSPipelineBuilder(dbClient *db.Client, urlFetcherWorkers, contentWorkers int, filters ...urls.UrlFilter) *Pipeline {
 	var fetcher URLFetcher
 	if len(filters) > 0 {
@@ -25,8 +37,10 @@ func RSSPipelineBuilder(dbClient *db.Client, urlFetcherWorkers, contentWorkers i
 
 	consumer := ContentConsumer{
 		WorkerCount:      contentWorkers,
-		ContentProcessor: NewHTTPContentProcessor(),
+		ContentProcessor: NewFeedContentProcessor(NewRetryingContentProcessor(NewHTTPContentProcessor(), defaultContentRetries, defaultContentRetryBackoff)),
 		ContentSaver:     NewDBContentSaver(dbClient),
+
+		MaxConsecutiveSaveFailures: defaultMaxConsecutiveSaveFailures,
 	}
 
 	return NewPipeline([]PipelineStep{step}, consumer)
@@ -34,25 +48,21 @@ func RSSPipelineBuilder(dbClient *db.Client, urlFetcherWorkers, contentWorkers i
 
 // SitemapPipelineBuilder builds a pipeline for Sitemaps
 // Pipeline: BaseURL → [Sitemap Fetcher] → [Content Consumer]
+// The sitemap is streamed, so content workers start on the first URLs while the rest is still being parsed
 func SitemapPipelineBuilder(dbClient *db.Client, urlFetcherWorkers, contentWorkers int, filters ...urls.UrlFilter) *Pipeline {
-	var fetcher URLFetcher
-	if len(filters) > 0 {
-		fetcher = NewBasicURLFetcherWithFilters(urls.NewSitemapParser(), filters)
-	} else {
-		fetcher = NewBasicURLFetcher(urls.NewSitemapParser())
-	}
-
 	step := PipelineStep{
 		Name:        "Sitemap Fetcher",
 		WorkerCount: urlFetcherWorkers,
 		Generator:   nil, // Uses Fetcher with baseURL
-		Fetcher:     fetcher,
+		Fetcher:     NewSitemapStreamFetcher(filters),
 	}
 
 	consumer := ContentConsumer{
 		WorkerCount:      contentWorkers,
-		ContentProcessor: NewHTTPContentProcessor(),
+		ContentProcessor: NewRetryingContentProcessor(NewHTTPContentProcessor(), defaultContentRetries, defaultContentRetryBackoff),
 		ContentSaver:     NewDBContentSaver(dbClient),
+
+		MaxConsecutiveSaveFailures: defaultMaxConsecutiveSaveFailures,
 	}
 
 	return NewPipeline([]PipelineStep{step}, consumer)
@@ -61,23 +71,20 @@ func SitemapPipelineBuilder(dbClient *db.Client, urlFetcherWorkers, contentWorke
 // PaginationPipelineBuilder builds a pipeline for paginated HTML sites
 // Pipeline: [Page Range Generator] → [HTML Page Fetcher] → [Content Consumer]
 // baseURL: the base URL (e.g., "https://site.com")
-// pagePattern: the pattern for page URLs with %d placeholder (e.g., "/page/%d" or "/page-bla-blah/%d")
-func PaginationPipelineBuilder(dbClient *db.Client, baseURL, pagePattern string, pagesPerBatch, pageGenWorkers, htmlFetcherWorkers, contentWorkers int, extractor urls.URLExtractor, filters ...urls.UrlFilter) *Pipeline {
+// pagePattern: the pattern for page URLs with %d placeholder (e.g., "/page/%d" or "/blog?page=%d&sort=new"),
+// or a full page URL template; see NewPageRangeGenerator
+// pageOpts: optional PageRangeGenerator settings (e.g., WithEmptyContentMarkers)
+func PaginationPipelineBuilder(dbClient *db.Client, baseURL, pagePattern string, pagesPerBatch, pageGenWorkers, htmlFetcherWorkers, contentWorkers int, extractor urls.BaseURLExtractor, pageOpts []PageRangeOption, filters ...urls.UrlFilter) *Pipeline {
 	// Step 1: Generate page URLs (uses Generator, not Fetcher)
 	step1 := PipelineStep{
 		Name:        "Page Range Generator",
 		WorkerCount: pageGenWorkers,
-		Generator:   NewPageRangeGenerator(baseURL, pagePattern, pagesPerBatch, extractor),
+		Generator:   NewPageRangeGenerator(baseURL, pagePattern, pagesPerBatch, nil, pageOpts...),
 		Fetcher:     nil, // First step uses Generator
 	}
 
 	// Step 2: Extract article URLs from each page (uses Fetcher with filters)
-	var fetcher URLFetcher
-	if len(filters) > 0 {
-		fetcher = NewHTMLPageFetcherWithFilters(extractor, filters)
-	} else {
-		fetcher = NewHTMLPageFetcher(extractor)
-	}
+	fetcher := NewHTMLPageFetcherWithBase(extractor, filters)
 
 	step2 := PipelineStep{
 		Name:        "HTML Page Fetcher",
@@ -88,34 +95,55 @@ func PaginationPipelineBuilder(dbClient *db.Client, baseURL, pagePattern string,
 
 	consumer := ContentConsumer{
 		WorkerCount:      contentWorkers,
-		ContentProcessor: NewHTTPContentProcessor(),
+		ContentProcessor: NewRetryingContentProcessor(NewHTTPContentProcessor(), defaultContentRetries, defaultContentRetryBackoff),
 		ContentSaver:     NewDBContentSaver(dbClient),
+
+		MaxConsecutiveSaveFailures: defaultMaxConsecutiveSaveFailures,
 	}
 
 	return NewPipeline([]PipelineStep{step1, step2}, consumer)
 }
 
+// FollowPaginationPipelineBuilder builds a pipeline for category or archive pages that link to
+// their next page, for sites whose page URLs don't follow a pattern
+// Pipeline: IndexURL → [Follow Pages Fetcher] → [Content Consumer]
+// Article URLs are handed to content workers page by page while the next pages are being followed
+func FollowPaginationPipelineBuilder(dbClient *db.Client, contentWorkers int, extractor urls.BaseURLExtractor, filters ...urls.UrlFilter) *Pipeline {
+	step := PipelineStep{
+		Name:        "Follow Pages Fetcher",
+		WorkerCount: 1,   // Pages are followed one after another
+		Generator:   nil, // Uses Fetcher with baseURL
+		Fetcher:     NewFollowPagesFetcher(extractor, filters),
+	}
+
+	consumer := ContentConsumer{
+		WorkerCount:      contentWorkers,
+		ContentProcessor: NewRetryingContentProcessor(NewHTTPContentProcessor(), defaultContentRetries, defaultContentRetryBackoff),
+		ContentSaver:     NewDBContentSaver(dbClient),
+
+		MaxConsecutiveSaveFailures: defaultMaxConsecutiveSaveFailures,
+	}
+
+	return NewPipeline([]PipelineStep{step}, consumer)
+}
+
 // DataEngineeringPodcastPipelineBuilder builds a pipeline specifically for dataengineeringpodcast.com
 // It uses the DataEngineeringPodcastExtractor which extracts transcript text instead of general content
 // Pipeline: [Page Range Generator] → [HTML Page Fetcher] → [Content Consumer with Custom Extractor]
 // baseURL: the base URL (e.g., "https://www.dataengineeringpodcast.com")
 // pagePattern: the pattern for page URLs with %d placeholder (e.g., "/page/%d")
-func DataEngineeringPodcastPipelineBuilder(dbClient *db.Client, baseURL, pagePattern string, pagesPerBatch, pageGenWorkers, htmlFetcherWorkers, contentWorkers int, extractor urls.URLExtractor, filters ...urls.UrlFilter) *Pipeline {
+// pageOpts: optional PageRangeGenerator settings (e.g., WithEmptyContentMarkers)
+func DataEngineeringPodcastPipelineBuilder(dbClient *db.Client, baseURL, pagePattern string, pagesPerBatch, pageGenWorkers, htmlFetcherWorkers, contentWorkers int, extractor urls.BaseURLExtractor, pageOpts []PageRangeOption, filters ...urls.UrlFilter) *Pipeline {
 	// Step 1: Generate page URLs (uses Generator, not Fetcher)
 	step1 := PipelineStep{
 		Name:        "Page Range Generator",
 		WorkerCount: pageGenWorkers,
-		Generator:   NewPageRangeGenerator(baseURL, pagePattern, pagesPerBatch, extractor),
+		Generator:   NewPageRangeGenerator(baseURL, pagePattern, pagesPerBatch, nil, pageOpts...),
 		Fetcher:     nil, // First step uses Generator
 	}
 
 	// Step 2: Extract article URLs from each page (uses Fetcher with filters)
-	var fetcher URLFetcher
-	if len(filters) > 0 {
-		fetcher = NewHTMLPageFetcherWithFilters(extractor, filters)
-	} else {
-		fetcher = NewHTMLPageFetcher(extractor)
-	}
+	fetcher := NewHTMLPageFetcherWithBase(extractor, filters)
 
 	step2 := PipelineStep{
 		Name:        "HTML Page Fetcher",
@@ -127,8 +155,10 @@ func DataEngineeringPodcastPipelineBuilder(dbClient *db.Client, baseURL, pagePat
 	// Use custom extractor for dataengineeringpodcast that extracts transcript
 	consumer := ContentConsumer{
 		WorkerCount:      contentWorkers,
-		ContentProcessor: NewHTTPContentProcessorWithExtractor(content.NewDataEngineeringPodcastExtractor()),
+		ContentProcessor: NewRetryingContentProcessor(NewHTTPContentProcessorWithExtractor(content.NewDataEngineeringPodcastExtractor()), defaultContentRetries, defaultContentRetryBackoff),
 		ContentSaver:     NewDBContentSaver(dbClient),
+
+		MaxConsecutiveSaveFailures: defaultMaxConsecutiveSaveFailures,
 	}
 
 	return NewPipeline([]PipelineStep{step1, step2}, consumer)
@@ -3,10 +3,11 @@ package pipeline
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"blog-search/pkg/httpclient"
 	"blog-search/pkg/urls"
@@ -15,8 +16,10 @@ import (
 // BasicUrlFetcher wraps a URLsFetcher to extract URLs from a base URL
 // Used for RSS, Sitemap, etc. where we extract URLs directly from base URL
 type BasicUrlFetcher struct {
-	fetcher urls.URLsFetcher
-	filters []urls.UrlFilter
+	fetcher    urls.URLsFetcher
+	filters    []urls.UrlFilter
+	requestSem chan struct{}
+	feedItems  *FeedItemIndex // Set by the pipeline; receives the feed data of fetched URLs
 }
 
 // NewBasicURLFetcher creates a new base URL fetcher
@@ -35,15 +38,44 @@ func NewBasicURLFetcherWithFilters(fetcher urls.URLsFetcher, filters []urls.UrlF
 	}
 }
 
+// SetRequestSemaphore limits concurrent fetches using a semaphore shared with other workers
+func (f *BasicUrlFetcher) SetRequestSemaphore(sem chan struct{}) {
+	f.requestSem = sem
+}
+
+// SetClientType forwards the client type to the wrapped fetcher if it supports it (e.g., urls.HTMLFetcher)
+func (f *BasicUrlFetcher) SetClientType(clientType httpclient.ClientType) {
+	if setter, ok := f.fetcher.(ClientTypeSetter); ok {
+		setter.SetClientType(clientType)
+	}
+}
+
+// SetClientOptions forwards the client options to the wrapped fetcher if it supports it (e.g., urls.RSSParser)
+func (f *BasicUrlFetcher) SetClientOptions(opts httpclient.ClientOptions) {
+	if setter, ok := f.fetcher.(ClientOptionsSetter); ok {
+		setter.SetClientOptions(opts)
+	}
+}
+
+// SetFeedItemIndex records the feed data of fetched URLs (e.g., descriptions, full content) in index
+func (f *BasicUrlFetcher) SetFeedItemIndex(index *FeedItemIndex) {
+	f.feedItems = index
+}
+
 // Fetch extracts URLs from the given base URL and applies filters
 func (f *BasicUrlFetcher) Fetch(ctx context.Context, baseURL string) ([]string, error) {
 	log.Printf("BasicUrlFetcher: Fetching URLs from %s", baseURL)
+	if err := acquireRequestSlot(ctx, f.requestSem); err != nil {
+		return nil, err
+	}
 	urls, err := f.fetchURLs(baseURL)
+	releaseRequestSlot(f.requestSem)
 	if err != nil {
 		return nil, err
 	}
 
 	result := f.extractLocations(urls)
+	f.recordFeedItems(urls)
 
 	// Apply filters if any
 	if len(f.filters) > 0 {
@@ -58,15 +90,31 @@ func (f *BasicUrlFetcher) Fetch(ctx context.Context, baseURL string) ([]string,
 	return result, nil
 }
 
-// fetchURLs fetches URLs from the underlying fetcher
+// fetchURLs fetches URLs from the underlying fetcher, logging where they came from
 func (f *BasicUrlFetcher) fetchURLs(baseURL string) ([]urls.URL, error) {
-	urls, err := f.fetcher.Fetch(baseURL)
+	result, err := urls.FetchWithMeta(f.fetcher, baseURL)
 	if err != nil {
 		log.Printf("BasicUrlFetcher: ERROR fetching URLs from %s: %v", baseURL, err)
 		return nil, fmt.Errorf("failed to fetch URLs: %w", err)
 	}
-	log.Printf("BasicUrlFetcher: Fetched %d URLs from %s", len(urls), baseURL)
-	return urls, nil
+	log.Printf("BasicUrlFetcher: Fetched %d URLs from %s", result.Count, describeSource(result))
+	return result.URLs, nil
+}
+
+// describeSource names a fetch result's source for logs, e.g., `rss feed "Kafka Blog" (https://...)`
+func describeSource(result *urls.FetchResult) string {
+	source := result.SourceURL
+	if result.Title != "" {
+		source = fmt.Sprintf("%q (%s)", result.Title, source)
+	}
+	switch result.SourceType {
+	case urls.SourceRSS:
+		return "rss feed " + source
+	case "":
+		return source
+	default:
+		return result.SourceType + " " + source
+	}
 }
 
 // extractLocations extracts location strings from URL structs
@@ -81,38 +129,248 @@ func (f *BasicUrlFetcher) extractLocations(urls []urls.URL) []string {
 	return result
 }
 
+// recordFeedItems stores the feed data of the fetched URLs in the pipeline's feed item index
+func (f *BasicUrlFetcher) recordFeedItems(urls []urls.URL) {
+	if f.feedItems == nil {
+		return
+	}
+	for _, u := range urls {
+		f.feedItems.Set(u.Location, FeedItem{Title: u.Title, Summary: u.Summary, Content: u.Content})
+	}
+}
+
 // applyFilters applies URL filters to the result set
 func (f *BasicUrlFetcher) applyFilters(ctx context.Context, result []string) ([]string, error) {
-	if len(f.filters) == 0 {
-		return result, nil
-	}
+	return filterURLs(ctx, f.filters, result)
+}
 
-	filtered := make([]string, 0, len(result))
-	for _, urlStr := range result {
-		shouldKeep, err := f.shouldKeepURL(ctx, urlStr)
+// filterURLs keeps only the URLs that pass every filter
+// Filters are applied in order, each to the URLs kept by the previous ones; filters that
+// implement urls.BatchUrlFilter get all of those URLs in one call
+func filterURLs(ctx context.Context, filters []urls.UrlFilter, result []string) ([]string, error) {
+	filtered := result
+	for _, filter := range filters {
+		var err error
+		filtered, err = applyFilter(ctx, filter, filtered)
 		if err != nil {
 			return nil, fmt.Errorf("filter error: %w", err)
 		}
-		if shouldKeep {
-			filtered = append(filtered, urlStr)
-		}
 	}
 	return filtered, nil
 }
 
-// shouldKeepURL checks if a URL should be kept after applying all filters
-// Returns (shouldKeep, error) - error is returned if any filter fails
-func (f *BasicUrlFetcher) shouldKeepURL(ctx context.Context, urlStr string) (bool, error) {
-	for _, filter := range f.filters {
+// applyFilter returns the URLs kept by a single filter, in order
+func applyFilter(ctx context.Context, filter urls.UrlFilter, candidates []string) ([]string, error) {
+	if batch, ok := filter.(urls.BatchUrlFilter); ok {
+		return batch.FilterURLs(ctx, candidates)
+	}
+
+	kept := make([]string, 0, len(candidates))
+	for _, urlStr := range candidates {
 		keep, err := filter.ShouldKeep(ctx, urlStr)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-		if !keep {
-			return false, nil
+		if keep {
+			kept = append(kept, urlStr)
 		}
 	}
-	return true, nil
+	return kept, nil
+}
+
+// DefaultStreamChunkSize is how many URLs a SitemapStreamFetcher filters and hands on at a time
+const DefaultStreamChunkSize = 100
+
+// SitemapStreamFetcher streams the URLs of a sitemap (or sitemap index) in chunks while it is
+// being parsed, so a sitemap with hundreds of thousands of URLs is never held in memory at once
+// Implements URLStreamer; Fetch collects every URL for use where streaming isn't possible
+type SitemapStreamFetcher struct {
+	parser    *urls.SitemapParser
+	filters   []urls.UrlFilter
+	chunkSize int
+}
+
+// NewSitemapStreamFetcher creates a new streaming sitemap fetcher that applies filters to each chunk
+func NewSitemapStreamFetcher(filters []urls.UrlFilter) *SitemapStreamFetcher {
+	return &SitemapStreamFetcher{
+		parser:    urls.NewSitemapParser(),
+		filters:   filters,
+		chunkSize: DefaultStreamChunkSize,
+	}
+}
+
+// SetChunkSize sets how many URLs are filtered and handed on at a time (non-positive uses the default)
+func (f *SitemapStreamFetcher) SetChunkSize(size int) {
+	if size <= 0 {
+		size = DefaultStreamChunkSize
+	}
+	f.chunkSize = size
+}
+
+// SetClientOptions replaces the options of the client that fetches the sitemaps
+func (f *SitemapStreamFetcher) SetClientOptions(opts httpclient.ClientOptions) {
+	f.parser.SetClientOptions(opts)
+}
+
+// StreamURLs calls send with each chunk of filtered URLs from the sitemap at baseURL
+func (f *SitemapStreamFetcher) StreamURLs(ctx context.Context, baseURL string, send func(urls []string) error) error {
+	log.Printf("SitemapStreamFetcher: Streaming URLs from %s", baseURL)
+	chunk := make([]string, 0, f.chunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		kept, err := filterURLs(ctx, f.filters, chunk)
+		chunk = make([]string, 0, f.chunkSize)
+		if err != nil {
+			return err
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		return send(kept)
+	}
+
+	err := f.parser.Stream(ctx, baseURL, func(u urls.URL) error {
+		chunk = append(chunk, u.Location)
+		if len(chunk) < f.chunkSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stream sitemap: %w", err)
+	}
+	return flush()
+}
+
+// Fetch returns every filtered URL from the sitemap at baseURL
+func (f *SitemapStreamFetcher) Fetch(ctx context.Context, baseURL string) ([]string, error) {
+	var result []string
+	err := f.StreamURLs(ctx, baseURL, func(chunk []string) error {
+		result = append(result, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DefaultFollowMaxPages is how many listing pages a FollowPagesFetcher visits at most by default
+const DefaultFollowMaxPages = 1000
+
+// FollowPagesFetcher crawls a paginated listing (e.g., a category or archive page) by following
+// its next-page links: each page's article URLs are extracted and handed on, then the page's
+// next link (see urls.ExtractNextPageURL) is fetched, until a page has none
+// Unlike PageRangeGenerator it needs no page URL pattern
+// Implements URLStreamer; Fetch collects every URL for use where streaming isn't possible
+type FollowPagesFetcher struct {
+	fetcher  *urls.HTMLFetcher
+	filters  []urls.UrlFilter
+	maxPages int
+}
+
+// NewFollowPagesFetcher creates a fetcher that extracts article URLs from each listing page
+// with extractor and applies filters to them
+func NewFollowPagesFetcher(extractor urls.BaseURLExtractor, filters []urls.UrlFilter) *FollowPagesFetcher {
+	return &FollowPagesFetcher{
+		fetcher:  urls.NewHTMLFetcherWithBaseExtractor(extractor),
+		filters:  filters,
+		maxPages: DefaultFollowMaxPages,
+	}
+}
+
+// SetClientType switches the headers sent with listing page fetches to those of clientType
+func (f *FollowPagesFetcher) SetClientType(clientType httpclient.ClientType) {
+	f.fetcher.SetClientType(clientType)
+}
+
+// SetClientOptions replaces the options of the client that fetches the listing pages
+func (f *FollowPagesFetcher) SetClientOptions(opts httpclient.ClientOptions) {
+	f.fetcher.SetClientOptions(opts)
+}
+
+// SetMaxPages limits how many listing pages are visited (non-positive uses the default)
+// Protects against sites whose next links never run out
+func (f *FollowPagesFetcher) SetMaxPages(maxPages int) {
+	if maxPages <= 0 {
+		maxPages = DefaultFollowMaxPages
+	}
+	f.maxPages = maxPages
+}
+
+// StreamURLs calls send with the filtered article URLs of each page, starting at indexURL
+// A page whose article links can't be extracted is skipped if it links to a next page;
+// a page that fails to load ends the crawl with an error
+func (f *FollowPagesFetcher) StreamURLs(ctx context.Context, indexURL string, send func(urls []string) error) error {
+	log.Printf("FollowPagesFetcher: Following pages from %s", indexURL)
+	visited := make(map[string]bool)
+	pageURL := indexURL
+
+	for pages := 0; pageURL != ""; pages++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if pages >= f.maxPages {
+			log.Printf("FollowPagesFetcher: Reached max pages limit (%d), stopping", f.maxPages)
+			return nil
+		}
+		visited[pageURL] = true
+
+		found, next, err := f.fetcher.FetchWithNext(pageURL)
+		if err != nil && next == "" {
+			return fmt.Errorf("failed to follow page %s: %w", pageURL, err)
+		}
+		if err != nil {
+			log.Printf("FollowPagesFetcher: Skipping page %s: %v", pageURL, err)
+		}
+
+		if err := f.sendPage(ctx, found, send); err != nil {
+			return err
+		}
+
+		if visited[next] {
+			log.Printf("FollowPagesFetcher: Next page %s was already visited, stopping", next)
+			break
+		}
+		pageURL = next
+	}
+
+	log.Printf("FollowPagesFetcher: Reached the last page after %d pages", len(visited))
+	return nil
+}
+
+// sendPage filters one page's article URLs and sends the ones that are kept
+func (f *FollowPagesFetcher) sendPage(ctx context.Context, found []urls.URL, send func(urls []string) error) error {
+	locations := make([]string, 0, len(found))
+	for _, u := range found {
+		if u.Location != "" {
+			locations = append(locations, u.Location)
+		}
+	}
+
+	kept, err := filterURLs(ctx, f.filters, locations)
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return send(kept)
+}
+
+// Fetch returns every filtered article URL from the listing starting at indexURL
+func (f *FollowPagesFetcher) Fetch(ctx context.Context, indexURL string) ([]string, error) {
+	var result []string
+	err := f.StreamURLs(ctx, indexURL, func(chunk []string) error {
+		result = append(result, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // NewHTMLPageFetcher creates a BasicUrlFetcher for HTML pages
@@ -127,40 +385,169 @@ func NewHTMLPageFetcherWithFilters(extractor urls.URLExtractor, filters []urls.U
 	return NewBasicURLFetcherWithFilters(urls.NewHTMLFetcher(extractor), filters)
 }
 
+// NewHTMLPageFetcherWithBase creates a BasicUrlFetcher for HTML pages whose extractor
+// receives each page's URL, so relative article links resolve correctly
+func NewHTMLPageFetcherWithBase(extractor urls.BaseURLExtractor, filters []urls.UrlFilter) *BasicUrlFetcher {
+	return NewBasicURLFetcherWithFilters(urls.NewHTMLFetcherWithBaseExtractor(extractor), filters)
+}
+
 // PageRangeGenerator generates page URLs from a base URL and page pattern
 // Used for paginated sites where we need to generate URLs like "https://site.com/page/1", "page/2", etc.
 // It generates page URLs until it finds a page that doesn't exist (404 or other error)
 // or contains content indicating no more pages (e.g., "0 episodes found")
 // Implements URLGenerator interface
 type PageRangeGenerator struct {
-	baseURL             string                 // Base URL (e.g., "https://site.com")
-	pagePattern         string                 // Page pattern with %d placeholder (e.g., "/page/%d" or "/page-bla-blah/%d")
+	urlTemplate         string                 // Page URL with one %d placeholder (e.g., "https://site.com/page/%d")
+	initErr             error                  // Set when the template is invalid; returned by Generate
 	pagesPerBatch       int                    // Not currently used, kept for backward compatibility
 	httpClient          *httpclient.HTTPClient // Used to check if a page exists via HEAD request
 	emptyContentMarkers []string               // Strings that indicate no content (e.g., "0 episodes found")
+	startPage           int                    // First page number (default 1)
+	step                int                    // Increment between page numbers (default 1)
+	maxPages            int                    // Maximum number of page URLs to generate (0 = unlimited)
+	contentCheckEvery   int                    // Check page content for empty markers every N pages (0 = never)
+	existsRetries       int                    // Retries of a page existence check that failed with a network error or 5xx
+	existsBackoff       time.Duration          // Delay before the first existence check retry, doubled after each one
 }
 
+// PageRangeOption configures optional PageRangeGenerator behavior
+type PageRangeOption func(*PageRangeGenerator)
+
+// WithStartPage sets the first page number (e.g., 0 for zero-based pagination)
+func WithStartPage(startPage int) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.startPage = startPage
+	}
+}
+
+// WithPageStep sets the increment between page numbers (e.g., 2 for "?page=2,4,6")
+func WithPageStep(step int) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.step = step
+	}
+}
+
+// WithMaxPages stops generation after maxPages page URLs (0 = unlimited)
+// Protects against sites that return 200 for every page number
+func WithMaxPages(maxPages int) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.maxPages = maxPages
+	}
+}
+
+// WithEmptyContentMarkers replaces the default empty-content markers (e.g., "No posts found")
+// Matching is case-insensitive
+func WithEmptyContentMarkers(markers ...string) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.emptyContentMarkers = markers
+	}
+}
+
+// WithContentCheckInterval sets how often (in pages) the page content is checked for
+// empty-content markers; 0 disables content checks
+func WithContentCheckInterval(every int) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.contentCheckEvery = every
+	}
+}
+
+// WithExistenceRetries sets how often a page existence check that fails with a network error,
+// 5xx or 429 is retried, and the delay before the first retry (doubled after each one)
+// 0 retries stops pagination at the first such failure
+func WithExistenceRetries(retries int, backoff time.Duration) PageRangeOption {
+	return func(f *PageRangeGenerator) {
+		f.existsRetries = retries
+		f.existsBackoff = backoff
+	}
+}
+
+// Default retries of a failed page existence check; a transient error shouldn't end a crawl
+const (
+	defaultExistenceRetries = 3
+	defaultExistenceBackoff = 500 * time.Millisecond
+)
+
 // NewPageRangeGenerator creates a new page range generator
 // baseURL: the base URL (e.g., "https://site.com")
-// pagePattern: the pattern for page URLs with %d placeholder (e.g., "/page/%d" or "/page-bla-blah/%d")
+// pagePattern: the pattern for page URLs with %d placeholder (e.g., "/page/%d", "/blog?page=%d&sort=new"),
+// or a full URL template (e.g., "https://site.com/blog?page=%d"), in which case baseURL is not prepended
 // pagesPerBatch: not currently used, kept for backward compatibility
 // extractor: not currently used, kept for backward compatibility (HEAD requests don't need content extraction)
-func NewPageRangeGenerator(baseURL, pagePattern string, pagesPerBatch int, extractor urls.URLExtractor) *PageRangeGenerator {
-	return &PageRangeGenerator{
-		baseURL:             baseURL,
-		pagePattern:         pagePattern,
+// opts: optional settings such as WithStartPage, WithPageStep, WithMaxPages and WithEmptyContentMarkers
+// An invalid pattern is reported by Generate; use NewPageRangeGeneratorE to get the error up front
+func NewPageRangeGenerator(baseURL, pagePattern string, pagesPerBatch int, extractor urls.URLExtractor, opts ...PageRangeOption) *PageRangeGenerator {
+	f, err := NewPageRangeGeneratorE(baseURL, pagePattern, pagesPerBatch, extractor, opts...)
+	if err != nil {
+		f.initErr = err
+	}
+	return f
+}
+
+// NewPageRangeGeneratorE is like NewPageRangeGenerator, but returns an error if the page URL
+// template doesn't contain exactly one %d
+// The returned generator is non-nil even on error
+func NewPageRangeGeneratorE(baseURL, pagePattern string, pagesPerBatch int, extractor urls.URLExtractor, opts ...PageRangeOption) (*PageRangeGenerator, error) {
+	f := &PageRangeGenerator{
+		urlTemplate:         PageURLTemplate(baseURL, pagePattern),
 		pagesPerBatch:       pagesPerBatch,
 		httpClient:          httpclient.NewClient(httpclient.CloudflareClient),
 		emptyContentMarkers: []string{"0 episodes found"}, // Default markers, can be extended
+		startPage:           1,
+		step:                1,
+		contentCheckEvery:   10,
+		existsRetries:       defaultExistenceRetries,
+		existsBackoff:       defaultExistenceBackoff,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, ValidatePageURLTemplate(f.urlTemplate)
+}
+
+// pageNumberVerb is the placeholder replaced with the page number in page URL templates
+const pageNumberVerb = "%d"
+
+// PageURLTemplate combines a base URL and page pattern into a page URL template
+// A pattern that is already an absolute URL is used as is
+func PageURLTemplate(baseURL, pagePattern string) string {
+	if strings.HasPrefix(pagePattern, "http://") || strings.HasPrefix(pagePattern, "https://") {
+		return pagePattern
 	}
+	return baseURL + pagePattern
+}
+
+// ValidatePageURLTemplate checks that a page URL template contains exactly one %d,
+// anywhere in the URL (path or query)
+func ValidatePageURLTemplate(urlTemplate string) error {
+	if n := strings.Count(urlTemplate, pageNumberVerb); n != 1 {
+		return fmt.Errorf("page URL template %q must contain exactly one %s, found %d", urlTemplate, pageNumberVerb, n)
+	}
+	return nil
+}
+
+// SetClientType switches the headers sent with page existence checks to those of clientType
+func (f *PageRangeGenerator) SetClientType(clientType httpclient.ClientType) {
+	f.httpClient = f.httpClient.WithClientType(clientType)
+}
+
+// SetClientOptions replaces the options of the client that checks whether pages exist
+func (f *PageRangeGenerator) SetClientOptions(opts httpclient.ClientOptions) {
+	f.httpClient = f.httpClient.WithOptions(opts)
 }
 
 // Generate generates page URLs from the configured pattern
 // Returns page URLs that should be processed by the next step
 // Stops when a page returns no URLs (indicating end of pagination)
 func (f *PageRangeGenerator) Generate(ctx context.Context) ([]string, error) {
+	if f.initErr != nil {
+		return nil, f.initErr
+	}
+	if f.step < 1 {
+		return nil, fmt.Errorf("page step must be >= 1, got %d", f.step)
+	}
+
 	var allPageURLs []string
-	currentPage := 1
+	currentPage := f.startPage
 
 	for {
 		select {
@@ -169,14 +556,22 @@ func (f *PageRangeGenerator) Generate(ctx context.Context) ([]string, error) {
 		default:
 		}
 
+		if f.maxPages > 0 && len(allPageURLs) >= f.maxPages {
+			log.Printf("PageRangeGenerator: Reached max pages limit (%d), stopping pagination", f.maxPages)
+			break
+		}
+
 		pageURL := f.buildPageURL(currentPage)
-		shouldStop, err := f.shouldStopPagination(ctx, currentPage, pageURL)
+		shouldStop, err := f.shouldStopPagination(ctx, len(allPageURLs)+1, currentPage, pageURL)
+		if err != nil && ctx.Err() != nil {
+			return allPageURLs, ctx.Err()
+		}
 		if err != nil || shouldStop {
 			break
 		}
 
 		allPageURLs = append(allPageURLs, pageURL)
-		currentPage++
+		currentPage += f.step
 	}
 
 	log.Printf("PageRangeGenerator: Generated %d page URLs total", len(allPageURLs))
@@ -184,13 +579,15 @@ func (f *PageRangeGenerator) Generate(ctx context.Context) ([]string, error) {
 }
 
 // buildPageURL builds the URL for a given page number
+// The %d is replaced literally, so other percent signs in the template (e.g., "%20") are kept
 func (f *PageRangeGenerator) buildPageURL(pageNum int) string {
-	return f.baseURL + fmt.Sprintf(f.pagePattern, pageNum)
+	return strings.Replace(f.urlTemplate, pageNumberVerb, strconv.Itoa(pageNum), 1)
 }
 
 // shouldStopPagination checks if pagination should stop by checking if the page exists and has content
-func (f *PageRangeGenerator) shouldStopPagination(ctx context.Context, currentPage int, pageURL string) (bool, error) {
-	exists, err := f.checkPageExists(pageURL)
+// pageCount is the 1-based position of this page among the generated pages
+func (f *PageRangeGenerator) shouldStopPagination(ctx context.Context, pageCount, currentPage int, pageURL string) (bool, error) {
+	exists, err := f.checkPageExists(ctx, pageURL)
 	if err != nil {
 		log.Printf("PageRangeGenerator: Error checking page %d: %v - stopping pagination", currentPage, err)
 		return true, err
@@ -200,8 +597,8 @@ func (f *PageRangeGenerator) shouldStopPagination(ctx context.Context, currentPa
 		return true, nil
 	}
 
-	// Every 10 pages, check content for empty markers
-	if currentPage%10 == 0 {
+	// Every N pages (10 by default), check content for empty markers
+	if f.contentCheckEvery > 0 && pageCount%f.contentCheckEvery == 0 {
 		return f.shouldStopDueToEmptyContent(ctx, currentPage, pageURL)
 	}
 
@@ -209,25 +606,54 @@ func (f *PageRangeGenerator) shouldStopPagination(ctx context.Context, currentPa
 }
 
 // checkPageExists checks if a page exists using a HEAD request
-func (f *PageRangeGenerator) checkPageExists(pageURL string) (bool, error) {
+// 200 means the page exists and other statuses (e.g., 404) that it doesn't; network errors,
+// 5xx and 429 are retried with exponential backoff and returned as errors once retries run out
+func (f *PageRangeGenerator) checkPageExists(ctx context.Context, pageURL string) (bool, error) {
+	delay := f.existsBackoff
+
+	for attempt := 0; ; attempt++ {
+		exists, err := f.headPage(ctx, pageURL)
+		if err == nil {
+			return exists, nil
+		}
+		if attempt >= f.existsRetries || ctx.Err() != nil {
+			return false, err
+		}
+
+		log.Printf("PageRangeGenerator: Checking %s failed (attempt %d), retrying in %s: %v", pageURL, attempt+1, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false, fmt.Errorf("retry cancelled: %w (last error: %v)", ctx.Err(), err)
+		}
+		delay *= 2
+	}
+}
+
+// headPage sends a single HEAD request for pageURL and reports whether the page exists
+// Returns an error for failures worth retrying: network errors, 5xx and 429
+func (f *PageRangeGenerator) headPage(ctx context.Context, pageURL string) (bool, error) {
 	log.Printf("PageRangeGenerator: Checking page: %s", pageURL)
-	resp, err := f.httpClient.Head(pageURL)
+	resp, err := f.httpClient.HeadWithContext(ctx, pageURL)
 	if err != nil {
 		return false, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		log.Printf("PageRangeGenerator: Page exists (status %d)", resp.StatusCode)
+		return true, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	default:
 		return false, nil
 	}
-
-	log.Printf("PageRangeGenerator: Page exists (status %d)", resp.StatusCode)
-	return true, nil
 }
 
 // shouldStopDueToEmptyContent checks if pagination should stop due to empty content markers
 func (f *PageRangeGenerator) shouldStopDueToEmptyContent(ctx context.Context, currentPage int, pageURL string) (bool, error) {
-	log.Printf("PageRangeGenerator: Page %d is a multiple of 10, checking content for empty markers", currentPage)
+	log.Printf("PageRangeGenerator: Checking content of page %d for empty markers", currentPage)
 	hasContent, err := f.checkPageContent(pageURL)
 	if err != nil {
 		log.Printf("PageRangeGenerator: Error checking content for page %d: %v - continuing", currentPage, err)
@@ -253,7 +679,7 @@ func (f *PageRangeGenerator) checkPageContent(pageURL string) (bool, error) {
 		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := f.httpClient.ReadBody(resp)
 	if err != nil {
 		return false, fmt.Errorf("failed to read response body: %w", err)
 	}
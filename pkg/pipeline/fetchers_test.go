@@ -3,9 +3,14 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"blog-search/pkg/urls"
 )
@@ -281,3 +286,423 @@ func TestNewHTMLPageFetcherWithFilters(t *testing.T) {
 		t.Fatalf("Expected 1 filter, got %d", len(fetcher.filters))
 	}
 }
+
+// pageRangeServer returns 200 for the given page numbers under /page/N and 404 otherwise
+func pageRangeServer(t *testing.T, pages ...int) *httptest.Server {
+	t.Helper()
+
+	existing := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		existing[fmt.Sprintf("/page/%d", page)] = true
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if existing[r.URL.Path] {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPageRangeGenerator_Generate_StartAtZero(t *testing.T) {
+	server := pageRangeServer(t, 0, 1, 2)
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithStartPage(0))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := []string{server.URL + "/page/0", server.URL + "/page/1", server.URL + "/page/2"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPageRangeGenerator_Generate_StartAndStep(t *testing.T) {
+	// Odd pages would exist too, but must never be requested with step 2
+	server := pageRangeServer(t, 1, 2, 3, 4, 5, 6)
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithStartPage(2), WithPageStep(2))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := []string{server.URL + "/page/2", server.URL + "/page/4", server.URL + "/page/6"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPageRangeGenerator_Generate_InvalidStep(t *testing.T) {
+	generator := NewPageRangeGenerator("https://example.com", "/page/%d", 10, nil, WithPageStep(0))
+	if _, err := generator.Generate(context.Background()); err == nil {
+		t.Fatal("Expected error for step 0, got nil")
+	}
+}
+
+func TestPageRangeGenerator_Generate_MaxPages(t *testing.T) {
+	// Every page exists, like an infinite-scroll stub
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithMaxPages(5))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(result) != 5 {
+		t.Fatalf("Expected exactly 5 page URLs, got %d", len(result))
+	}
+	if result[4] != server.URL+"/page/5" {
+		t.Errorf("Expected last URL to be page 5, got %s", result[4])
+	}
+	if requests != 5 {
+		t.Errorf("Expected no requests beyond the limit, got %d", requests)
+	}
+}
+
+func TestPageRangeGenerator_Generate_CustomEmptyMarkerStops(t *testing.T) {
+	// Every page exists, but page 3 renders a site-specific "no results" message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/page/3" {
+			fmt.Fprint(w, "<html><body><p>No posts found</p></body></html>")
+			return
+		}
+		fmt.Fprint(w, "<html><body><article>post</article></body></html>")
+	}))
+	defer server.Close()
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil,
+		WithEmptyContentMarkers("No posts found"), WithContentCheckInterval(3), WithMaxPages(10))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := []string{server.URL + "/page/1", server.URL + "/page/2"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPageRangeGenerator_Generate_ContentCheckInterval(t *testing.T) {
+	var contentChecks []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			contentChecks = append(contentChecks, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithContentCheckInterval(4), WithMaxPages(5))
+	if _, err := generator.Generate(context.Background()); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := []string{"/page/4"}
+	if !reflect.DeepEqual(contentChecks, expected) {
+		t.Errorf("Expected content checks %v, got %v", expected, contentChecks)
+	}
+}
+
+func TestPageRangeGenerator_Generate_QueryStringPagination(t *testing.T) {
+	// Pages 1-3 exist; the sort parameter must be kept on every page URL
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if r.URL.Path != "/blog" || r.URL.Query().Get("sort") != "new" || (page != "1" && page != "2" && page != "3") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	generator, err := NewPageRangeGeneratorE(server.URL, "/blog?page=%d&sort=new", 10, nil)
+	if err != nil {
+		t.Fatalf("NewPageRangeGeneratorE failed: %v", err)
+	}
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := []string{
+		server.URL + "/blog?page=1&sort=new",
+		server.URL + "/blog?page=2&sort=new",
+		server.URL + "/blog?page=3&sort=new",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPageRangeGenerator_FullURLTemplate(t *testing.T) {
+	generator, err := NewPageRangeGeneratorE("https://ignored.example.com", "https://example.com/search?q=a%20b&page=%d", 10, nil)
+	if err != nil {
+		t.Fatalf("NewPageRangeGeneratorE failed: %v", err)
+	}
+	if got := generator.buildPageURL(7); got != "https://example.com/search?q=a%20b&page=7" {
+		t.Errorf("Expected the full template with the page number, got %q", got)
+	}
+}
+
+func TestNewPageRangeGeneratorE_InvalidTemplate(t *testing.T) {
+	for _, pattern := range []string{"/page/", "/page/%d?offset=%d"} {
+		if _, err := NewPageRangeGeneratorE("https://example.com", pattern, 10, nil); err == nil {
+			t.Errorf("Expected an error for pattern %q", pattern)
+		}
+
+		// The error-less constructor reports it from Generate instead
+		generator := NewPageRangeGenerator("https://example.com", pattern, 10, nil)
+		if _, err := generator.Generate(context.Background()); err == nil {
+			t.Errorf("Expected Generate to fail for pattern %q", pattern)
+		}
+	}
+}
+
+// sitemapServer serves a sitemap listing n posts, where every third post is under /news/
+func sitemapServer(n int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		for i := 1; i <= n; i++ {
+			section := "blog"
+			if i%3 == 0 {
+				section = "news"
+			}
+			fmt.Fprintf(w, "<url><loc>https://example.com/%s/post-%d</loc></url>", section, i)
+		}
+		fmt.Fprint(w, `</urlset>`)
+	}))
+}
+
+func TestSitemapStreamFetcher_StreamURLs_ChunksAndFilters(t *testing.T) {
+	server := sitemapServer(250)
+	defer server.Close()
+
+	fetcher := NewSitemapStreamFetcher([]urls.UrlFilter{urls.NewContainsPathFilter("/blog/")})
+	fetcher.SetChunkSize(100)
+
+	var chunkSizes []int
+	var streamed []string
+	err := fetcher.StreamURLs(context.Background(), server.URL, func(chunk []string) error {
+		chunkSizes = append(chunkSizes, len(chunk))
+		streamed = append(streamed, chunk...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamURLs failed: %v", err)
+	}
+
+	// 100, 100 and 50 URLs per chunk, of which a third are filtered out
+	if !reflect.DeepEqual(chunkSizes, []int{67, 67, 33}) {
+		t.Errorf("Expected filtered chunks of [67 67 33], got %v", chunkSizes)
+	}
+	for _, u := range streamed {
+		if !strings.Contains(u, "/blog/") {
+			t.Errorf("Expected only blog URLs, got %s", u)
+		}
+	}
+
+	fetched, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !reflect.DeepEqual(fetched, streamed) {
+		t.Errorf("Expected Fetch to return the streamed URLs, got %d URLs", len(fetched))
+	}
+}
+
+func TestSitemapStreamFetcher_StreamURLs_StopsWhenSendFails(t *testing.T) {
+	server := sitemapServer(1000)
+	defer server.Close()
+
+	fetcher := NewSitemapStreamFetcher(nil)
+	fetcher.SetChunkSize(10)
+
+	stop := errors.New("stop")
+	chunks := 0
+	err := fetcher.StreamURLs(context.Background(), server.URL, func(chunk []string) error {
+		chunks++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected send's error, got %v", err)
+	}
+	if chunks != 1 {
+		t.Errorf("Expected streaming to stop after the first chunk, got %d chunks", chunks)
+	}
+}
+
+// flakyPagesServer serves pages 1-3 and 404 after that, counting HEAD requests per path
+// Each path in failures first answers with the given status codes, in order
+func flakyPagesServer(failures map[string][]int) (*httptest.Server, map[string]int) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n := requests[r.URL.Path]
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		if statuses := failures[r.URL.Path]; n < len(statuses) {
+			w.WriteHeader(statuses[n])
+			return
+		}
+		var page int
+		fmt.Sscanf(r.URL.Path, "/page/%d", &page)
+		if page > 3 {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, requests
+}
+
+func TestPageRangeGenerator_Generate_RetriesTransientErrors(t *testing.T) {
+	server, requests := flakyPagesServer(map[string][]int{"/page/2": {http.StatusServiceUnavailable}})
+	defer server.Close()
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithExistenceRetries(3, time.Millisecond))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("Expected pagination to continue past the 503 to 3 pages, got %v", result)
+	}
+	if requests["/page/2"] != 2 {
+		t.Errorf("Expected page 2 to be checked twice, got %d", requests["/page/2"])
+	}
+	if requests["/page/4"] != 1 {
+		t.Errorf("Expected the 404 to end pagination without retries, got %d requests", requests["/page/4"])
+	}
+}
+
+func TestPageRangeGenerator_Generate_PersistentErrorStops(t *testing.T) {
+	server, requests := flakyPagesServer(map[string][]int{
+		"/page/2": {http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+	})
+	defer server.Close()
+
+	generator := NewPageRangeGenerator(server.URL, "/page/%d", 10, nil, WithExistenceRetries(2, time.Millisecond))
+	result, err := generator.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("Expected pagination to stop at the persistently failing page, got %v", result)
+	}
+	if requests["/page/2"] != 3 {
+		t.Errorf("Expected 1 check plus 2 retries of page 2, got %d", requests["/page/2"])
+	}
+}
+
+// followPagesServer serves a category listing of n pages, two articles each; every page but
+// the last links to the next one with the given markup (%s is the next page's path)
+func followPagesServer(n int, nextLink string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page int
+		if _, err := fmt.Sscanf(r.URL.Path, "/category/page/%d", &page); err != nil || page < 1 || page > n {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><article><a href="/posts/%d-a">A</a></article><article><a href="/posts/%d-b">B</a></article>`, page, page)
+		if page < n {
+			fmt.Fprintf(w, nextLink, fmt.Sprintf("/category/page/%d", page+1))
+		}
+		fmt.Fprint(w, `</body></html>`)
+	}))
+}
+
+// followExtractor returns every <article> link of a page, resolved against the page URL
+func followExtractor(html, pageURL string) ([]urls.URL, error) {
+	var found []urls.URL
+	for _, part := range strings.Split(html, `<a href="/posts/`)[1:] {
+		path := part[:strings.Index(part, `"`)]
+		found = append(found, urls.URL{Location: strings.SplitN(pageURL, "/category/", 2)[0] + "/posts/" + path})
+	}
+	return found, nil
+}
+
+func TestFollowPagesFetcher_StreamURLs_FollowsUntilLastPage(t *testing.T) {
+	server := followPagesServer(3, `<div class="nav"><span class="next"><a href="%s">Older posts</a></span></div>`)
+	defer server.Close()
+
+	fetcher := NewFollowPagesFetcher(followExtractor, []urls.UrlFilter{urls.NewContainsPathFilter("-a")})
+
+	var pages [][]string
+	err := fetcher.StreamURLs(context.Background(), server.URL+"/category/page/1", func(chunk []string) error {
+		pages = append(pages, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamURLs failed: %v", err)
+	}
+
+	want := [][]string{
+		{server.URL + "/posts/1-a"},
+		{server.URL + "/posts/2-a"},
+		{server.URL + "/posts/3-a"},
+	}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("Expected one filtered chunk per page %v, got %v", want, pages)
+	}
+}
+
+func TestFollowPagesFetcher_Fetch_StopsAtMaxPagesAndLoops(t *testing.T) {
+	server := followPagesServer(10, `<link rel="next" href="%s">`)
+	defer server.Close()
+
+	fetcher := NewFollowPagesFetcher(followExtractor, nil)
+	fetcher.SetMaxPages(2)
+	got, err := fetcher.Fetch(context.Background(), server.URL+"/category/page/1")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("Expected the articles of 2 pages, got %v", got)
+	}
+
+	// A listing whose last page links back to the first is not followed forever
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := "/category/page/2"
+		if r.URL.Path == "/category/page/2" {
+			next = "/category/page/1"
+		}
+		fmt.Fprintf(w, `<html><body><a href="/posts%s">Post</a><a rel="next" href="%s">Next</a></body></html>`, r.URL.Path, next)
+	}))
+	defer loop.Close()
+
+	got, err = NewFollowPagesFetcher(followExtractor, nil).Fetch(context.Background(), loop.URL+"/category/page/1")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected each page's article once, got %v", got)
+	}
+}
+
+func TestFollowPagesFetcher_StreamURLs_FirstPageError(t *testing.T) {
+	server := followPagesServer(1, "")
+	defer server.Close()
+
+	fetcher := NewFollowPagesFetcher(followExtractor, nil)
+	err := fetcher.StreamURLs(context.Background(), server.URL+"/missing", func(chunk []string) error {
+		t.Errorf("Expected nothing to be sent, got %v", chunk)
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected an error for an index page that fails to load")
+	}
+}
@@ -2,11 +2,20 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"blog-search/pkg/clock"
+	"blog-search/pkg/db"
 	"blog-search/pkg/domain"
+	"blog-search/pkg/httpclient"
+	"blog-search/pkg/logging"
+	"blog-search/pkg/metrics"
+	"blog-search/pkg/progress"
+	"blog-search/pkg/urls"
 )
 
 // URLGenerator generates initial URLs (used for the first step only)
@@ -27,6 +36,15 @@ type URLFetcher interface {
 	Fetch(ctx context.Context, url string) ([]string, error)
 }
 
+// URLStreamer is implemented by fetchers that can hand on URLs in chunks while they are still
+// being fetched (e.g., huge sitemaps). A first step whose Fetcher implements it is streamed
+// instead of fetched all at once
+type URLStreamer interface {
+	// StreamURLs calls send with each chunk of URLs extracted from url, after the fetcher's own filters
+	// Stops with send's error if it returns one
+	StreamURLs(ctx context.Context, url string, send func(urls []string) error) error
+}
+
 // ContentProcessor processes a URL and returns an Article
 // Handles fetching HTML, extracting content, and creating the article
 type ContentProcessor interface {
@@ -48,6 +66,9 @@ type PipelineStep struct {
 	WorkerCount int
 	Generator   URLGenerator // Used for first step (optional)
 	Fetcher     URLFetcher   // Used for all steps (required if Generator is nil)
+
+	// Filters are applied to the URLs this step produces before they are forwarded
+	Filters []urls.UrlFilter
 }
 
 // ContentConsumer is the final step that fetches content and saves to storage
@@ -55,19 +76,331 @@ type ContentConsumer struct {
 	WorkerCount      int
 	ContentProcessor ContentProcessor // Processor to fetch and extract content
 	ContentSaver     ContentSaver     // Saver to persist articles
+
+	// MaxConsecutiveSaveFailures stops the whole pipeline after this many saves fail in a row
+	// (e.g., the database went away). Zero disables the check.
+	MaxConsecutiveSaveFailures int
+
+	// MaxArticles stops the pipeline once this many articles were saved. Zero means no limit.
+	MaxArticles int
+
+	// OnProgress, if set, is called every ProgressInterval (default 1s) while counts change,
+	// and once more when the run ends. Calls come from a single goroutine.
+	OnProgress       func(progress.Progress)
+	ProgressInterval time.Duration
+}
+
+// RequestLimited is implemented by steps and processors that make outbound HTTP requests
+// The pipeline hands them a shared semaphore to cap in-flight requests across all workers
+type RequestLimited interface {
+	SetRequestSemaphore(sem chan struct{})
+}
+
+// FeedItemRecorder is implemented by steps and processors that record or use feed item data
+// The pipeline hands them its FeedItemIndex so data found during discovery (e.g., feed descriptions
+// or full post content) reaches the content consumer
+type FeedItemRecorder interface {
+	SetFeedItemIndex(index *FeedItemIndex)
+}
+
+// FeedItem holds what a feed said about a URL
+type FeedItem struct {
+	Title   string
+	Summary string // Plain-text description
+	Content string // Full post HTML (e.g., RSS <content:encoded>)
+}
+
+// FeedItemIndex maps URLs to the feed items found while discovering them; safe for concurrent use
+type FeedItemIndex struct {
+	mu    sync.RWMutex
+	items map[string]FeedItem
+}
+
+// NewFeedItemIndex creates an empty feed item index
+func NewFeedItemIndex() *FeedItemIndex {
+	return &FeedItemIndex{items: make(map[string]FeedItem)}
+}
+
+// Set records the feed item for url; items without a summary or content and a nil index are ignored
+func (s *FeedItemIndex) Set(url string, item FeedItem) {
+	if s == nil || (item.Summary == "" && item.Content == "") {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[url] = item
+}
+
+// Get returns the feed item recorded for url and whether there is one
+func (s *FeedItemIndex) Get(url string) (FeedItem, bool) {
+	if s == nil {
+		return FeedItem{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[url]
+	return item, ok
+}
+
+// RawHTMLKeeper is implemented by processors that can keep the fetched HTML in Article.RawHTML
+type RawHTMLKeeper interface {
+	SetKeepRawHTML(keep bool)
+}
+
+// HeadPrechecker is implemented by processors that can check URLs with a HEAD request before fetching them
+type HeadPrechecker interface {
+	SetHeadPrecheck(enabled bool)
+}
+
+// MinTextLengthSetter is implemented by processors that can reject pages with too little extracted text
+type MinTextLengthSetter interface {
+	SetMinTextLength(n int)
+}
+
+// SoftNotFoundDetector is implemented by processors that can recognize error pages served with HTTP 200
+type SoftNotFoundDetector interface {
+	SetSoftNotFoundMarkers(markers []string)
+}
+
+// CanonicalPreferrer is implemented by processors that can store articles under the page's canonical URL
+type CanonicalPreferrer interface {
+	SetPreferCanonical(prefer bool)
+}
+
+// ConditionalFetcher is implemented by processors that can send conditional GETs for stored articles
+type ConditionalFetcher interface {
+	SetArticleLookup(lookup ArticleLookup)
+}
+
+// ClientTypeSetter is implemented by components that can switch the header preset of their HTTP client
+// (e.g., to httpclient.BrowserClient for sites that answer 406 to curl-like requests)
+type ClientTypeSetter interface {
+	SetClientType(clientType httpclient.ClientType)
+}
+
+// ClientOptionsSetter is implemented by components that can replace the options of their HTTP client
+// (e.g., to send a login cookie with every request to the crawled site)
+type ClientOptionsSetter interface {
+	SetClientOptions(opts httpclient.ClientOptions)
+}
+
+// ClockSetter is implemented by processors whose article timestamps can come from a given clock
+type ClockSetter interface {
+	SetClock(c clock.Clock)
 }
 
 // Pipeline orchestrates multiple steps and a final content consumer
 type Pipeline struct {
 	steps           []PipelineStep
 	contentConsumer ContentConsumer
+	requestSem      chan struct{}
+	feedItems       *FeedItemIndex
+	failures        db.FailedURLRecorder // Receives content URLs that failed to process (optional)
+	seen            *urls.SeenFilter     // Skips content URLs attempted by recent runs (optional)
+}
+
+// PipelineOption configures optional pipeline behavior
+type PipelineOption func(*Pipeline)
+
+// WithMaxInFlightRequests caps the total number of concurrent outbound requests across
+// all steps and content workers, to protect the target site
+func WithMaxInFlightRequests(n int) PipelineOption {
+	return func(p *Pipeline) {
+		if n > 0 {
+			p.requestSem = make(chan struct{}, n)
+		}
+	}
 }
 
 // NewPipeline creates a new pipeline with the given steps and content consumer
-func NewPipeline(steps []PipelineStep, consumer ContentConsumer) *Pipeline {
-	return &Pipeline{
+func NewPipeline(steps []PipelineStep, consumer ContentConsumer, opts ...PipelineOption) *Pipeline {
+	p := &Pipeline{
 		steps:           steps,
 		contentConsumer: consumer,
+		feedItems:       NewFeedItemIndex(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.requestSem != nil {
+		p.shareRequestSemaphore()
+	}
+	p.shareFeedItemIndex()
+	return p
+}
+
+// SetMaxArticles stops the pipeline once n articles were saved; zero or negative means no limit
+func (p *Pipeline) SetMaxArticles(n int) {
+	p.contentConsumer.MaxArticles = n
+}
+
+// SetKeepRawHTML asks the content processor to store fetched HTML with each article
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetKeepRawHTML(keep bool) bool {
+	keeper, ok := p.contentConsumer.ContentProcessor.(RawHTMLKeeper)
+	if ok {
+		keeper.SetKeepRawHTML(keep)
+	}
+	return ok
+}
+
+// SetHeadPrecheck makes the content processor check each URL with a HEAD request first and
+// skip non-HTML and oversized resources
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetHeadPrecheck(enabled bool) bool {
+	prechecker, ok := p.contentConsumer.ContentProcessor.(HeadPrechecker)
+	if ok {
+		prechecker.SetHeadPrecheck(enabled)
+	}
+	return ok
+}
+
+// SetMinTextLength makes the content processor skip pages whose extracted text has fewer than
+// n characters instead of saving them; zero disables the check
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetMinTextLength(n int) bool {
+	setter, ok := p.contentConsumer.ContentProcessor.(MinTextLengthSetter)
+	if ok {
+		setter.SetMinTextLength(n)
+	}
+	return ok
+}
+
+// SetSoftNotFoundMarkers replaces the texts the content processor uses to recognize error pages
+// served with HTTP 200 (soft 404s), which are skipped instead of saved
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetSoftNotFoundMarkers(markers []string) bool {
+	detector, ok := p.contentConsumer.ContentProcessor.(SoftNotFoundDetector)
+	if ok {
+		detector.SetSoftNotFoundMarkers(markers)
+	}
+	return ok
+}
+
+// SetPreferCanonical makes the content processor store articles under the page's same-host
+// <link rel="canonical"> URL instead of the URL they were fetched from
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetPreferCanonical(prefer bool) bool {
+	preferrer, ok := p.contentConsumer.ContentProcessor.(CanonicalPreferrer)
+	if ok {
+		preferrer.SetPreferCanonical(prefer)
+	}
+	return ok
+}
+
+// SetConditionalFetch makes the content processor re-crawl stored articles with conditional GETs,
+// skipping pages the server reports as unchanged (304 Not Modified)
+// Returns false if the processor doesn't support it
+func (p *Pipeline) SetConditionalFetch(lookup ArticleLookup) bool {
+	fetcher, ok := p.contentConsumer.ContentProcessor.(ConditionalFetcher)
+	if ok {
+		fetcher.SetArticleLookup(lookup)
+	}
+	return ok
+}
+
+// SetClientType makes the content processor and the steps' fetchers send the headers of clientType
+// Returns false if the content processor doesn't support it
+func (p *Pipeline) SetClientType(clientType httpclient.ClientType) bool {
+	for _, step := range p.steps {
+		for _, c := range []interface{}{step.Generator, step.Fetcher} {
+			if setter, ok := c.(ClientTypeSetter); ok {
+				setter.SetClientType(clientType)
+			}
+		}
+	}
+
+	setter, ok := p.contentConsumer.ContentProcessor.(ClientTypeSetter)
+	if ok {
+		setter.SetClientType(clientType)
+	}
+	return ok
+}
+
+// SetClientOptions makes the content processor and the steps' fetchers use HTTP clients with opts,
+// keeping their client types; set opts.ExtraHeadersHost to keep opts.ExtraHeaders on the crawled site
+// Returns false if the content processor doesn't support it
+func (p *Pipeline) SetClientOptions(opts httpclient.ClientOptions) bool {
+	for _, step := range p.steps {
+		for _, c := range []interface{}{step.Generator, step.Fetcher} {
+			if setter, ok := c.(ClientOptionsSetter); ok {
+				setter.SetClientOptions(opts)
+			}
+		}
+	}
+
+	setter, ok := p.contentConsumer.ContentProcessor.(ClientOptionsSetter)
+	if ok {
+		setter.SetClientOptions(opts)
+	}
+	return ok
+}
+
+// SetFailedURLRecorder records each content URL that fails to fetch or extract in recorder,
+// so it can be retried later (see the retry-failed command)
+func (p *Pipeline) SetFailedURLRecorder(recorder db.FailedURLRecorder) {
+	p.failures = recorder
+}
+
+// SetSeenFilter makes the content consumer skip URLs that seen reports as attempted within its TTL
+// A URL is marked seen once its attempt has a final outcome: it was saved, found unchanged, skipped
+// as a non-article, or failed permanently (e.g., a 404); transient failures are tried again next run
+// The marks are flushed at the end of each run
+func (p *Pipeline) SetSeenFilter(seen *urls.SeenFilter) {
+	p.seen = seen
+}
+
+// SetContentDedup makes the content consumer skip articles whose text is already stored under
+// another URL (e.g., the same post reached through an AMP or a mirror URL); see HashDedupContentSaver
+func (p *Pipeline) SetContentDedup(checker ContentHashChecker) {
+	p.contentConsumer.ContentSaver = NewHashDedupContentSaver(p.contentConsumer.ContentSaver, checker)
+}
+
+// shareRequestSemaphore hands the request semaphore to every component that makes requests
+func (p *Pipeline) shareRequestSemaphore() {
+	components := []interface{}{p.contentConsumer.ContentProcessor}
+	for _, step := range p.steps {
+		components = append(components, step.Generator, step.Fetcher)
+	}
+
+	for _, c := range components {
+		if limited, ok := c.(RequestLimited); ok {
+			limited.SetRequestSemaphore(p.requestSem)
+		}
+	}
+}
+
+// shareFeedItemIndex hands the feed item index to every component that records or uses feed items
+func (p *Pipeline) shareFeedItemIndex() {
+	components := []interface{}{p.contentConsumer.ContentProcessor}
+	for _, step := range p.steps {
+		components = append(components, step.Generator, step.Fetcher)
+	}
+
+	for _, c := range components {
+		if recorder, ok := c.(FeedItemRecorder); ok {
+			recorder.SetFeedItemIndex(p.feedItems)
+		}
+	}
+}
+
+// acquireRequestSlot blocks until a request slot is free; a nil semaphore means no limit
+func acquireRequestSlot(ctx context.Context, sem chan struct{}) error {
+	if sem == nil {
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseRequestSlot frees a slot taken by acquireRequestSlot
+func releaseRequestSlot(sem chan struct{}) {
+	if sem != nil {
+		<-sem
 	}
 }
 
@@ -76,18 +409,82 @@ func NewPipeline(steps []PipelineStep, consumer ContentConsumer) *Pipeline {
 // 2. Each subsequent step extracts URLs and passes them to the next step
 // 3. Final step passes URLs to content consumer
 // 4. Content consumer fetches content and saves to database
+//
+// Per-URL failures are logged and counted but don't fail the run. Run returns an error
+// when the first step can't produce URLs or when every content URL failed.
 func (p *Pipeline) Run(ctx context.Context, baseURL string) error {
+	_, err := p.Run2(ctx, baseURL)
+	return err
+}
+
+// Run2 executes the pipeline like Run and also returns the counts collected during the run
+func (p *Pipeline) Run2(ctx context.Context, baseURL string) (PipelineStats, error) {
 	if len(p.steps) == 0 {
-		return fmt.Errorf("pipeline has no steps")
+		return PipelineStats{}, fmt.Errorf("pipeline has no steps")
 	}
 
+	// Internal context so a fatal content error can stop every step
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	channels, contentChan := p.createChannels()
 	var wg sync.WaitGroup
+	state := newRunState(len(p.steps))
+	state.cancel = cancel
+	state.maxConsecutiveSaveFailures = p.contentConsumer.MaxConsecutiveSaveFailures
+	state.maxArticles = p.contentConsumer.MaxArticles
+	state.progress = progress.NewReporter(p.contentConsumer.OnProgress, p.contentConsumer.ProgressInterval)
+	state.errorLog = logging.NewErrorAggregator("Pipeline", logging.DefaultRollupInterval)
 
-	p.startAllWorkers(ctx, baseURL, channels, contentChan, &wg)
+	state.progress.Start()
+	state.errorLog.Start()
+	p.startAllWorkers(ctx, baseURL, channels, contentChan, &wg, state)
 	wg.Wait()
+	p.flushSeen(ctx)
+	state.errorLog.Stop()
+	state.progress.Stop()
 
-	return nil
+	return state.stats(), state.err()
+}
+
+// Discover runs only the URL-discovery steps and returns the URLs that would reach the content
+// consumer, deduplicated in arrival order. No content is fetched and nothing is saved, so the
+// consumer's processor and saver are never used.
+func (p *Pipeline) Discover(ctx context.Context, baseURL string) ([]string, error) {
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("pipeline has no steps")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	channels, contentChan := p.createChannels()
+	var wg sync.WaitGroup
+	state := newRunState(len(p.steps))
+	state.cancel = cancel
+	state.errorLog = logging.NewErrorAggregator("Pipeline", logging.DefaultRollupInterval)
+	state.errorLog.Start()
+	defer state.errorLog.Stop()
+
+	var discovered []string
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		seen := make(map[string]bool)
+		for url := range contentChan {
+			if !seen[url] {
+				seen[url] = true
+				discovered = append(discovered, url)
+			}
+		}
+	}()
+
+	p.startSubsequentStepWorkers(ctx, channels, contentChan, &wg, state)
+	p.startFirstStepWorker(ctx, baseURL, channels, contentChan, &wg, state)
+	wg.Wait()
+	<-collected
+
+	return discovered, state.err()
 }
 
 // createChannels creates channels for communication between pipeline steps
@@ -106,18 +503,18 @@ func (p *Pipeline) createChannels() ([]chan string, chan string) {
 }
 
 // startAllWorkers starts all workers in the pipeline
-func (p *Pipeline) startAllWorkers(ctx context.Context, baseURL string, channels []chan string, contentChan chan string, wg *sync.WaitGroup) {
-	p.startContentConsumer(ctx, contentChan, wg)
-	p.startSubsequentStepWorkers(ctx, channels, contentChan, wg)
-	p.startFirstStepWorker(ctx, baseURL, channels, contentChan, wg)
+func (p *Pipeline) startAllWorkers(ctx context.Context, baseURL string, channels []chan string, contentChan chan string, wg *sync.WaitGroup, state *runState) {
+	p.startContentConsumer(ctx, contentChan, wg, state)
+	p.startSubsequentStepWorkers(ctx, channels, contentChan, wg, state)
+	p.startFirstStepWorker(ctx, baseURL, channels, contentChan, wg, state)
 }
 
 // startSubsequentStepWorkers starts workers for all steps after the first
-func (p *Pipeline) startSubsequentStepWorkers(ctx context.Context, channels []chan string, contentChan chan string, wg *sync.WaitGroup) {
+func (p *Pipeline) startSubsequentStepWorkers(ctx context.Context, channels []chan string, contentChan chan string, wg *sync.WaitGroup, state *runState) {
 	for i := 1; i < len(p.steps); i++ {
 		inputChan := channels[i-1]
 		outputChan := p.getOutputChannelForStep(i, channels, contentChan)
-		p.startStepWorkers(ctx, p.steps[i], inputChan, outputChan, wg)
+		p.startStepWorkers(ctx, i, p.steps[i], inputChan, outputChan, wg, state)
 	}
 }
 
@@ -130,36 +527,86 @@ func (p *Pipeline) getOutputChannelForStep(stepIndex int, channels []chan string
 }
 
 // startFirstStepWorker starts the first step worker
-func (p *Pipeline) startFirstStepWorker(ctx context.Context, baseURL string, channels []chan string, contentChan chan string, wg *sync.WaitGroup) {
+func (p *Pipeline) startFirstStepWorker(ctx context.Context, baseURL string, channels []chan string, contentChan chan string, wg *sync.WaitGroup, state *runState) {
 	firstStepOutput := channels[0]
 	if len(p.steps) == 1 {
 		firstStepOutput = contentChan
 	}
-	p.startFirstStep(ctx, p.steps[0], baseURL, firstStepOutput, wg)
+	p.startFirstStep(ctx, p.steps[0], baseURL, firstStepOutput, wg, state)
 }
 
 // startFirstStep starts the first step (can use Generator or Fetcher with baseURL)
-func (p *Pipeline) startFirstStep(ctx context.Context, step PipelineStep, baseURL string, outputChan chan<- string, wg *sync.WaitGroup) {
+func (p *Pipeline) startFirstStep(ctx context.Context, step PipelineStep, baseURL string, outputChan chan<- string, wg *sync.WaitGroup, state *runState) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		defer close(outputChan)
 
+		if streamer, ok := step.Fetcher.(URLStreamer); ok && step.Generator == nil {
+			p.streamFirstStep(ctx, step, streamer, baseURL, outputChan, state)
+			return
+		}
+
 		urls, err := p.generateOrFetchURLs(ctx, step, baseURL)
+		if err == nil {
+			urls, err = filterURLs(ctx, step.Filters, urls)
+		}
 		if err != nil {
+			state.addFatal(fmt.Errorf("first step %s: %w", step.Name, err))
 			return
 		}
+		if step.Generator == nil {
+			state.progress.AddPages(1) // The base URL itself (sitemap, feed, listing page)
+		}
+		state.addStepURLs(0, len(urls))
 
 		p.sendURLsToChannel(ctx, urls, outputChan, "First step")
 	}()
 }
 
+// streamFirstStep sends the first step's URLs to outputChan chunk by chunk as the fetcher
+// produces them, so content workers start before the whole base URL has been read
+// A failure before any URL was sent is fatal; a later one only ends the stream early
+func (p *Pipeline) streamFirstStep(ctx context.Context, step PipelineStep, streamer URLStreamer, baseURL string, outputChan chan<- string, state *runState) {
+	sent := 0
+	err := streamer.StreamURLs(ctx, baseURL, func(chunk []string) error {
+		chunk, err := filterURLs(ctx, step.Filters, chunk)
+		if err != nil {
+			return err
+		}
+		state.addStepURLs(0, len(chunk))
+		for _, url := range chunk {
+			select {
+			case outputChan <- url:
+				sent++
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		state.progress.AddPages(1) // The base URL itself
+		logging.Infof("First step: Streamed %d URLs, all sent", sent)
+	case ctx.Err() != nil:
+		logging.Debugf("First step: Context cancelled after streaming %d URLs", sent)
+	case sent == 0:
+		logging.Errorf("First step (Streamer): Error streaming URLs from %s: %v", baseURL, err)
+		state.addFatal(fmt.Errorf("first step %s: %w", step.Name, err))
+	default:
+		logging.Warnf("First step (Streamer): Stream from %s ended early after %d URLs: %v", baseURL, sent, err)
+		state.addStepError()
+	}
+}
+
 // generateOrFetchURLs generates or fetches URLs for the first step
 func (p *Pipeline) generateOrFetchURLs(ctx context.Context, step PipelineStep, baseURL string) ([]string, error) {
 	if step.Generator != nil {
 		urls, err := step.Generator.Generate(ctx)
 		if err != nil {
-			log.Printf("First step (Generator): Error generating URLs: %v", err)
+			logging.Errorf("First step (Generator): Error generating URLs: %v", err)
 			return nil, err
 		}
 		return urls, nil
@@ -168,37 +615,37 @@ func (p *Pipeline) generateOrFetchURLs(ctx context.Context, step PipelineStep, b
 	if step.Fetcher != nil {
 		urls, err := step.Fetcher.Fetch(ctx, baseURL)
 		if err != nil {
-			log.Printf("First step (Fetcher): Error fetching URLs from %s: %v", baseURL, err)
+			logging.Errorf("First step (Fetcher): Error fetching URLs from %s: %v", baseURL, err)
 			return nil, err
 		}
 		return urls, nil
 	}
 
-	log.Printf("First step: Neither Generator nor Fetcher is set")
+	logging.Errorf("First step: Neither Generator nor Fetcher is set")
 	return nil, fmt.Errorf("neither generator nor fetcher is set")
 }
 
 // sendURLsToChannel sends URLs to the output channel with logging
 func (p *Pipeline) sendURLsToChannel(ctx context.Context, urls []string, outputChan chan<- string, stepName string) {
-	log.Printf("%s: Sending %d URLs to next step", stepName, len(urls))
+	logging.Infof("%s: Sending %d URLs to next step", stepName, len(urls))
 	for i, url := range urls {
 		select {
 		case outputChan <- url:
 			if i < 5 || i == len(urls)-1 {
-				log.Printf("%s: Sent URL %d/%d: %s", stepName, i+1, len(urls), url)
+				logging.Debugf("%s: Sent URL %d/%d: %s", stepName, i+1, len(urls), url)
 			}
 		case <-ctx.Done():
-			log.Printf("%s: Context cancelled", stepName)
+			logging.Debugf("%s: Context cancelled", stepName)
 			return
 		}
 	}
-	log.Printf("%s: Generated/fetched %d URLs, all sent", stepName, len(urls))
+	logging.Infof("%s: Generated/fetched %d URLs, all sent", stepName, len(urls))
 }
 
 // startStepWorkers starts workers for a pipeline step (subsequent steps)
-func (p *Pipeline) startStepWorkers(ctx context.Context, step PipelineStep, inputChan <-chan string, outputChan chan<- string, wg *sync.WaitGroup) {
+func (p *Pipeline) startStepWorkers(ctx context.Context, stepIndex int, step PipelineStep, inputChan <-chan string, outputChan chan<- string, wg *sync.WaitGroup, state *runState) {
 	if step.Fetcher == nil {
-		log.Printf("Step %s: Fetcher is not set", step.Name)
+		logging.Errorf("Step %s: Fetcher is not set", step.Name)
 		return
 	}
 
@@ -207,14 +654,14 @@ func (p *Pipeline) startStepWorkers(ctx context.Context, step PipelineStep, inpu
 	for i := 0; i < step.WorkerCount; i++ {
 		stepWg.Add(1)
 		wg.Add(1)
-		go p.startStepWorker(ctx, step, i, inputChan, outputChan, &stepWg, wg)
+		go p.startStepWorker(ctx, stepIndex, step, i, inputChan, outputChan, &stepWg, wg, state)
 	}
 
 	go p.closeChannelWhenDone(&stepWg, outputChan)
 }
 
 // startStepWorker starts a single worker for a pipeline step
-func (p *Pipeline) startStepWorker(ctx context.Context, step PipelineStep, workerID int, inputChan <-chan string, outputChan chan<- string, stepWg, wg *sync.WaitGroup) {
+func (p *Pipeline) startStepWorker(ctx context.Context, stepIndex int, step PipelineStep, workerID int, inputChan <-chan string, outputChan chan<- string, stepWg, wg *sync.WaitGroup, state *runState) {
 	defer stepWg.Done()
 	defer wg.Done()
 
@@ -224,26 +671,44 @@ func (p *Pipeline) startStepWorker(ctx context.Context, step PipelineStep, worke
 			if !ok {
 				return
 			}
-			p.processURLInStep(ctx, step, workerID, url, outputChan)
+			extracted, err := p.processURLInStep(ctx, step, workerID, url, outputChan, state.errorLog)
+			if err != nil {
+				state.addStepError()
+			} else {
+				state.progress.AddPages(1)
+			}
+			state.addStepURLs(stepIndex, extracted)
 
 		case <-ctx.Done():
-			log.Printf("Step %s (worker %d): Context cancelled", step.Name, workerID)
+			logging.Debugf("Step %s (worker %d): Context cancelled", step.Name, workerID)
 			return
 		}
 	}
 }
 
 // processURLInStep processes a URL in a pipeline step: fetches URLs and sends them to output
-func (p *Pipeline) processURLInStep(ctx context.Context, step PipelineStep, workerID int, url string, outputChan chan<- string) {
-	log.Printf("Step %s (worker %d): Fetching URLs from %s", step.Name, workerID, url)
+// and returns how many URLs were extracted; errors are logged through errorLog
+func (p *Pipeline) processURLInStep(ctx context.Context, step PipelineStep, workerID int, url string, outputChan chan<- string, errorLog *logging.ErrorAggregator) (int, error) {
+	logging.Debugf("Step %s (worker %d): Fetching URLs from %s", step.Name, workerID, url)
 	extractedURLs, err := step.Fetcher.Fetch(ctx, url)
 	if err != nil {
-		log.Printf("Step %s (worker %d): Error fetching URLs from %s: %v", step.Name, workerID, url, err)
-		return
+		errorLog.Warnf(err, "Step %s (worker %d): Error fetching URLs from %s: %v", step.Name, workerID, url, err)
+		return 0, err
+	}
+
+	logging.Debugf("Step %s (worker %d): Extracted %d URLs from %s", step.Name, workerID, len(extractedURLs), url)
+
+	if len(step.Filters) > 0 {
+		extractedURLs, err = filterURLs(ctx, step.Filters, extractedURLs)
+		if err != nil {
+			errorLog.Warnf(err, "Step %s (worker %d): Error filtering URLs from %s: %v", step.Name, workerID, url, err)
+			return 0, err
+		}
+		logging.Debugf("Step %s (worker %d): %d URLs left after step filters", step.Name, workerID, len(extractedURLs))
 	}
 
-	log.Printf("Step %s (worker %d): Extracted %d URLs from %s", step.Name, workerID, len(extractedURLs), url)
 	p.sendExtractedURLs(ctx, step, workerID, extractedURLs, outputChan)
+	return len(extractedURLs), nil
 }
 
 // sendExtractedURLs sends extracted URLs to the output channel
@@ -252,10 +717,10 @@ func (p *Pipeline) sendExtractedURLs(ctx context.Context, step PipelineStep, wor
 		select {
 		case outputChan <- extractedURL:
 			if i < 3 || i == len(extractedURLs)-1 {
-				log.Printf("Step %s (worker %d): Sent URL %d/%d: %s", step.Name, workerID, i+1, len(extractedURLs), extractedURL)
+				logging.Debugf("Step %s (worker %d): Sent URL %d/%d: %s", step.Name, workerID, i+1, len(extractedURLs), extractedURL)
 			}
 		case <-ctx.Done():
-			log.Printf("Step %s (worker %d): Context cancelled", step.Name, workerID)
+			logging.Debugf("Step %s (worker %d): Context cancelled", step.Name, workerID)
 			return
 		}
 	}
@@ -268,7 +733,7 @@ func (p *Pipeline) closeChannelWhenDone(stepWg *sync.WaitGroup, outputChan chan<
 }
 
 // startContentConsumer starts the content consumer workers
-func (p *Pipeline) startContentConsumer(ctx context.Context, inputChan <-chan string, wg *sync.WaitGroup) {
+func (p *Pipeline) startContentConsumer(ctx context.Context, inputChan <-chan string, wg *sync.WaitGroup, state *runState) {
 	for i := 0; i < p.contentConsumer.WorkerCount; i++ {
 		wg.Add(1)
 		go func(workerID int) {
@@ -282,16 +747,43 @@ func (p *Pipeline) startContentConsumer(ctx context.Context, inputChan <-chan st
 						return
 					}
 
+					if p.seenRecently(ctx, url) {
+						// Not a failure: a recent run already attempted the URL
+						state.contentSkipped.Add(1)
+						logging.Debugf("Content worker %d: Attempted by a recent run, skipping URL: %s", workerID, url)
+						continue
+					}
+
 					// Process this URL: fetch content and save to database
-					log.Printf("Content worker %d: Starting to process URL: %s", workerID, url)
-					if err := p.processContentURL(ctx, url); err != nil {
-						log.Printf("Content worker %d: ERROR processing URL %s: %v", workerID, url, err)
+					logging.Debugf("Content worker %d: Starting to process URL: %s", workerID, url)
+					err := p.processContentURL(ctx, url, state)
+					if errors.Is(err, errMaxArticlesReached) {
+						// Not a failure: the run is stopping because it saved enough articles
+						logging.Debugf("Content worker %d: Article limit reached, skipping URL: %s", workerID, url)
+						continue
+					}
+					if errors.Is(err, ErrNotModified) {
+						// Not a failure: the stored article is up to date
+						state.contentUnchanged.Add(1)
+						logging.Debugf("Content worker %d: Not modified since last crawl, skipping URL: %s", workerID, url)
+						continue
+					}
+					if isSkippedPage(err) {
+						// Not a failure: the URL isn't an article page (e.g., an image that slipped past
+						// the filters, a tag listing with too little text, or a "Page not found" page)
+						state.contentSkipped.Add(1)
+						logging.Debugf("Content worker %d: Skipping URL %s: %v", workerID, url, err)
+						continue
+					}
+					state.addContentResult(url, err)
+					if err != nil {
+						state.errorLog.Warnf(err, "Content worker %d: ERROR processing URL %s: %v", workerID, url, err)
 					} else {
-						log.Printf("Content worker %d: SUCCESS - Processed and saved URL: %s", workerID, url)
+						logging.Debugf("Content worker %d: SUCCESS - Processed and saved URL: %s", workerID, url)
 					}
 
 				case <-ctx.Done():
-					log.Printf("Content worker %d: Context cancelled", workerID)
+					logging.Debugf("Content worker %d: Context cancelled", workerID)
 					return
 				}
 			}
@@ -299,8 +791,15 @@ func (p *Pipeline) startContentConsumer(ctx context.Context, inputChan <-chan st
 	}
 }
 
+// isSkippedPage reports whether a content processor or saver error means the URL isn't an article
+// page or duplicates a stored one, which is counted as skipped rather than failed
+func isSkippedPage(err error) bool {
+	return errors.Is(err, ErrSkippedResource) || errors.Is(err, ErrContentTooShort) || errors.Is(err, ErrSoftNotFound) ||
+		errors.Is(err, ErrPDFTooLarge) || errors.Is(err, ErrDuplicateContent)
+}
+
 // processContentURL processes a URL using the content processor and saves it using the content saver
-func (p *Pipeline) processContentURL(ctx context.Context, url string) error {
+func (p *Pipeline) processContentURL(ctx context.Context, url string, state *runState) error {
 	if p.contentConsumer.ContentProcessor == nil {
 		return fmt.Errorf("content processor is not set")
 	}
@@ -309,22 +808,288 @@ func (p *Pipeline) processContentURL(ctx context.Context, url string) error {
 	}
 
 	// Process content (fetch, extract, create article)
-	log.Printf("processContentURL: Fetching and extracting content from %s", url)
+	logging.Debugf("processContentURL: Fetching and extracting content from %s", url)
+	if state.maxArticlesReached() {
+		return errMaxArticlesReached
+	}
 	article, err := p.contentConsumer.ContentProcessor.ProcessContent(ctx, url)
 	if err != nil {
-		log.Printf("processContentURL: ERROR processing content from %s: %v", url, err)
+		if state.maxArticlesReached() {
+			// The fetch was most likely aborted because the limit cancelled the run
+			return errMaxArticlesReached
+		}
+		if errors.Is(err, ErrNotModified) || isSkippedPage(err) {
+			p.markSeen(ctx, url)
+			return err
+		}
+		if !isTransient(err) && ctx.Err() == nil {
+			p.markSeen(ctx, url)
+		}
+		logging.Debugf("processContentURL: ERROR processing content from %s: %v", url, err)
+		metrics.FetchErrors.Inc()
+		p.recordFailure(ctx, url, err)
 		return fmt.Errorf("failed to process content: %w", err)
 	}
+	state.contentProcessed.Add(1)
 
-	log.Printf("processContentURL: Successfully extracted article - Title: %s, URL: %s", article.Title, article.URL)
+	// Keep the feed description found during discovery as the article's summary
+	if article.Summary == "" {
+		item, _ := p.feedItems.Get(url)
+		article.Summary = item.Summary
+	}
+
+	logging.Debugf("processContentURL: Successfully extracted article - Title: %s, URL: %s", article.Title, article.URL)
 
 	// Save article
-	log.Printf("processContentURL: Saving article to database - URL: %s", article.URL)
-	if err := p.contentConsumer.ContentSaver.SaveArticle(ctx, article); err != nil {
-		log.Printf("processContentURL: ERROR saving article to database - URL: %s, Error: %v", article.URL, err)
+	if !state.reserveSave() {
+		return errMaxArticlesReached
+	}
+	logging.Debugf("processContentURL: Saving article to database - URL: %s", article.URL)
+	// An article that was already fetched is still saved if the run is being cancelled (e.g., Ctrl-C)
+	if err := p.contentConsumer.ContentSaver.SaveArticle(context.WithoutCancel(ctx), article); errors.Is(err, ErrDuplicateContent) {
+		// Nothing was written, so the article doesn't count towards the saved articles
+		state.releaseSave()
+		p.markSeen(ctx, url)
+		return err
+	} else if err != nil {
+		logging.Debugf("processContentURL: ERROR saving article to database - URL: %s, Error: %v", article.URL, err)
+		state.releaseSave()
+		state.recordSaveResult(err)
 		return fmt.Errorf("failed to save article: %w", err)
 	}
+	state.addSaved()
+	state.progress.AddSaved(1)
+	state.recordSaveResult(nil)
+	metrics.ArticlesSaved.Inc()
+	p.markSeen(ctx, url)
 
-	log.Printf("processContentURL: SUCCESS - Article saved to database - URL: %s", article.URL)
+	logging.Debugf("processContentURL: SUCCESS - Article saved to database - URL: %s", article.URL)
 	return nil
 }
+
+// recordFailure hands a content URL that failed to process to the failed URL recorder, if set
+// Failures caused by the run being cancelled aren't recorded
+func (p *Pipeline) recordFailure(ctx context.Context, url string, processErr error) {
+	if p.failures == nil || ctx.Err() != nil {
+		return
+	}
+	if err := p.failures.RecordFailedURL(ctx, url, processErr); err != nil {
+		logging.Warnf("processContentURL: Failed to record failed URL %s: %v", url, err)
+	}
+}
+
+// seenRecently reports whether the seen filter, if set, skips url
+// Lookup errors are logged and the URL is processed
+func (p *Pipeline) seenRecently(ctx context.Context, url string) bool {
+	if p.seen == nil {
+		return false
+	}
+	keep, err := p.seen.ShouldKeep(ctx, url)
+	if err != nil {
+		logging.Warnf("Pipeline: Failed to look up seen URL %s, processing it: %v", url, err)
+		return false
+	}
+	return !keep
+}
+
+// markSeen records url as attempted in the seen filter, if set
+func (p *Pipeline) markSeen(ctx context.Context, url string) {
+	if p.seen == nil {
+		return
+	}
+	if err := p.seen.MarkSeen(context.WithoutCancel(ctx), url); err != nil {
+		logging.Warnf("Pipeline: Failed to mark URL %s as seen: %v", url, err)
+	}
+}
+
+// flushSeen persists the URLs marked during the run, if a seen filter is set
+func (p *Pipeline) flushSeen(ctx context.Context) {
+	if p.seen == nil {
+		return
+	}
+	if err := p.seen.Flush(context.WithoutCancel(ctx)); err != nil {
+		logging.Warnf("Pipeline: Failed to save seen URLs: %v", err)
+	}
+}
+
+// errMaxArticlesReached marks content URLs skipped because ContentConsumer.MaxArticles was reached
+var errMaxArticlesReached = errors.New("max articles reached")
+
+// maxJoinedContentErrors caps how many per-URL errors are included in Run's error
+const maxJoinedContentErrors = 10
+
+// PipelineStats summarizes a single pipeline run
+type PipelineStats struct {
+	URLsGenerated    int64   // URLs produced by the first step
+	URLsPerStep      []int64 // URLs produced by each step, in step order
+	ContentProcessed int64   // Content URLs successfully fetched and extracted
+	ContentSaved     int64   // Articles successfully saved
+	ContentUnchanged int64   // Content URLs skipped because the server reported them unchanged (304)
+	ContentSkipped   int64   // Content URLs skipped as non-articles (HEAD precheck, minimum text length, soft 404, oversized PDF) or duplicate content
+	Errors           int64   // Failed step fetches plus failed content URLs
+}
+
+// runState accumulates errors and counts from all pipeline workers during a single Run
+type runState struct {
+	mu             sync.Mutex
+	fatal          []error
+	contentErrs    []error
+	contentTotal   int
+	contentFailed  int
+	stepURLsFailed int
+
+	urlsPerStep      []atomic.Int64
+	contentProcessed atomic.Int64
+	contentSaved     atomic.Int64
+	contentUnchanged atomic.Int64
+	contentSkipped   atomic.Int64
+
+	cancel                     context.CancelFunc
+	maxConsecutiveSaveFailures int
+	consecutiveSaveFailures    int
+
+	maxArticles int
+	saveSlots   atomic.Int64 // Saves started or finished, so concurrent workers can't overshoot maxArticles
+
+	progress *progress.Reporter       // nil when no OnProgress callback is set
+	errorLog *logging.ErrorAggregator // Rolls up repeated step and content errors; nil logs each one
+}
+
+// newRunState creates the run state for a pipeline with the given number of steps
+func newRunState(stepCount int) *runState {
+	return &runState{urlsPerStep: make([]atomic.Int64, stepCount)}
+}
+
+// stats returns a snapshot of the run's counts
+func (r *runState) stats() PipelineStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := PipelineStats{
+		URLsPerStep:      make([]int64, len(r.urlsPerStep)),
+		ContentProcessed: r.contentProcessed.Load(),
+		ContentSaved:     r.contentSaved.Load(),
+		ContentUnchanged: r.contentUnchanged.Load(),
+		ContentSkipped:   r.contentSkipped.Load(),
+		Errors:           int64(r.stepURLsFailed + r.contentFailed + len(r.fatal)),
+	}
+	for i := range r.urlsPerStep {
+		stats.URLsPerStep[i] = r.urlsPerStep[i].Load()
+	}
+	if len(stats.URLsPerStep) > 0 {
+		stats.URLsGenerated = stats.URLsPerStep[0]
+	}
+	return stats
+}
+
+// addFatal records an error that stops the pipeline from producing any URLs
+func (r *runState) addFatal(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fatal = append(r.fatal, err)
+}
+
+// recordSaveResult tracks consecutive save failures and cancels the run once the
+// configured threshold is reached
+func (r *runState) recordSaveResult(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		r.consecutiveSaveFailures = 0
+		return
+	}
+
+	r.consecutiveSaveFailures++
+	if r.maxConsecutiveSaveFailures <= 0 || r.consecutiveSaveFailures != r.maxConsecutiveSaveFailures {
+		return
+	}
+
+	logging.Errorf("Pipeline: %d consecutive save failures, stopping the pipeline", r.consecutiveSaveFailures)
+	r.fatal = append(r.fatal, fmt.Errorf("stopped after %d consecutive save failures: %w", r.consecutiveSaveFailures, err))
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// reserveSave claims a save slot under maxArticles; false means the limit is already taken
+func (r *runState) reserveSave() bool {
+	if r.maxArticles <= 0 {
+		return true
+	}
+	if r.saveSlots.Add(1) > int64(r.maxArticles) {
+		r.saveSlots.Add(-1)
+		return false
+	}
+	return true
+}
+
+// releaseSave gives back a slot claimed by reserveSave when the save failed
+func (r *runState) releaseSave() {
+	if r.maxArticles > 0 {
+		r.saveSlots.Add(-1)
+	}
+}
+
+// addSaved counts a saved article and cancels the run once maxArticles is reached
+func (r *runState) addSaved() {
+	saved := r.contentSaved.Add(1)
+	if r.maxArticles > 0 && saved == int64(r.maxArticles) {
+		logging.Infof("Pipeline: saved %d articles, stopping the pipeline", saved)
+		if r.cancel != nil {
+			r.cancel()
+		}
+	}
+}
+
+// maxArticlesReached reports whether maxArticles articles were saved
+func (r *runState) maxArticlesReached() bool {
+	return r.maxArticles > 0 && r.contentSaved.Load() >= int64(r.maxArticles)
+}
+
+// addStepURLs counts URLs produced by a step
+func (r *runState) addStepURLs(stepIndex, n int) {
+	r.urlsPerStep[stepIndex].Add(int64(n))
+	metrics.URLsDiscovered.Add(uint64(n))
+	if stepIndex == len(r.urlsPerStep)-1 {
+		r.progress.AddURLs(n)
+	}
+}
+
+// addStepError counts a non-fatal URL fetch failure in an intermediate step
+func (r *runState) addStepError() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stepURLsFailed++
+}
+
+// addContentResult counts a processed content URL and keeps its error, if any
+func (r *runState) addContentResult(url string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contentTotal++
+	if err == nil {
+		return
+	}
+	r.contentFailed++
+	if len(r.contentErrs) < maxJoinedContentErrors {
+		r.contentErrs = append(r.contentErrs, fmt.Errorf("%s: %w", url, err))
+	}
+}
+
+// err returns the joined error for the run, or nil if the run (at least partially) succeeded
+func (r *runState) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stepURLsFailed > 0 || r.contentFailed > 0 {
+		logging.Warnf("Pipeline: %d step URL(s) and %d/%d content URL(s) failed", r.stepURLsFailed, r.contentFailed, r.contentTotal)
+	}
+
+	errs := append([]error{}, r.fatal...)
+	if r.contentTotal > 0 && r.contentFailed == r.contentTotal {
+		errs = append(errs, fmt.Errorf("all %d content URLs failed", r.contentTotal))
+		errs = append(errs, r.contentErrs...)
+	}
+	return errors.Join(errs...)
+}
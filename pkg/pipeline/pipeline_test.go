@@ -2,10 +2,24 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"blog-search/pkg/domain"
+	"blog-search/pkg/httpclient"
+	"blog-search/pkg/metrics"
+	"blog-search/pkg/progress"
+	"blog-search/pkg/urls"
 )
 
 // mockURLGenerator is a mock implementation of URLGenerator for testing
@@ -348,3 +362,774 @@ func TestPipeline_Run_MultiStepPipeline(t *testing.T) {
 		t.Errorf("Expected exactly %d unique URLs saved, got %d", len(expectedURLs), len(savedURLs))
 	}
 }
+
+func TestPipeline_Run_FailingGeneratorReturnsError(t *testing.T) {
+	generator := &mockURLGenerator{err: errors.New("pagination endpoint down")}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}
+	consumer := ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver}
+
+	err := NewPipeline(steps, consumer).Run(context.Background(), "https://example.com")
+	if err == nil {
+		t.Fatal("Expected error when the generator fails, got nil")
+	}
+	if !strings.Contains(err.Error(), "pagination endpoint down") {
+		t.Errorf("Expected the generator error to be returned, got: %v", err)
+	}
+	if processor.callCount != 0 {
+		t.Errorf("Expected no content processing, got %d calls", processor.callCount)
+	}
+}
+
+// failingURLProcessor fails content processing for the configured URLs
+type failingURLProcessor struct {
+	failing map[string]bool
+}
+
+func (m *failingURLProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	if m.failing[url] {
+		return nil, errors.New("extraction failed")
+	}
+	return &domain.Article{URL: url, Title: "Test Article", Text: "Test content", CrawledAt: time.Now()}, nil
+}
+
+func TestPipeline_Run_PartialContentFailuresComplete(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/ok", "https://example.com/bad"}}
+	processor := &failingURLProcessor{failing: map[string]bool{"https://example.com/bad": true}}
+	saver := &mockContentSaver{}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}
+	consumer := ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver}
+
+	if err := NewPipeline(steps, consumer).Run(context.Background(), "https://example.com"); err != nil {
+		t.Fatalf("Expected partial failures to be non-fatal, got: %v", err)
+	}
+	if len(saver.savedArticles) != 1 || saver.savedArticles[0].URL != "https://example.com/ok" {
+		t.Errorf("Expected only the successful article to be saved, got %v", saver.savedArticles)
+	}
+}
+
+func TestPipeline_Run_AllContentFailuresReturnError(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/a", "https://example.com/b"}}
+	processor := &failingURLProcessor{failing: map[string]bool{
+		"https://example.com/a": true,
+		"https://example.com/b": true,
+	}}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}
+	consumer := ContentConsumer{WorkerCount: 2, ContentProcessor: processor, ContentSaver: &mockContentSaver{}}
+
+	err := NewPipeline(steps, consumer).Run(context.Background(), "https://example.com")
+	if err == nil {
+		t.Fatal("Expected error when every content URL fails, got nil")
+	}
+	if !strings.Contains(err.Error(), "all 2 content URLs failed") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPipeline_Run2_ReturnsStats(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/page/1", "https://example.com/page/2"}}
+	fetcher := &mockURLFetcher{urls: map[string][]string{
+		"https://example.com/page/1": {"https://example.com/a", "https://example.com/b"},
+		"https://example.com/page/2": {"https://example.com/c"},
+	}}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+		{Name: "Fetcher", WorkerCount: 2, Fetcher: fetcher},
+	}
+	consumer := ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver}
+
+	stats, err := NewPipeline(steps, consumer).Run2(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if stats.URLsGenerated != 2 {
+		t.Errorf("Expected 2 generated URLs, got %d", stats.URLsGenerated)
+	}
+	if len(stats.URLsPerStep) != 2 || stats.URLsPerStep[1] != 3 {
+		t.Errorf("Expected 3 URLs from the fetcher step, got %v", stats.URLsPerStep)
+	}
+	if stats.ContentProcessed != int64(processor.callCount) || stats.ContentProcessed != 3 {
+		t.Errorf("Expected 3 processed, got %d (processor called %d times)", stats.ContentProcessed, processor.callCount)
+	}
+	if stats.ContentSaved != int64(len(saver.savedArticles)) || stats.ContentSaved != 3 {
+		t.Errorf("Expected 3 saved, got %d (saver has %d)", stats.ContentSaved, len(saver.savedArticles))
+	}
+	if stats.Errors != 0 {
+		t.Errorf("Expected no errors, got %d", stats.Errors)
+	}
+}
+
+func TestPipeline_Run2_CountsErrors(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/a", "https://example.com/b"}}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{err: errors.New("db down")}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}
+	consumer := ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver}
+
+	stats, err := NewPipeline(steps, consumer).Run2(context.Background(), "https://example.com")
+	if err == nil {
+		t.Fatal("Expected error when every save fails")
+	}
+	if stats.ContentProcessed != 2 || stats.ContentSaved != 0 || stats.Errors != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestPipeline_Run_StopsAfterConsecutiveSaveFailures(t *testing.T) {
+	urls := make([]string, 100)
+	for i := range urls {
+		urls[i] = "https://example.com/post/" + strconv.Itoa(i)
+	}
+	generator := &mockURLGenerator{urls: urls}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{err: errors.New("connection refused")}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}
+	consumer := ContentConsumer{
+		WorkerCount:                1,
+		ContentProcessor:           processor,
+		ContentSaver:               saver,
+		MaxConsecutiveSaveFailures: 3,
+	}
+
+	err := NewPipeline(steps, consumer).Run(context.Background(), "https://example.com")
+	if err == nil {
+		t.Fatal("Expected error when saves keep failing")
+	}
+	if !strings.Contains(err.Error(), "3 consecutive save failures") {
+		t.Errorf("Expected consecutive failure error, got: %v", err)
+	}
+	if processor.callCount >= len(urls) {
+		t.Errorf("Expected the pipeline to stop early, but all %d URLs were processed", processor.callCount)
+	}
+}
+
+// concurrencyTrackingProcessor records the peak number of concurrent ProcessContent calls
+// while honoring the pipeline's request semaphore
+type concurrencyTrackingProcessor struct {
+	sem      chan struct{}
+	inFlight atomic.Int64
+	peak     atomic.Int64
+}
+
+func (m *concurrencyTrackingProcessor) SetRequestSemaphore(sem chan struct{}) {
+	m.sem = sem
+}
+
+func (m *concurrencyTrackingProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	if err := acquireRequestSlot(ctx, m.sem); err != nil {
+		return nil, err
+	}
+	defer releaseRequestSlot(m.sem)
+
+	current := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if current <= peak || m.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	return &domain.Article{URL: url, Title: "Test Article", Text: "Test content", CrawledAt: time.Now()}, nil
+}
+
+func TestPipeline_Run_MaxInFlightRequests(t *testing.T) {
+	urls := make([]string, 30)
+	for i := range urls {
+		urls[i] = "https://example.com/post/" + strconv.Itoa(i)
+	}
+	processor := &concurrencyTrackingProcessor{}
+
+	steps := []PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: &mockURLGenerator{urls: urls}},
+	}
+	consumer := ContentConsumer{WorkerCount: 8, ContentProcessor: processor, ContentSaver: &syncContentSaver{}}
+
+	p := NewPipeline(steps, consumer, WithMaxInFlightRequests(2))
+	if err := p.Run(context.Background(), "https://example.com"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if peak := processor.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", peak)
+	}
+	if processor.sem == nil {
+		t.Error("Expected the pipeline to share its request semaphore with the processor")
+	}
+}
+
+// syncContentSaver is a ContentSaver that is safe to use from many workers
+type syncContentSaver struct {
+	saved atomic.Int64
+}
+
+func (m *syncContentSaver) SaveArticle(ctx context.Context, article *domain.Article) error {
+	m.saved.Add(1)
+	return nil
+}
+
+func TestPipeline_Run_StepFiltersDropURLs(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/index/1"}}
+	fetcher := &mockURLFetcher{urls: map[string][]string{
+		"https://example.com/index/1": {
+			"https://example.com/blog/a",
+			"https://example.com/about",
+			"https://example.com/blog/b",
+			"https://example.com/careers",
+		},
+	}}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{}
+
+	steps := []PipelineStep{
+		{Name: "Index", WorkerCount: 1, Generator: generator},
+		{Name: "Links", WorkerCount: 1, Fetcher: fetcher, Filters: []urls.UrlFilter{urls.NewContainsPathFilter("/blog")}},
+	}
+	consumer := ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver}
+
+	stats, err := NewPipeline(steps, consumer).Run2(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(saver.savedArticles) != 2 {
+		t.Fatalf("Expected 2 articles after filtering, got %d", len(saver.savedArticles))
+	}
+	for _, article := range saver.savedArticles {
+		if !strings.Contains(article.URL, "/blog/") {
+			t.Errorf("Expected only blog URLs, got %s", article.URL)
+		}
+	}
+	if stats.URLsPerStep[1] != 2 {
+		t.Errorf("Expected the step to forward 2 URLs, got %d", stats.URLsPerStep[1])
+	}
+}
+
+func TestPipeline_Discover_ReturnsURLsWithoutProcessingContent(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/page1", "https://example.com/page2"}}
+	fetcher := &mockURLFetcher{
+		urls: map[string][]string{
+			"https://example.com/page1": {"https://example.com/article1", "https://example.com/shared"},
+			"https://example.com/page2": {"https://example.com/article2", "https://example.com/shared"},
+		},
+	}
+	processor := &mockContentProcessor{}
+	saver := &mockContentSaver{}
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Page Generator", WorkerCount: 1, Generator: generator},
+		{Name: "Article Fetcher", WorkerCount: 2, Fetcher: fetcher},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver})
+
+	discovered, err := p.Discover(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	if len(discovered) != 3 {
+		t.Fatalf("Expected 3 unique URLs, got %d: %v", len(discovered), discovered)
+	}
+	if processor.callCount != 0 || saver.callCount != 0 {
+		t.Errorf("Expected no content processing or saving, got %d processed, %d saved", processor.callCount, saver.callCount)
+	}
+}
+
+func TestPipeline_Discover_FailingFirstStepReturnsError(t *testing.T) {
+	p := NewPipeline([]PipelineStep{
+		{Name: "Page Generator", WorkerCount: 1, Generator: &mockURLGenerator{err: errors.New("sitemap unavailable")}},
+	}, ContentConsumer{})
+
+	if _, err := p.Discover(context.Background(), "https://example.com"); err == nil {
+		t.Fatal("Expected error when the first step fails, got nil")
+	}
+}
+
+func TestPipeline_Run_IncrementsArticlesSavedMetric(t *testing.T) {
+	savedBefore := metrics.ArticlesSaved.Value()
+	discoveredBefore := metrics.URLsDiscovered.Value()
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: &mockURLGenerator{urls: []string{"https://example.com/a", "https://example.com/b"}}},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: &mockContentProcessor{}, ContentSaver: &mockContentSaver{}})
+
+	if err := p.Run(context.Background(), "https://example.com"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got := metrics.ArticlesSaved.Value() - savedBefore; got != 2 {
+		t.Errorf("Expected articles_saved_total to increase by 2, got %d", got)
+	}
+	if got := metrics.URLsDiscovered.Value() - discoveredBefore; got != 2 {
+		t.Errorf("Expected urls_discovered_total to increase by 2, got %d", got)
+	}
+}
+
+// slowContentProcessor returns a default article after a short delay, so a run spans several progress ticks
+type slowContentProcessor struct {
+	delay time.Duration
+}
+
+func (m *slowContentProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	time.Sleep(m.delay)
+	return &domain.Article{URL: url, Title: "Test Article", Text: "Test content", CrawledAt: time.Now()}, nil
+}
+
+func TestPipeline_Run_ReportsMonotonicProgress(t *testing.T) {
+	pages := make([]string, 4)
+	pageArticles := make(map[string][]string)
+	for i := range pages {
+		pages[i] = "https://example.com/page/" + strconv.Itoa(i+1)
+		for j := 0; j < 5; j++ {
+			pageArticles[pages[i]] = append(pageArticles[pages[i]], pages[i]+"/article/"+strconv.Itoa(j))
+		}
+	}
+
+	var reports []progress.Progress
+	var inCallback atomic.Int32
+	p := NewPipeline([]PipelineStep{
+		{Name: "Page Generator", WorkerCount: 1, Generator: &mockURLGenerator{urls: pages}},
+		{Name: "Article Fetcher", WorkerCount: 2, Fetcher: &mockURLFetcher{urls: pageArticles}},
+	}, ContentConsumer{
+		WorkerCount:      2,
+		ContentProcessor: &slowContentProcessor{delay: 3 * time.Millisecond},
+		ContentSaver:     &syncContentSaver{},
+		OnProgress: func(pr progress.Progress) {
+			if inCallback.Add(1) > 1 {
+				t.Error("OnProgress called concurrently")
+			}
+			reports = append(reports, pr)
+			inCallback.Add(-1)
+		},
+		ProgressInterval: 5 * time.Millisecond,
+	})
+
+	if err := p.Run(context.Background(), "https://example.com"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("Expected several progress reports, got %d", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		prev, cur := reports[i-1], reports[i]
+		if cur.PagesProcessed < prev.PagesProcessed || cur.URLsExtracted < prev.URLsExtracted || cur.ArticlesSaved < prev.ArticlesSaved {
+			t.Errorf("Progress went backwards: %+v -> %+v", prev, cur)
+		}
+	}
+
+	final := reports[len(reports)-1]
+	expected := progress.Progress{PagesProcessed: 4, URLsExtracted: 20, ArticlesSaved: 20}
+	if final != expected {
+		t.Errorf("Expected final progress %+v, got %+v", expected, final)
+	}
+}
+
+func TestPipeline_Run_StopsAtMaxArticles(t *testing.T) {
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = "https://example.com/post/" + strconv.Itoa(i)
+	}
+	saver := &syncContentSaver{}
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: &mockURLGenerator{urls: urls}},
+	}, ContentConsumer{
+		WorkerCount:      3,
+		ContentProcessor: &slowContentProcessor{delay: time.Millisecond},
+		ContentSaver:     saver,
+		MaxArticles:      3,
+	})
+
+	stats, err := p.Run2(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Expected no error when stopping at the article limit, got: %v", err)
+	}
+	if saved := saver.saved.Load(); saved != 3 {
+		t.Errorf("Expected exactly 3 articles saved, got %d", saved)
+	}
+	if stats.ContentSaved != 3 {
+		t.Errorf("Expected stats to report 3 saved, got %d", stats.ContentSaved)
+	}
+}
+
+// staticURLsFetcher is a urls.URLsFetcher returning fixed URLs
+type staticURLsFetcher struct {
+	urls []urls.URL
+}
+
+func (f *staticURLsFetcher) Fetch(baseURL string) ([]urls.URL, error) {
+	return f.urls, nil
+}
+
+func TestPipeline_Run_AttachesFeedSummaries(t *testing.T) {
+	fetcher := NewBasicURLFetcher(&staticURLsFetcher{urls: []urls.URL{
+		{Location: "https://example.com/a", Summary: "How we sharded Postgres."},
+		{Location: "https://example.com/b"},
+	}})
+	saver := NewInMemoryContentSaver()
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Feed", WorkerCount: 1, Fetcher: fetcher},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: &mockContentProcessor{}, ContentSaver: saver})
+
+	if err := p.Run(context.Background(), "https://example.com/feed.xml"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	summaries := make(map[string]string)
+	for _, article := range saver.Articles() {
+		summaries[article.URL] = article.Summary
+	}
+	if summaries["https://example.com/a"] != "How we sharded Postgres." {
+		t.Errorf("Expected the feed summary on the article, got %q", summaries["https://example.com/a"])
+	}
+	if summaries["https://example.com/b"] != "" {
+		t.Errorf("Expected no summary for a URL without one, got %q", summaries["https://example.com/b"])
+	}
+}
+
+func TestPipeline_Run_UsesFeedContent(t *testing.T) {
+	fetcher := NewBasicURLFetcher(&staticURLsFetcher{urls: []urls.URL{
+		{Location: "https://example.com/full", Title: "Full Post", Content: "<p>Whole post.</p>"},
+		{Location: "https://example.com/teaser", Title: "Teaser Only"},
+	}})
+	fallback := &mockContentProcessor{}
+	saver := NewInMemoryContentSaver()
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Feed", WorkerCount: 1, Fetcher: fetcher},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: NewFeedContentProcessor(fallback), ContentSaver: saver})
+
+	if err := p.Run(context.Background(), "https://example.com/feed.xml"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	texts := make(map[string]string)
+	for _, article := range saver.Articles() {
+		texts[article.URL] = article.Text
+	}
+	if texts["https://example.com/full"] != "Whole post." {
+		t.Errorf("Expected the article text from the feed, got %q", texts["https://example.com/full"])
+	}
+	if texts["https://example.com/teaser"] != "Test content" {
+		t.Errorf("Expected the fetched article for an item without content, got %q", texts["https://example.com/teaser"])
+	}
+	if fallback.callCount != 1 {
+		t.Errorf("Expected exactly 1 fetch, got %d", fallback.callCount)
+	}
+}
+
+func TestPipeline_Run2_CountsUnchangedPages(t *testing.T) {
+	var requests atomic.Int32
+	server := notModifiedServer(t, &requests)
+	pageURL := server.URL + "/post"
+
+	fetcher := NewBasicURLFetcher(&staticURLsFetcher{urls: []urls.URL{{Location: pageURL}}})
+	saver := NewInMemoryContentSaver()
+	processor := NewRetryingContentProcessor(NewHTTPContentProcessorWithExtractor(&countingExtractor{}), 2, time.Millisecond)
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Feed", WorkerCount: 1, Fetcher: fetcher},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver})
+	if !p.SetConditionalFetch(mapArticleLookup{pageURL: {URL: pageURL, ETag: `"v1"`}}) {
+		t.Fatal("Expected the processor to support conditional fetches")
+	}
+
+	stats, err := p.Run2(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("Run2 failed: %v", err)
+	}
+	if stats.ContentUnchanged != 1 || stats.ContentSaved != 0 || stats.Errors != 0 {
+		t.Errorf("Expected 1 unchanged, 0 saved and 0 errors, got %+v", stats)
+	}
+	if len(saver.Articles()) != 0 {
+		t.Errorf("Expected the stored article to be kept, got %d saves", len(saver.Articles()))
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected a single request without retries, got %d", n)
+	}
+}
+
+// signallingProcessor closes started when it processes its first URL
+type signallingProcessor struct {
+	mockContentProcessor
+	once    sync.Once
+	started chan struct{}
+}
+
+func (m *signallingProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	m.once.Do(func() { close(m.started) })
+	return m.mockContentProcessor.ProcessContent(ctx, url)
+}
+
+func TestPipeline_Run_StreamsSitemapToContentWorkers(t *testing.T) {
+	processor := &signallingProcessor{started: make(chan struct{})}
+
+	// The server sends half of the sitemap, then waits for content processing to start
+	streamed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		for i := 1; i <= 300; i++ {
+			if i == 151 {
+				w.(http.Flusher).Flush()
+				select {
+				case <-processor.started:
+					streamed = true
+				case <-time.After(5 * time.Second):
+				}
+			}
+			fmt.Fprintf(w, "<url><loc>https://example.com/post-%d</loc></url>", i)
+		}
+		fmt.Fprint(w, `</urlset>`)
+	}))
+	defer server.Close()
+
+	saver := &mockContentSaver{}
+	p := NewPipeline([]PipelineStep{
+		{Name: "Sitemap Fetcher", WorkerCount: 1, Fetcher: NewSitemapStreamFetcher(nil)},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver})
+
+	stats, err := p.Run2(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Run2 failed: %v", err)
+	}
+	if !streamed {
+		t.Error("Expected content processing to start before the whole sitemap was read")
+	}
+	if len(saver.savedArticles) != 300 || stats.URLsPerStep[0] != 300 {
+		t.Errorf("Expected all 300 URLs to be streamed and saved, got %d saved and %d streamed", len(saver.savedArticles), stats.URLsPerStep[0])
+	}
+}
+
+func TestPipeline_Run_FailingStreamReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Sitemap Fetcher", WorkerCount: 1, Fetcher: NewSitemapStreamFetcher(nil)},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: &mockContentProcessor{}, ContentSaver: &mockContentSaver{}})
+
+	if err := p.Run(context.Background(), server.URL); err == nil {
+		t.Fatal("Expected an error when the sitemap can't be streamed, got nil")
+	}
+}
+
+// recordingFailures is a db.FailedURLRecorder that remembers the recorded URLs
+type recordingFailures struct {
+	mu     sync.Mutex
+	failed map[string]error
+}
+
+func (r *recordingFailures) RecordFailedURL(ctx context.Context, url string, fetchErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[url] = fetchErr
+	return nil
+}
+
+func TestPipeline_Run_RecordsFailedContentURLs(t *testing.T) {
+	generator := &mockURLGenerator{urls: []string{"https://example.com/ok", "https://example.com/bad"}}
+	processor := &failingURLProcessor{failing: map[string]bool{"https://example.com/bad": true}}
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}, ContentConsumer{WorkerCount: 2, ContentProcessor: processor, ContentSaver: &syncContentSaver{}})
+	failures := &recordingFailures{failed: make(map[string]error)}
+	p.SetFailedURLRecorder(failures)
+
+	if err := p.Run(context.Background(), "https://example.com"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(failures.failed) != 1 || failures.failed["https://example.com/bad"] == nil {
+		t.Errorf("Expected only the failing URL to be recorded with its error, got %v", failures.failed)
+	}
+}
+
+func TestPipeline_SetClientType_ReachesFetchersAndProcessor(t *testing.T) {
+	// Like a site that answers 406 to curl-like requests
+	var refused atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "Mozilla/") {
+			refused.Add(1)
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		fmt.Fprintf(w, `<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1><p>%s</p></article></body></html>`,
+			strings.Repeat("Partitions let consumers scale horizontally. ", 20))
+	}))
+	defer server.Close()
+
+	fetcher := NewHTMLPageFetcherWithBase(func(html, pageURL string) ([]urls.URL, error) {
+		return []urls.URL{{Location: pageURL + "/post"}}, nil
+	}, nil)
+	processor := NewRetryingContentProcessor(NewHTTPContentProcessor(), 0, 0)
+	p := NewPipeline([]PipelineStep{{Name: "HTML Page Fetcher", WorkerCount: 1, Fetcher: fetcher}},
+		ContentConsumer{ContentProcessor: processor})
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Expected the default client to be refused")
+	}
+
+	if !p.SetClientType(httpclient.BrowserClient) {
+		t.Fatal("Expected the wrapped HTTP processor to support the client type")
+	}
+	refused.Store(0)
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err != nil {
+		t.Errorf("Expected the page fetcher to use the browser client, got %v", err)
+	}
+	if _, err := processor.ProcessContent(context.Background(), server.URL); err != nil {
+		t.Errorf("Expected the content processor to use the browser client, got %v", err)
+	}
+	if n := refused.Load(); n != 0 {
+		t.Errorf("Expected the browser client to be tried first, got %d refused requests", n)
+	}
+}
+
+func TestPipeline_SetClientOptions_ReachesFetchersAndProcessor(t *testing.T) {
+	// Like a site that only serves logged-in requests
+	var missingCookie atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "session=abc123" {
+			missingCookie.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1><p>%s</p></article></body></html>`,
+			strings.Repeat("Partitions let consumers scale horizontally. ", 20))
+	}))
+	defer server.Close()
+
+	fetcher := NewHTMLPageFetcherWithBase(func(html, pageURL string) ([]urls.URL, error) {
+		return []urls.URL{{Location: pageURL + "/post"}}, nil
+	}, nil)
+	processor := NewRetryingContentProcessor(NewHTTPContentProcessor(), 0, 0)
+	p := NewPipeline([]PipelineStep{{Name: "HTML Page Fetcher", WorkerCount: 1, Fetcher: fetcher}},
+		ContentConsumer{ContentProcessor: processor})
+
+	opts := httpclient.ClientOptions{
+		ExtraHeaders:     map[string]string{"Cookie": "session=abc123"},
+		ExtraHeadersHost: strings.TrimPrefix(server.URL, "http://"),
+	}
+	if !p.SetClientOptions(opts) {
+		t.Fatal("Expected the wrapped HTTP processor to support client options")
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err != nil {
+		t.Errorf("Expected the page fetcher to send the cookie, got %v", err)
+	}
+	if _, err := processor.ProcessContent(context.Background(), server.URL); err != nil {
+		t.Errorf("Expected the content processor to send the cookie, got %v", err)
+	}
+	if n := missingCookie.Load(); n != 0 {
+		t.Errorf("Expected every request to carry the cookie, got %d without it", n)
+	}
+}
+
+// perURLProcessor fails the URLs in errs with their error and returns an article for the others,
+// counting the calls per URL
+type perURLProcessor struct {
+	mu    sync.Mutex
+	errs  map[string]error
+	calls map[string]int
+}
+
+func (m *perURLProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls[url]++
+	if err := m.errs[url]; err != nil {
+		return nil, err
+	}
+	return &domain.Article{URL: url, Title: "Test Article", Text: "Test content"}, nil
+}
+
+func TestPipeline_SeenFilter_SkipsOnlyFinishedAttemptsOnNextRun(t *testing.T) {
+	const (
+		saved     = "https://example.com/saved"
+		missing   = "https://example.com/missing"
+		transient = "https://example.com/transient"
+	)
+	path := filepath.Join(t.TempDir(), "seen.json")
+	processor := &perURLProcessor{
+		errs: map[string]error{
+			missing:   &HTTPStatusError{StatusCode: http.StatusNotFound},
+			transient: &HTTPStatusError{StatusCode: http.StatusServiceUnavailable},
+		},
+		calls: make(map[string]int),
+	}
+
+	run := func() PipelineStats {
+		t.Helper()
+		store, err := urls.NewFileSeenStore(path)
+		if err != nil {
+			t.Fatalf("NewFileSeenStore failed: %v", err)
+		}
+		fetcher := NewBasicURLFetcher(&staticURLsFetcher{urls: []urls.URL{{Location: saved}, {Location: missing}, {Location: transient}}})
+		p := NewPipeline([]PipelineStep{
+			{Name: "Feed", WorkerCount: 1, Fetcher: fetcher},
+		}, ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: NewInMemoryContentSaver()})
+		p.SetSeenFilter(urls.NewSeenFilter(store, time.Hour))
+
+		stats, _ := p.Run2(context.Background(), "https://example.com/feed.xml")
+		return stats
+	}
+
+	run()
+	stats := run()
+
+	want := map[string]int{saved: 1, missing: 1, transient: 2}
+	if !reflect.DeepEqual(processor.calls, want) {
+		t.Errorf("Expected only the transient failure to be retried, got calls %v", processor.calls)
+	}
+	if stats.ContentSkipped != 2 {
+		t.Errorf("Expected 2 URLs skipped as seen on the second run, got %+v", stats)
+	}
+}
+
+func TestPipeline_SetContentDedup_CountsDuplicatesAsSkipped(t *testing.T) {
+	const (
+		stored = "https://example.com/post"
+		mirror = "https://example.com/post/amp"
+	)
+	// The mirror comes first, so it would take the only -max-articles slot if it counted as saved
+	generator := &mockURLGenerator{urls: []string{mirror, stored}}
+	processor := &perURLProcessor{calls: make(map[string]int)}
+	saver := &mockContentSaver{}
+
+	p := NewPipeline([]PipelineStep{
+		{Name: "Generator", WorkerCount: 1, Generator: generator},
+	}, ContentConsumer{WorkerCount: 1, ContentProcessor: processor, ContentSaver: saver})
+	p.SetContentDedup(&mockHashChecker{stored: map[string]string{domain.ContentHash("Test content"): stored}})
+	p.SetMaxArticles(1)
+
+	stats, err := p.Run2(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Run2 failed: %v", err)
+	}
+	if len(saver.savedArticles) != 1 || saver.savedArticles[0].URL != stored {
+		t.Errorf("Expected only the re-crawl of the stored URL to be saved, got %v", saver.savedArticles)
+	}
+	if stats.ContentSaved != 1 || stats.ContentSkipped != 1 || stats.Errors != 0 {
+		t.Errorf("Expected 1 saved and 1 skipped article without errors, got %+v", stats)
+	}
+}
@@ -1,47 +1,124 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
+
+	"blog-search/pkg/clock"
 	"blog-search/pkg/content"
 	"blog-search/pkg/db"
 	"blog-search/pkg/domain"
 	"blog-search/pkg/httpclient"
+	"blog-search/pkg/logging"
+	"blog-search/pkg/metrics"
+	"blog-search/pkg/urls"
 )
 
 // HTTPContentProcessor implements ContentProcessor by fetching HTML from URLs
 // and extracting content using the content package
 type HTTPContentProcessor struct {
-	client    *httpclient.HTTPClient
-	extractor content.Extractor
+	client       *httpclient.FallbackHTTPClient // Retries pages refused with 403/406 with the other client type
+	extractor    content.Extractor
+	requestSem   chan struct{}
+	fetchTimeout time.Duration
+	keepRawHTML  bool
+	lookup       ArticleLookup // Set to send conditional GETs for already stored articles
+	headPrecheck bool
+	minTextLen   int      // Pages with less extracted text are rejected with ErrContentTooShort (0 = no minimum)
+	softNotFound []string // Error page markers; nil uses content.DefaultSoftNotFoundMarkers
+	canonical    bool     // Store articles under the page's same-host canonical URL
+	clock        clock.Clock
+}
+
+// ErrSkippedResource is returned by HTTPContentProcessor when the HEAD precheck found a URL that
+// isn't an HTML page (e.g., an image) or is too large to download
+var ErrSkippedResource = errors.New("skipped non-HTML or oversized resource")
+
+// ErrNotModified is returned by content processors when a conditional GET found the stored
+// article unchanged (304 Not Modified); nothing was extracted and the stored record stays as is
+var ErrNotModified = errors.New("not modified since last crawl")
+
+// ErrContentTooShort is returned by HTTPContentProcessor when a page's extracted text is shorter
+// than the minimum set with SetMinTextLength; the page is skipped rather than saved
+var ErrContentTooShort = content.ErrContentTooShort
+
+// ErrSoftNotFound is returned by HTTPContentProcessor when a page served with HTTP 200 is an
+// error page (e.g., "Page not found"); the page is skipped rather than saved
+var ErrSoftNotFound = content.ErrSoftNotFound
+
+// HTTPStatusError is returned by content processors when a page is answered with a status other
+// than 200 OK, so callers can tell transient failures (429, 5xx) from permanent ones (404, 410)
+type HTTPStatusError struct {
+	StatusCode int
 }
 
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// ArticleLookup finds the stored copy of an article; db.Client satisfies it
+// GetArticleByURL must also find articles stored under their canonical URL by their FetchedURL
+type ArticleLookup interface {
+	GetArticleByURL(ctx context.Context, url string) (*domain.Article, error)
+}
+
+// defaultFetchTimeout bounds a single page fetch so one slow server can't hold a worker indefinitely
+const defaultFetchTimeout = 30 * time.Second
+
 // NewHTTPContentProcessor creates a new HTTP content processor
+// Pages are fetched with CloudflareClient, falling back to BrowserClient when refused
 func NewHTTPContentProcessor() *HTTPContentProcessor {
 	return &HTTPContentProcessor{
-		client:    httpclient.NewClient(httpclient.CloudflareClient),
-		extractor: nil, // nil means use default behavior
+		client:       httpclient.NewFallbackClient(httpclient.CloudflareClient),
+		extractor:    nil, // nil means use default behavior
+		fetchTimeout: defaultFetchTimeout,
+		clock:        clock.Real,
 	}
 }
 
-// NewHTTPContentProcessorWithClient creates a new HTTP content processor with a custom client type
+// NewHTTPContentProcessorWithClient creates a new HTTP content processor that tries clientType first
 func NewHTTPContentProcessorWithClient(clientType httpclient.ClientType) *HTTPContentProcessor {
 	return &HTTPContentProcessor{
-		client:    httpclient.NewClient(clientType),
-		extractor: nil, // nil means use default behavior
+		client:       httpclient.NewFallbackClient(clientType),
+		extractor:    nil, // nil means use default behavior
+		fetchTimeout: defaultFetchTimeout,
+		clock:        clock.Real,
+	}
+}
+
+// NewHTTPContentProcessorWithClientOptions creates a new HTTP content processor with a custom client type and options
+// Use opts.MaxBodyBytes to bound how much of each page is read into memory
+func NewHTTPContentProcessorWithClientOptions(clientType httpclient.ClientType, opts httpclient.ClientOptions) *HTTPContentProcessor {
+	return &HTTPContentProcessor{
+		client:       httpclient.NewFallbackClientWithOptions(clientType, opts),
+		extractor:    nil, // nil means use default behavior
+		fetchTimeout: defaultFetchTimeout,
+		clock:        clock.Real,
 	}
 }
 
 // NewHTTPContentProcessorWithExtractor creates a new HTTP content processor with a custom extractor
 func NewHTTPContentProcessorWithExtractor(extractor content.Extractor) *HTTPContentProcessor {
 	return &HTTPContentProcessor{
-		client:    httpclient.NewClient(httpclient.CloudflareClient),
-		extractor: extractor,
+		client:       httpclient.NewFallbackClient(httpclient.CloudflareClient),
+		extractor:    extractor,
+		fetchTimeout: defaultFetchTimeout,
+		clock:        clock.Real,
 	}
 }
 
@@ -50,16 +127,110 @@ func (p *HTTPContentProcessor) SetExtractor(extractor content.Extractor) {
 	p.extractor = extractor
 }
 
+// SetFetchTimeout sets the per-page fetch timeout; zero or negative disables it
+// The fetch is also aborted when the context passed to ProcessContent is done
+func (p *HTTPContentProcessor) SetFetchTimeout(timeout time.Duration) {
+	p.fetchTimeout = timeout
+}
+
+// SetKeepRawHTML stores the fetched HTML in Article.RawHTML, so articles can be re-extracted later
+func (p *HTTPContentProcessor) SetKeepRawHTML(keep bool) {
+	p.keepRawHTML = keep
+}
+
+// SetRequestSemaphore limits concurrent fetches using a semaphore shared with other workers
+func (p *HTTPContentProcessor) SetRequestSemaphore(sem chan struct{}) {
+	p.requestSem = sem
+}
+
+// SetHeadPrecheck sends a HEAD request before each GET and skips URLs whose Content-Type isn't HTML
+// or whose Content-Length exceeds the client's body limit, returning ErrSkippedResource
+// Off by default; if the server rejects the HEAD request, the page is fetched as usual
+func (p *HTTPContentProcessor) SetHeadPrecheck(enabled bool) {
+	p.headPrecheck = enabled
+}
+
+// SetMinTextLength rejects pages whose extracted text has fewer than n characters, returning
+// ErrContentTooShort so thin pages (tag listings, stubs) aren't saved; zero disables the check
+func (p *HTTPContentProcessor) SetMinTextLength(n int) {
+	p.minTextLen = n
+}
+
+// SetSoftNotFoundMarkers replaces the texts that mark a page served with HTTP 200 as an error
+// page (default content.DefaultSoftNotFoundMarkers); such pages return ErrSoftNotFound
+// An empty, non-nil slice disables the check
+func (p *HTTPContentProcessor) SetSoftNotFoundMarkers(markers []string) {
+	p.softNotFound = markers
+}
+
+// SetPreferCanonical stores each article under the URL of the page's <link rel="canonical">
+// instead of the fetched URL (e.g., without ?ref=rss), so one post doesn't get several entries
+// Canonical links to another host are ignored
+func (p *HTTPContentProcessor) SetPreferCanonical(prefer bool) {
+	p.canonical = prefer
+}
+
+// SetArticleLookup enables conditional GETs: pages of stored articles are requested with the
+// stored ETag/Last-Modified, and ProcessContent returns ErrNotModified when the server answers 304
+func (p *HTTPContentProcessor) SetArticleLookup(lookup ArticleLookup) {
+	p.lookup = lookup
+}
+
+// SetClock sets the clock that stamps Article.CrawledAt; the default is clock.Real
+func (p *HTTPContentProcessor) SetClock(c clock.Clock) {
+	p.clock = c
+}
+
+// SetClientType makes page fetches try the headers of clientType first
+func (p *HTTPContentProcessor) SetClientType(clientType httpclient.ClientType) {
+	p.client = p.client.WithClientType(clientType)
+}
+
+// SetClientOptions replaces the options of the page fetch client (e.g., its extra headers)
+func (p *HTTPContentProcessor) SetClientOptions(opts httpclient.ClientOptions) {
+	p.client = p.client.WithOptions(opts)
+}
+
 // ProcessContent fetches HTML from the URL, extracts text and title, and returns an Article
 // If an extractor is set, it uses that; otherwise, it uses the default extraction functions
 func (p *HTTPContentProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	stored := p.storedArticle(ctx, url)
+
 	// Fetch HTML content
-	htmlContent, err := p.fetchHTML(url)
+	if err := acquireRequestSlot(ctx, p.requestSem); err != nil {
+		return nil, err
+	}
+	if p.headPrecheck {
+		if err := p.checkHead(ctx, url, isHTML); err != nil {
+			releaseRequestSlot(p.requestSem)
+			return nil, err
+		}
+	}
+	fetchStart := time.Now()
+	page, err := p.fetchPage(ctx, url, stored)
+	metrics.FetchLatency.ObserveSince(fetchStart)
+	releaseRequestSlot(p.requestSem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
+	}
+
+	htmlContent, err := htmlFromBody(page.body, p.softNotFound)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
 	}
 
+	article, err := p.articleFromHTML(url, htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	page.setValidators(article)
+	return article, nil
+}
+
+// articleFromHTML extracts text and title from fetched HTML and builds the Article
+func (p *HTTPContentProcessor) articleFromHTML(url, htmlContent string) (*domain.Article, error) {
 	var text, title string
+	var err error
 
 	// Use custom extractor if provided, otherwise use default functions
 	if p.extractor != nil {
@@ -73,70 +244,743 @@ func (p *HTTPContentProcessor) ProcessContent(ctx context.Context, url string) (
 			return nil, fmt.Errorf("failed to extract title: %w", err)
 		}
 	} else {
-		// Default behavior: use package-level functions
-		text, err = content.ExtractText(htmlContent)
+		// Default behavior: the default extractor, given the page URL so relative links resolve
+		extractor := content.NewDefaultExtractorWithOptions(pageURL(url))
+		text, err = extractor.ExtractText(htmlContent)
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract text: %w", err)
 		}
 
-		title, err = content.ExtractTitle(htmlContent)
+		title, err = extractor.ExtractTitle(htmlContent)
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract title: %w", err)
 		}
 	}
 
+	if err := content.CheckTextLength(text, p.minTextLen); err != nil {
+		return nil, err
+	}
+
 	// Create article document
 	article := &domain.Article{
-		URL:       url,
+		URL:       urls.NormalizeOrRaw(p.articleURL(url, htmlContent)),
+		Host:      domain.HostFromURL(url),
 		Title:     title,
 		Text:      text,
-		CrawledAt: time.Now(),
+		Tags:      content.ExtractTags(htmlContent),
+		CrawledAt: p.clock.Now(),
+
+		ContentHash: domain.ContentHash(text),
+	}
+	if fetched := urls.NormalizeOrRaw(url); article.URL != fetched {
+		article.FetchedURL = fetched
+	}
+	if p.keepRawHTML {
+		article.RawHTML = htmlContent
 	}
 
 	return article, nil
 }
 
-// fetchHTML fetches HTML content from a URL
-// Uses the configured HTTP client
-func (p *HTTPContentProcessor) fetchHTML(url string) (string, error) {
-	resp, err := p.client.Get(url)
+// articleURL returns the URL the article at pageURL is stored under: its canonical URL if
+// SetPreferCanonical is on and the page declares one on the same host, pageURL otherwise
+func (p *HTTPContentProcessor) articleURL(pageURL, htmlContent string) string {
+	if !p.canonical {
+		return pageURL
+	}
+	canonical, ok := urls.ExtractCanonicalURL(htmlContent, pageURL)
+	if !ok || domain.HostFromURL(canonical) != domain.HostFromURL(pageURL) {
+		return pageURL
+	}
+	return canonical
+}
+
+// pageURL parses the URL a page was fetched from for the extractor; nil if it isn't absolute
+func pageURL(rawURL string) *url.URL {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !parsed.IsAbs() {
+		return nil
+	}
+	return parsed
+}
+
+// htmlFromBody converts a fetched body to HTML, rejecting empty bodies and error pages
+// Error pages are recognized by softNotFound (see content.CheckSoftNotFound) and wrap ErrSoftNotFound
+func htmlFromBody(body []byte, softNotFound []string) (string, error) {
+	bodyStr := string(body)
+
+	if strings.TrimSpace(bodyStr) == "" {
+		return "", fmt.Errorf("server returned error or empty response (status: %d)", http.StatusOK)
+	}
+
+	// Check if we got an error page instead of actual HTML
+	if err := content.CheckSoftNotFound(bodyStr, softNotFound); err != nil {
+		return "", fmt.Errorf("server returned error or empty response (status: %d): %w", http.StatusOK, err)
+	}
+
+	return bodyStr, nil
+}
+
+// checkHead sends a HEAD request for url and returns ErrSkippedResource if the response
+// announces a Content-Type that accept rejects or a Content-Length over the client's body limit
+// Failed or rejected HEAD requests (e.g., 405 Method Not Allowed) let the GET go ahead
+func (p *HTTPContentProcessor) checkHead(ctx context.Context, url string, accept func(contentType string) bool) error {
+	if p.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.fetchTimeout)
+		defer cancel()
+	}
+
+	resp, err := p.client.HeadWithContext(ctx, url)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to check URL: %w", err)
+		}
+		logging.Debugf("HTTPContentProcessor: HEAD %s failed, fetching it anyway: %v", url, err)
+		return nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !accept(contentType) {
+		return fmt.Errorf("%w: Content-Type %q", ErrSkippedResource, contentType)
+	}
+	if limit := p.client.MaxBodyBytes(); limit >= 0 && resp.ContentLength > limit {
+		return fmt.Errorf("%w: Content-Length %d exceeds %d bytes", ErrSkippedResource, resp.ContentLength, limit)
+	}
+	return nil
+}
+
+// isHTML reports whether a Content-Type header names an HTML document
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// fetchedPage is a fetched response body and the headers the processors use
+type fetchedPage struct {
+	body         []byte
+	contentType  string
+	etag         string
+	lastModified string
+}
+
+// setValidators copies the response's cache validators to the article, for the next conditional GET
+func (page *fetchedPage) setValidators(article *domain.Article) {
+	article.ETag = page.etag
+	article.LastModified = page.lastModified
+}
+
+// storedArticle returns the stored copy of the article at url, or nil if conditional GETs are
+// disabled or there is none; a failed lookup only costs an unconditional fetch
+// An article stored under its canonical URL is found through its FetchedURL
+func (p *HTTPContentProcessor) storedArticle(ctx context.Context, url string) *domain.Article {
+	if p.lookup == nil {
+		return nil
+	}
+	stored, err := p.lookup.GetArticleByURL(ctx, urls.NormalizeOrRaw(url))
+	if err != nil {
+		if !errors.Is(err, db.ErrArticleNotFound) {
+			logging.Warnf("HTTPContentProcessor: Failed to look up stored article %s: %v", url, err)
+		}
+		return nil
+	}
+	return stored
+}
+
+// fetchPage fetches a URL and returns its body and headers
+// If stored is non-nil, the request is conditional on its ETag/Last-Modified, and ErrNotModified
+// is returned when the server answers 304
+// The request is bound to ctx, limited by the processor's fetch timeout
+func (p *HTTPContentProcessor) fetchPage(ctx context.Context, url string, stored *domain.Article) (*fetchedPage, error) {
+	if p.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.fetchTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	if stored != nil {
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && stored != nil {
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := p.client.ReadBody(resp)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	bodyStr := string(body)
+	return &fetchedPage{
+		body:         body,
+		contentType:  resp.Header.Get("Content-Type"),
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
 
-	// Check if we got an error page instead of actual HTML
-	if strings.Contains(bodyStr, "Not Acceptable") || strings.TrimSpace(bodyStr) == "" {
-		return "", fmt.Errorf("server returned error or empty response (status: %d)", resp.StatusCode)
+// PDFContentProcessor implements ContentProcessor for URLs that may point at PDFs
+// (e.g., a blog "article" that is a linked whitepaper). Responses served as application/pdf
+// or with a .pdf path are parsed as PDFs; everything else is handled like HTTPContentProcessor.
+type PDFContentProcessor struct {
+	html        *HTTPContentProcessor
+	maxPDFBytes int64 // PDFs over this size return ErrPDFTooLarge (0 = content.DefaultMaxPDFBytes, negative = no limit)
+	rawText     bool  // Store PDF text as extracted, without content.NormalizeTranscript
+	clock       clock.Clock
+}
+
+// ErrPDFTooLarge is returned by PDFContentProcessor for PDFs over the size limit, without decoding them
+var ErrPDFTooLarge = content.ErrPDFTooLarge
+
+// NewPDFContentProcessor creates a PDF-aware processor that uses html's client, fetch timeout
+// and extractor, and falls back to it for non-PDF responses
+func NewPDFContentProcessor(html *HTTPContentProcessor) *PDFContentProcessor {
+	return &PDFContentProcessor{
+		html:  html,
+		clock: clock.Real,
 	}
+}
 
-	return bodyStr, nil
+// SetRequestSemaphore limits concurrent fetches using a semaphore shared with other workers
+func (p *PDFContentProcessor) SetRequestSemaphore(sem chan struct{}) {
+	p.html.SetRequestSemaphore(sem)
+}
+
+// SetKeepRawHTML stores the fetched HTML of non-PDF responses in Article.RawHTML
+func (p *PDFContentProcessor) SetKeepRawHTML(keep bool) {
+	p.html.SetKeepRawHTML(keep)
+}
+
+// SetMaxPDFBytes sets the largest PDF that is decoded; bigger ones return ErrPDFTooLarge
+// Zero means content.DefaultMaxPDFBytes; a negative value disables the limit
+func (p *PDFContentProcessor) SetMaxPDFBytes(n int64) {
+	p.maxPDFBytes = n
+}
+
+// SetKeepRawText stores PDF text exactly as extracted; by default it is cleaned up with
+// content.NormalizeTranscript (hyphenated line breaks, page headers, repeated whitespace)
+func (p *PDFContentProcessor) SetKeepRawText(keep bool) {
+	p.rawText = keep
+}
+
+// SetHeadPrecheck sends a HEAD request before each GET, like HTTPContentProcessor, but also
+// lets PDFs through
+func (p *PDFContentProcessor) SetHeadPrecheck(enabled bool) {
+	p.html.SetHeadPrecheck(enabled)
+}
+
+// SetMinTextLength rejects HTML pages and PDFs whose extracted text has fewer than n characters
+func (p *PDFContentProcessor) SetMinTextLength(n int) {
+	p.html.SetMinTextLength(n)
+}
+
+// SetSoftNotFoundMarkers replaces the texts that mark an HTML response as an error page
+func (p *PDFContentProcessor) SetSoftNotFoundMarkers(markers []string) {
+	p.html.SetSoftNotFoundMarkers(markers)
+}
+
+// SetPreferCanonical stores HTML pages under their same-host canonical URL; PDFs have none
+func (p *PDFContentProcessor) SetPreferCanonical(prefer bool) {
+	p.html.SetPreferCanonical(prefer)
+}
+
+// SetArticleLookup enables conditional GETs for stored articles, like HTTPContentProcessor
+func (p *PDFContentProcessor) SetArticleLookup(lookup ArticleLookup) {
+	p.html.SetArticleLookup(lookup)
+}
+
+// SetClock sets the clock that stamps Article.CrawledAt, for PDFs and HTML pages alike
+func (p *PDFContentProcessor) SetClock(c clock.Clock) {
+	p.clock = c
+	p.html.SetClock(c)
 }
 
-// DBContentSaver implements ContentSaver by saving articles to a MongoDB database
+// SetClientType switches the headers sent with fetches to those of clientType
+func (p *PDFContentProcessor) SetClientType(clientType httpclient.ClientType) {
+	p.html.SetClientType(clientType)
+}
+
+// SetClientOptions replaces the options of the client that fetches PDFs and HTML pages alike
+func (p *PDFContentProcessor) SetClientOptions(opts httpclient.ClientOptions) {
+	p.html.SetClientOptions(opts)
+}
+
+// ProcessContent fetches the URL once and builds the Article from the PDF text or the HTML
+func (p *PDFContentProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	if err := acquireRequestSlot(ctx, p.html.requestSem); err != nil {
+		return nil, err
+	}
+	if p.html.headPrecheck {
+		accept := func(contentType string) bool { return isHTML(contentType) || isPDF(url, contentType) }
+		if err := p.html.checkHead(ctx, url, accept); err != nil {
+			releaseRequestSlot(p.html.requestSem)
+			return nil, err
+		}
+	}
+	fetchStart := time.Now()
+	page, err := p.html.fetchPage(ctx, url, p.html.storedArticle(ctx, url))
+	metrics.FetchLatency.ObserveSince(fetchStart)
+	releaseRequestSlot(p.html.requestSem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch content: %w", err)
+	}
+
+	if !isPDF(url, page.contentType) {
+		htmlContent, err := htmlFromBody(page.body, p.html.softNotFound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch HTML: %w", err)
+		}
+		article, err := p.html.articleFromHTML(url, htmlContent)
+		if err != nil {
+			return nil, err
+		}
+		page.setValidators(article)
+		return article, nil
+	}
+
+	text, err := content.ExtractTextFromPDFReaderWithLimit(bytes.NewReader(page.body), p.maxPDFBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+	}
+	if !p.rawText {
+		text = content.NormalizeTranscript(text)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("no text found in PDF")
+	}
+	if err := content.CheckTextLength(text, p.html.minTextLen); err != nil {
+		return nil, err
+	}
+
+	article := &domain.Article{
+		URL:       urls.NormalizeOrRaw(url),
+		Host:      domain.HostFromURL(url),
+		Title:     pdfTitle(url),
+		Text:      text,
+		CrawledAt: p.clock.Now(),
+
+		ContentHash: domain.ContentHash(text),
+	}
+	page.setValidators(article)
+	return article, nil
+}
+
+// isPDF reports whether a response should be parsed as a PDF, based on its Content-Type or URL path
+func isPDF(rawURL, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "application/pdf" {
+		return true
+	}
+	parsed, err := url.Parse(rawURL)
+	return err == nil && strings.EqualFold(path.Ext(parsed.Path), ".pdf")
+}
+
+// pdfTitle derives a title from the PDF's file name, e.g. "/papers/kafka-internals.pdf" -> "kafka-internals"
+func pdfTitle(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	name := path.Base(parsed.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return rawURL
+	}
+	return name
+}
+
+// FeedContentProcessor implements ContentProcessor for feeds that embed the full post
+// (e.g., WordPress <content:encoded>). When the feed item recorded for a URL has content,
+// the Article is built from it without a fetch; otherwise the fallback processor is used.
+type FeedContentProcessor struct {
+	fallback    ContentProcessor
+	feedItems   *FeedItemIndex // Set by the pipeline
+	keepRawHTML bool
+	clock       clock.Clock
+}
+
+// NewFeedContentProcessor creates a processor that uses feed content when available and fallback otherwise
+func NewFeedContentProcessor(fallback ContentProcessor) *FeedContentProcessor {
+	return &FeedContentProcessor{
+		fallback: fallback,
+		clock:    clock.Real,
+	}
+}
+
+// SetFeedItemIndex sets the index the feed content is read from
+func (p *FeedContentProcessor) SetFeedItemIndex(index *FeedItemIndex) {
+	p.feedItems = index
+}
+
+// SetRequestSemaphore forwards the pipeline's request semaphore to the fallback processor
+func (p *FeedContentProcessor) SetRequestSemaphore(sem chan struct{}) {
+	if limited, ok := p.fallback.(RequestLimited); ok {
+		limited.SetRequestSemaphore(sem)
+	}
+}
+
+// SetHeadPrecheck forwards the HEAD precheck setting to the fallback processor
+func (p *FeedContentProcessor) SetHeadPrecheck(enabled bool) {
+	if prechecker, ok := p.fallback.(HeadPrechecker); ok {
+		prechecker.SetHeadPrecheck(enabled)
+	}
+}
+
+// SetMinTextLength forwards the minimum text length to the fallback processor
+func (p *FeedContentProcessor) SetMinTextLength(n int) {
+	if setter, ok := p.fallback.(MinTextLengthSetter); ok {
+		setter.SetMinTextLength(n)
+	}
+}
+
+// SetSoftNotFoundMarkers forwards the error page markers to the fallback processor
+func (p *FeedContentProcessor) SetSoftNotFoundMarkers(markers []string) {
+	if detector, ok := p.fallback.(SoftNotFoundDetector); ok {
+		detector.SetSoftNotFoundMarkers(markers)
+	}
+}
+
+// SetPreferCanonical forwards the canonical URL setting to the fallback processor
+func (p *FeedContentProcessor) SetPreferCanonical(prefer bool) {
+	if preferrer, ok := p.fallback.(CanonicalPreferrer); ok {
+		preferrer.SetPreferCanonical(prefer)
+	}
+}
+
+// SetArticleLookup forwards conditional GET support to the fallback processor
+func (p *FeedContentProcessor) SetArticleLookup(lookup ArticleLookup) {
+	if fetcher, ok := p.fallback.(ConditionalFetcher); ok {
+		fetcher.SetArticleLookup(lookup)
+	}
+}
+
+// SetClock sets the clock that stamps Article.CrawledAt, and forwards it to the fallback processor
+func (p *FeedContentProcessor) SetClock(c clock.Clock) {
+	p.clock = c
+	if setter, ok := p.fallback.(ClockSetter); ok {
+		setter.SetClock(c)
+	}
+}
+
+// SetClientType forwards the client type to the fallback processor
+func (p *FeedContentProcessor) SetClientType(clientType httpclient.ClientType) {
+	if setter, ok := p.fallback.(ClientTypeSetter); ok {
+		setter.SetClientType(clientType)
+	}
+}
+
+// SetClientOptions forwards the client options to the fallback processor
+func (p *FeedContentProcessor) SetClientOptions(opts httpclient.ClientOptions) {
+	if setter, ok := p.fallback.(ClientOptionsSetter); ok {
+		setter.SetClientOptions(opts)
+	}
+}
+
+// SetKeepRawHTML stores the feed content (or the fetched HTML, if the fallback supports it) in Article.RawHTML
+func (p *FeedContentProcessor) SetKeepRawHTML(keep bool) {
+	p.keepRawHTML = keep
+	if keeper, ok := p.fallback.(RawHTMLKeeper); ok {
+		keeper.SetKeepRawHTML(keep)
+	}
+}
+
+// ProcessContent builds the Article from the feed item's content, or calls the fallback processor
+func (p *FeedContentProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	item, ok := p.feedItems.Get(url)
+	if !ok || item.Content == "" {
+		return p.fallback.ProcessContent(ctx, url)
+	}
+
+	text, err := feedContentText(item.Content)
+	if err != nil || text == "" {
+		log.Printf("FeedContentProcessor: No usable feed content for %s, fetching it instead", url)
+		return p.fallback.ProcessContent(ctx, url)
+	}
+
+	article := &domain.Article{
+		URL:       urls.NormalizeOrRaw(url),
+		Host:      domain.HostFromURL(url),
+		Title:     item.Title,
+		Text:      text,
+		Summary:   item.Summary,
+		Tags:      content.ExtractTags(item.Content),
+		CrawledAt: p.clock.Now(),
+
+		ContentHash: domain.ContentHash(text),
+	}
+	if p.keepRawHTML {
+		article.RawHTML = item.Content
+	}
+	return article, nil
+}
+
+// feedContentText converts a feed's post HTML to plain text
+func feedContentText(htmlContent string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse feed content: %w", err)
+	}
+	return strings.TrimSpace(doc.Text()), nil
+}
+
+// RetryingContentProcessor wraps a ContentProcessor and retries transient failures (see isTransient)
+// with exponential backoff; permanent ones such as 404s or parse failures are returned right away
+type RetryingContentProcessor struct {
+	inner      ContentProcessor
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewRetryingContentProcessor creates a processor that retries inner up to maxRetries times,
+// waiting backoff before the first retry and doubling it after each one
+func NewRetryingContentProcessor(inner ContentProcessor, maxRetries int, backoff time.Duration) *RetryingContentProcessor {
+	return &RetryingContentProcessor{
+		inner:      inner,
+		maxRetries: maxRetries,
+		backoff:    backoff,
+	}
+}
+
+// SetRequestSemaphore forwards the pipeline's request semaphore to the wrapped processor
+func (p *RetryingContentProcessor) SetRequestSemaphore(sem chan struct{}) {
+	if limited, ok := p.inner.(RequestLimited); ok {
+		limited.SetRequestSemaphore(sem)
+	}
+}
+
+// SetKeepRawHTML forwards the setting to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetKeepRawHTML(keep bool) {
+	if keeper, ok := p.inner.(RawHTMLKeeper); ok {
+		keeper.SetKeepRawHTML(keep)
+	}
+}
+
+// SetHeadPrecheck forwards the HEAD precheck setting to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetHeadPrecheck(enabled bool) {
+	if prechecker, ok := p.inner.(HeadPrechecker); ok {
+		prechecker.SetHeadPrecheck(enabled)
+	}
+}
+
+// SetMinTextLength forwards the minimum text length to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetMinTextLength(n int) {
+	if setter, ok := p.inner.(MinTextLengthSetter); ok {
+		setter.SetMinTextLength(n)
+	}
+}
+
+// SetSoftNotFoundMarkers forwards the error page markers to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetSoftNotFoundMarkers(markers []string) {
+	if detector, ok := p.inner.(SoftNotFoundDetector); ok {
+		detector.SetSoftNotFoundMarkers(markers)
+	}
+}
+
+// SetPreferCanonical forwards the canonical URL setting to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetPreferCanonical(prefer bool) {
+	if preferrer, ok := p.inner.(CanonicalPreferrer); ok {
+		preferrer.SetPreferCanonical(prefer)
+	}
+}
+
+// SetArticleLookup forwards conditional GET support to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetArticleLookup(lookup ArticleLookup) {
+	if fetcher, ok := p.inner.(ConditionalFetcher); ok {
+		fetcher.SetArticleLookup(lookup)
+	}
+}
+
+// SetClock forwards the clock to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetClock(c clock.Clock) {
+	if setter, ok := p.inner.(ClockSetter); ok {
+		setter.SetClock(c)
+	}
+}
+
+// SetClientType forwards the client type to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetClientType(clientType httpclient.ClientType) {
+	if setter, ok := p.inner.(ClientTypeSetter); ok {
+		setter.SetClientType(clientType)
+	}
+}
+
+// SetClientOptions forwards the client options to the wrapped processor if it supports it
+func (p *RetryingContentProcessor) SetClientOptions(opts httpclient.ClientOptions) {
+	if setter, ok := p.inner.(ClientOptionsSetter); ok {
+		setter.SetClientOptions(opts)
+	}
+}
+
+// ProcessContent calls the wrapped processor, retrying transient errors until it succeeds,
+// the retries are used up, or the context is cancelled
+func (p *RetryingContentProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	delay := p.backoff
+
+	for attempt := 0; ; attempt++ {
+		article, err := p.inner.ProcessContent(ctx, url)
+		if err == nil {
+			return article, nil
+		}
+		if attempt >= p.maxRetries || ctx.Err() != nil || !isTransient(err) {
+			return nil, err
+		}
+
+		log.Printf("RetryingContentProcessor: Attempt %d for %s failed, retrying in %s: %v", attempt+1, url, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("retry cancelled: %w (last error: %v)", ctx.Err(), err)
+		}
+		delay *= 2
+	}
+}
+
+// isTransient reports whether a failed fetch may succeed when retried: throttling (429), server
+// errors (5xx), timeouts and connection resets
+func isTransient(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// DBContentSaver implements ContentSaver by saving articles to an article store (MongoDB in production)
 type DBContentSaver struct {
-	dbClient *db.Client
+	store db.ArticleStore
 }
 
 // NewDBContentSaver creates a new database content saver
-func NewDBContentSaver(dbClient *db.Client) *DBContentSaver {
+func NewDBContentSaver(store db.ArticleStore) *DBContentSaver {
 	return &DBContentSaver{
-		dbClient: dbClient,
+		store: store,
 	}
 }
 
-// SaveArticle saves an article to the database
+// SaveArticle saves an article to the store, retrying once after a dropped connection if the store supports it
 func (s *DBContentSaver) SaveArticle(ctx context.Context, article *domain.Article) error {
-	return s.dbClient.SaveArticle(ctx, article)
+	return db.SaveWithRetry(ctx, s.store, article)
+}
+
+// InMemoryContentSaver implements ContentSaver by keeping articles in memory
+// Useful for tests and for embedding the pipeline without a database
+type InMemoryContentSaver struct {
+	mu       sync.Mutex
+	articles []*domain.Article
+}
+
+// NewInMemoryContentSaver creates an empty in-memory content saver
+func NewInMemoryContentSaver() *InMemoryContentSaver {
+	return &InMemoryContentSaver{}
+}
+
+// SaveArticle appends the article; safe for concurrent use
+func (s *InMemoryContentSaver) SaveArticle(ctx context.Context, article *domain.Article) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.articles = append(s.articles, article)
+	return nil
+}
+
+// Articles returns a copy of the saved articles in save order
+func (s *InMemoryContentSaver) Articles() []*domain.Article {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*domain.Article(nil), s.articles...)
+}
+
+// ContentHashChecker checks whether an article with the same content is already stored under another URL
+// db.Client implements this interface
+type ContentHashChecker interface {
+	ArticleExistsByHash(ctx context.Context, hash, excludeURL string) (bool, error)
+}
+
+// ErrDuplicateContent is returned by HashDedupContentSaver for an article whose text is already
+// stored under another URL; the article isn't saved
+var ErrDuplicateContent = errors.New("content already stored under another URL")
+
+// HashDedupContentSaver wraps a ContentSaver and skips saving articles whose content hash
+// is already stored (e.g., the same post served under a canonical and an AMP URL)
+type HashDedupContentSaver struct {
+	saver   ContentSaver
+	checker ContentHashChecker
+}
+
+// NewHashDedupContentSaver creates a content saver that skips duplicate content
+func NewHashDedupContentSaver(saver ContentSaver, checker ContentHashChecker) *HashDedupContentSaver {
+	return &HashDedupContentSaver{
+		saver:   saver,
+		checker: checker,
+	}
+}
+
+// SaveArticle saves the article unless another URL's article has the same content hash, in which
+// case it returns ErrDuplicateContent
+// An article stored under its own URL is saved again (e.g., a re-crawl)
+func (s *HashDedupContentSaver) SaveArticle(ctx context.Context, article *domain.Article) error {
+	hash := article.ContentHash
+	if hash == "" {
+		hash = domain.ContentHash(article.Text)
+	}
+
+	exists, err := s.checker.ArticleExistsByHash(ctx, hash, article.URL)
+	if err != nil {
+		return fmt.Errorf("failed to check content hash: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateContent, article.URL)
+	}
+
+	return s.saver.SaveArticle(ctx, article)
+}
+
+// MultiContentSaver implements ContentSaver by saving each article to several savers
+// (e.g., MongoDB and the filesystem in one run)
+type MultiContentSaver struct {
+	savers []ContentSaver
+}
+
+// NewMultiContentSaver creates a content saver that fans out to all given savers
+func NewMultiContentSaver(savers ...ContentSaver) *MultiContentSaver {
+	return &MultiContentSaver{
+		savers: savers,
+	}
+}
+
+// SaveArticle saves the article with every saver, even if earlier ones fail
+// Returns the joined errors of all failing savers, or nil if all succeeded
+func (s *MultiContentSaver) SaveArticle(ctx context.Context, article *domain.Article) error {
+	var errs []error
+	for i, saver := range s.savers {
+		if err := saver.SaveArticle(ctx, article); err != nil {
+			errs = append(errs, fmt.Errorf("saver %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
 }
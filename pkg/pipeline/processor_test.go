@@ -1,12 +1,25 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
+	"blog-search/pkg/clock"
+	"blog-search/pkg/content"
+	"blog-search/pkg/db"
+	"blog-search/pkg/domain"
+	"blog-search/pkg/httpclient"
 )
 
 
@@ -83,9 +96,148 @@ func TestHTTPContentProcessor_ProcessContent_NotAcceptable(t *testing.T) {
 	}
 }
 
-// Note: DBContentSaver tests would require a real database connection or refactoring
-// db.Client to use an interface. For now, DBContentSaver is a thin wrapper that
-// delegates to db.Client.SaveArticle, so it's tested indirectly through integration tests.
+func TestHTTPContentProcessor_ProcessContent_FallsBackToBrowserOn403(t *testing.T) {
+	// Like a site that blocks curl-like User-Agents but lets browsers through
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("User-Agent"), "curl/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("<html><head><title>Kafka</title></head><body><article><p>" +
+			strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p></article></body></html>"))
+	}))
+	defer server.Close()
+
+	article, err := NewHTTPContentProcessor().ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected the browser fallback to fetch the page, got %v", err)
+	}
+	if article.Title != "Kafka" {
+		t.Errorf("Expected the page's article, got title %q", article.Title)
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_SoftNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<html><head><title>404 - Not Found</title></head><body><h1>404 - Not Found</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	article, err := NewHTTPContentProcessor().ProcessContent(context.Background(), server.URL)
+	if !errors.Is(err, ErrSoftNotFound) {
+		t.Fatalf("Expected ErrSoftNotFound for a 200 error page, got %v", err)
+	}
+	if article != nil {
+		t.Fatal("Expected nil article for a soft 404")
+	}
+}
+
+// mockArticleStore is a db.ArticleStore that records saved articles
+type mockArticleStore struct {
+	saved   []*domain.Article
+	saveErr error
+}
+
+func (m *mockArticleStore) SaveArticle(ctx context.Context, article *domain.Article) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.saved = append(m.saved, article)
+	return nil
+}
+
+func (m *mockArticleStore) GetAllURLs(ctx context.Context) (map[string]bool, error) {
+	urls := make(map[string]bool)
+	for _, article := range m.saved {
+		urls[article.URL] = true
+	}
+	return urls, nil
+}
+
+func (m *mockArticleStore) GetExistingArticleURLs(ctx context.Context, candidates []string) (map[string]bool, error) {
+	all, _ := m.GetAllURLs(ctx)
+	found := make(map[string]bool)
+	for _, url := range candidates {
+		if all[url] {
+			found[url] = true
+		}
+	}
+	return found, nil
+}
+
+// retryingArticleStore is a mockArticleStore that also supports db.RetryingSaver
+type retryingArticleStore struct {
+	mockArticleStore
+	retries int
+}
+
+func (m *retryingArticleStore) SaveArticleWithRetry(ctx context.Context, article *domain.Article) error {
+	m.retries++
+	return m.SaveArticle(ctx, article)
+}
+
+func TestDBContentSaver_DelegatesToStore(t *testing.T) {
+	store := &mockArticleStore{}
+	saver := NewDBContentSaver(store)
+
+	article := &domain.Article{URL: "https://example.com/post", Text: "Body"}
+	if err := saver.SaveArticle(context.Background(), article); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	if len(store.saved) != 1 || store.saved[0] != article {
+		t.Errorf("Expected the article to reach the store, got %v", store.saved)
+	}
+
+	store.saveErr = errors.New("write conflict")
+	if err := saver.SaveArticle(context.Background(), article); !errors.Is(err, store.saveErr) {
+		t.Errorf("Expected the store's error, got %v", err)
+	}
+}
+
+func TestDBContentSaver_UsesRetryWhenSupported(t *testing.T) {
+	store := &retryingArticleStore{}
+	saver := NewDBContentSaver(store)
+
+	if err := saver.SaveArticle(context.Background(), &domain.Article{URL: "https://example.com/post"}); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	if store.retries != 1 || len(store.saved) != 1 {
+		t.Errorf("Expected the save to go through SaveArticleWithRetry, got %d retrying saves and %d saved", store.retries, len(store.saved))
+	}
+}
+
+func TestInMemoryContentSaver_ConcurrentSaves(t *testing.T) {
+	saver := NewInMemoryContentSaver()
+
+	const goroutines, perGoroutine = 8, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				article := &domain.Article{URL: fmt.Sprintf("https://example.com/%d/%d", g, i)}
+				if err := saver.SaveArticle(context.Background(), article); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	articles := saver.Articles()
+	if len(articles) != goroutines*perGoroutine {
+		t.Fatalf("Expected %d articles, got %d", goroutines*perGoroutine, len(articles))
+	}
+	seen := make(map[string]bool)
+	for _, article := range articles {
+		if seen[article.URL] {
+			t.Errorf("Article %s saved twice", article.URL)
+		}
+		seen[article.URL] = true
+	}
+}
 
 func TestHTTPContentProcessor_ProcessContent_Integration(t *testing.T) {
 	if testing.Short() {
@@ -134,9 +286,9 @@ func TestHTTPContentProcessor_ProcessContent_Integration(t *testing.T) {
 		t.Fatal("ProcessContent returned nil article")
 	}
 
-	// Verify article fields
-	if article.URL != server.URL {
-		t.Errorf("Expected URL %s, got %s", server.URL, article.URL)
+	// Verify article fields; the bare host is stored in normalized form, with the root path
+	if article.URL != server.URL+"/" {
+		t.Errorf("Expected URL %s/, got %s", server.URL, article.URL)
 	}
 
 	if !strings.Contains(article.Title, "Flavia Saldanha") {
@@ -156,3 +308,823 @@ func TestHTTPContentProcessor_ProcessContent_Integration(t *testing.T) {
 		t.Error("Expected text to contain 'data engineering'")
 	}
 }
+
+// mockHashChecker reports the configured hashes as stored under the given URLs
+type mockHashChecker struct {
+	stored map[string]string // Content hash -> URL it is stored under
+}
+
+func (m *mockHashChecker) ArticleExistsByHash(ctx context.Context, hash, excludeURL string) (bool, error) {
+	url, ok := m.stored[hash]
+	return ok && url != excludeURL, nil
+}
+
+func TestHashDedupContentSaver_SkipsDuplicateContent(t *testing.T) {
+	saver := &mockContentSaver{}
+	checker := &mockHashChecker{stored: map[string]string{
+		domain.ContentHash("Already stored body"): "https://example.com/post",
+	}}
+	dedup := NewHashDedupContentSaver(saver, checker)
+	ctx := context.Background()
+
+	duplicate := &domain.Article{URL: "https://example.com/post/amp", Text: "Already  stored\nbody"}
+	if err := dedup.SaveArticle(ctx, duplicate); !errors.Is(err, ErrDuplicateContent) {
+		t.Fatalf("Expected ErrDuplicateContent, got %v", err)
+	}
+
+	fresh := &domain.Article{URL: "https://example.com/other", Text: "New body"}
+	if err := dedup.SaveArticle(ctx, fresh); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A re-crawl of the stored URL isn't a duplicate of itself
+	recrawl := &domain.Article{URL: "https://example.com/post", Text: "Already stored body"}
+	if err := dedup.SaveArticle(ctx, recrawl); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(saver.savedArticles) != 2 || saver.savedArticles[0].URL != fresh.URL || saver.savedArticles[1].URL != recrawl.URL {
+		t.Errorf("Expected the fresh article and the re-crawl to be saved, got %v", saver.savedArticles)
+	}
+}
+
+func TestMultiContentSaver_SavesToAllAndJoinsErrors(t *testing.T) {
+	failing := &mockContentSaver{err: errors.New("disk full")}
+	working := &mockContentSaver{}
+	multi := NewMultiContentSaver(failing, working)
+
+	article := &domain.Article{URL: "https://example.com/post", Text: "Body"}
+	err := multi.SaveArticle(context.Background(), article)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Expected the failing saver's error to surface, got %v", err)
+	}
+	if len(working.savedArticles) != 1 || working.savedArticles[0] != article {
+		t.Errorf("Expected the other saver to still save the article, got %v", working.savedArticles)
+	}
+	if failing.callCount != 1 {
+		t.Errorf("Expected the failing saver to be called once, got %d", failing.callCount)
+	}
+}
+
+// flakyProcessor fails the first failures calls, then returns an article
+type flakyProcessor struct {
+	failures int
+	calls    int
+	err      error // Returned for failed calls; defaults to a 503 error
+}
+
+func (m *flakyProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		if m.err != nil {
+			return nil, m.err
+		}
+		return nil, &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+	}
+	return &domain.Article{URL: url, Title: "Recovered"}, nil
+}
+
+func TestRetryingContentProcessor_SucceedsOnThirdTry(t *testing.T) {
+	inner := &flakyProcessor{failures: 2}
+	processor := NewRetryingContentProcessor(inner, 3, time.Millisecond)
+
+	article, err := processor.ProcessContent(context.Background(), "https://example.com/post")
+	if err != nil {
+		t.Fatalf("Expected success after retries, got: %v", err)
+	}
+	if article == nil || article.Title != "Recovered" {
+		t.Errorf("Expected the recovered article, got %v", article)
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected exactly 3 calls, got %d", inner.calls)
+	}
+}
+
+func TestRetryingContentProcessor_GivesUpAfterMaxRetries(t *testing.T) {
+	inner := &flakyProcessor{failures: 10}
+	processor := NewRetryingContentProcessor(inner, 2, time.Millisecond)
+
+	if _, err := processor.ProcessContent(context.Background(), "https://example.com/post"); err == nil {
+		t.Fatal("Expected error after retries are exhausted")
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected 1 attempt plus 2 retries, got %d calls", inner.calls)
+	}
+}
+
+func TestRetryingContentProcessor_StopsOnCancelledContext(t *testing.T) {
+	inner := &flakyProcessor{failures: 10}
+	processor := NewRetryingContentProcessor(inner, 5, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := processor.ProcessContent(ctx, "https://example.com/post"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d calls", inner.calls)
+	}
+}
+
+// slowServer responds only after delay, or gives up when the client goes away
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.Write([]byte("<html><body><p>late</p></body></html>"))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPContentProcessor_ProcessContent_ContextDeadlineAbortsFetch(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+	processor := NewHTTPContentProcessor()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := processor.ProcessContent(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected fetch to abort promptly, took %v", elapsed)
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_FetchTimeout(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+	processor := NewHTTPContentProcessor()
+	processor.SetFetchTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := processor.ProcessContent(context.Background(), server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected per-request timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected fetch to time out promptly, took %v", elapsed)
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_KeepRawHTML(t *testing.T) {
+	page := "<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1><p>" +
+		strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p></article></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	processor := NewHTTPContentProcessor()
+	article, err := processor.ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.RawHTML != "" {
+		t.Error("Expected no raw HTML by default")
+	}
+
+	p := NewPipeline([]PipelineStep{{Name: "Generator", WorkerCount: 1, Generator: &mockURLGenerator{}}},
+		ContentConsumer{ContentProcessor: NewRetryingContentProcessor(processor, 0, 0)})
+	if !p.SetKeepRawHTML(true) {
+		t.Fatal("Expected the wrapped HTTP processor to support keeping raw HTML")
+	}
+	article, err = processor.ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.RawHTML != page {
+		t.Errorf("Expected the fetched HTML to be kept, got %d bytes", len(article.RawHTML))
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_ExtractsTags(t *testing.T) {
+	page := `<html><head><title>Kafka</title><meta property="article:tag" content="Kafka"></head>` +
+		`<body><article><h1>Kafka</h1><p>` + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + `</p>` +
+		`<a rel="tag" href="/tag/streaming">Streaming</a></article></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	article, err := NewHTTPContentProcessor().ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if !reflect.DeepEqual(article.Tags, []string{"Kafka", "Streaming"}) {
+		t.Errorf("Expected the page's tags, got %v", article.Tags)
+	}
+}
+
+func TestContentProcessors_StampCrawledAtFromClock(t *testing.T) {
+	page := "<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1><p>" +
+		strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p></article></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	crawledAt := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	fake := clock.NewFake(crawledAt)
+
+	httpProcessor := NewHTTPContentProcessor()
+	index := NewFeedItemIndex()
+	index.Set("https://example.com/full", FeedItem{Title: "Full Post", Content: "<p>The whole post.</p>"})
+	feedProcessor := NewFeedContentProcessor(NewPDFContentProcessor(httpProcessor))
+	feedProcessor.SetFeedItemIndex(index)
+
+	// Set through the retrying wrapper, so it reaches the feed processor and its fallbacks
+	NewRetryingContentProcessor(feedProcessor, 0, 0).SetClock(fake)
+
+	for _, url := range []string{"https://example.com/full", server.URL} {
+		article, err := feedProcessor.ProcessContent(context.Background(), url)
+		if err != nil {
+			t.Fatalf("ProcessContent(%s) failed: %v", url, err)
+		}
+		if !article.CrawledAt.Equal(crawledAt) {
+			t.Errorf("%s: expected CrawledAt %v, got %v", url, crawledAt, article.CrawledAt)
+		}
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_MinTextLength(t *testing.T) {
+	page := `<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1><p>` +
+		strings.Repeat("Partitions let consumers scale horizontally. ", 20) + `</p></article></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	processor := NewHTTPContentProcessor()
+	processor.SetMinTextLength(5000)
+	if _, err := processor.ProcessContent(context.Background(), server.URL); !errors.Is(err, ErrContentTooShort) {
+		t.Errorf("Expected ErrContentTooShort for text under the minimum, got %v", err)
+	}
+
+	processor.SetMinTextLength(100)
+	article, err := processor.ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected text over the minimum to be accepted, got %v", err)
+	}
+	if len(article.Text) < 100 {
+		t.Errorf("Expected the extracted text, got %q", article.Text)
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_PreferCanonical(t *testing.T) {
+	body := `<body><article><h1>Kafka</h1><p>` + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + `</p></article></body></html>`
+	var canonical string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Kafka</title><link rel="canonical" href="` + canonical + `"></head>` + body))
+	}))
+	defer server.Close()
+
+	processor := NewHTTPContentProcessor()
+	processor.SetPreferCanonical(true)
+
+	// Same host: the tracking URL is replaced by the canonical one
+	canonical = "/posts/kafka"
+	article, err := processor.ProcessContent(context.Background(), server.URL+"/posts/kafka?ref=rss")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.URL != server.URL+"/posts/kafka" {
+		t.Errorf("Expected the canonical URL, got %s", article.URL)
+	}
+
+	// Another host: the fetched URL is kept
+	canonical = "https://syndication.example.org/posts/kafka"
+	article, err = processor.ProcessContent(context.Background(), server.URL+"/posts/kafka?ref=rss")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.URL != server.URL+"/posts/kafka?ref=rss" {
+		t.Errorf("Expected a cross-host canonical to be ignored, got %s", article.URL)
+	}
+}
+
+func TestHTTPContentProcessor_ProcessContent_BodyOverLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>" + strings.Repeat("x", 4096) + "</p></body></html>"))
+	}))
+	defer server.Close()
+
+	processor := NewHTTPContentProcessorWithClientOptions(httpclient.CloudflareClient, httpclient.ClientOptions{MaxBodyBytes: 1024})
+
+	_, err := processor.ProcessContent(context.Background(), server.URL)
+	if !errors.Is(err, httpclient.ErrBodyTooLarge) {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+// buildPDF builds a minimal single-page PDF showing text in Helvetica
+func buildPDF(t *testing.T, text string) []byte {
+	t.Helper()
+
+	stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf strings.Builder
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return []byte(buf.String())
+}
+
+func TestPDFContentProcessor_ProcessContent(t *testing.T) {
+	pdfBody := buildPDF(t, "Exactly-once delivery in Kafka")
+	htmlBody := "<html><head><title>Kafka Streams</title></head><body><article><h1>Kafka Streams</h1><p>" +
+		strings.Repeat("Stream processing builds on consumer groups. ", 20) + "</p></article></body></html>"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdfBody)
+	})
+	mux.HandleFunc("/papers/kafka-internals.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(pdfBody)
+	})
+	mux.HandleFunc("/blog/streams", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(htmlBody))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	processor := NewPDFContentProcessor(NewHTTPContentProcessor())
+	ctx := context.Background()
+
+	for _, path := range []string{"/download", "/papers/kafka-internals.pdf"} {
+		article, err := processor.ProcessContent(ctx, server.URL+path)
+		if err != nil {
+			t.Fatalf("%s: ProcessContent failed: %v", path, err)
+		}
+		if !strings.Contains(article.Text, "Exactly-once delivery in Kafka") {
+			t.Errorf("%s: Expected PDF text in article, got %q", path, article.Text)
+		}
+		if article.ContentHash == "" {
+			t.Errorf("%s: Expected content hash to be set", path)
+		}
+	}
+
+	article, err := processor.ProcessContent(ctx, server.URL+"/papers/kafka-internals.pdf")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.Title != "kafka-internals" {
+		t.Errorf("Expected title from the file name, got %q", article.Title)
+	}
+
+	article, err = processor.ProcessContent(ctx, server.URL+"/blog/streams")
+	if err != nil {
+		t.Fatalf("HTML fallback failed: %v", err)
+	}
+	if article.Title != "Kafka Streams" || !strings.Contains(article.Text, "consumer groups") {
+		t.Errorf("Expected HTML article, got title %q text %q", article.Title, article.Text)
+	}
+}
+
+func TestPDFContentProcessor_ProcessContent_MaxPDFBytes(t *testing.T) {
+	pdfBody := buildPDF(t, "Exactly-once delivery in Kafka")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdfBody)
+	}))
+	defer server.Close()
+
+	processor := NewPDFContentProcessor(NewHTTPContentProcessor())
+	processor.SetMaxPDFBytes(int64(len(pdfBody)) - 1)
+	if _, err := processor.ProcessContent(context.Background(), server.URL); !errors.Is(err, ErrPDFTooLarge) {
+		t.Errorf("Expected ErrPDFTooLarge, got %v", err)
+	}
+}
+
+func TestPDFContentProcessor_ProcessContent_NormalizesText(t *testing.T) {
+	pdfBody := buildPDF(t, "Data    engineering    at scale")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdfBody)
+	}))
+	defer server.Close()
+
+	processor := NewPDFContentProcessor(NewHTTPContentProcessor())
+	article, err := processor.ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	raw, err := content.ExtractTextFromPDFReader(bytes.NewReader(pdfBody))
+	if err != nil {
+		t.Fatalf("ExtractTextFromPDFReader failed: %v", err)
+	}
+	if article.Text != "Data engineering at scale" {
+		t.Errorf("Expected the normalized PDF text, got %q", article.Text)
+	}
+
+	processor.SetKeepRawText(true)
+	article, err = processor.ProcessContent(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.Text != raw {
+		t.Errorf("Expected the raw PDF text, got %q", article.Text)
+	}
+}
+
+func TestPDFContentProcessor_ForwardsHTMLOptions(t *testing.T) {
+	pdfBody := buildPDF(t, "Exactly-once delivery in Kafka")
+	paragraph := "<p>" + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p>"
+
+	var gets atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/paper.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		if r.Method == http.MethodGet {
+			w.Write(pdfBody)
+		}
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+		w.Header().Set("Content-Type", "image/png")
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Nothing here</title></head><body>" + paragraph + "</body></html>"))
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Kafka</title><link rel="canonical" href="/posts/kafka"></head><body><article>` + paragraph + `</article></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	processor := NewPDFContentProcessor(NewHTTPContentProcessor())
+	processor.SetHeadPrecheck(true)
+	processor.SetSoftNotFoundMarkers([]string{"Nothing here"})
+	processor.SetPreferCanonical(true)
+	ctx := context.Background()
+
+	// The HEAD precheck skips other resources, but not PDFs
+	if _, err := processor.ProcessContent(ctx, server.URL+"/image.png"); !errors.Is(err, ErrSkippedResource) || gets.Load() != 0 {
+		t.Errorf("Expected the image to be skipped without a GET, got %v after %d GETs", err, gets.Load())
+	}
+	if _, err := processor.ProcessContent(ctx, server.URL+"/paper.pdf"); err != nil {
+		t.Errorf("Expected the PDF to pass the HEAD precheck, got %v", err)
+	}
+
+	if _, err := processor.ProcessContent(ctx, server.URL+"/gone"); !errors.Is(err, ErrSoftNotFound) {
+		t.Errorf("Expected ErrSoftNotFound for the custom marker, got %v", err)
+	}
+
+	article, err := processor.ProcessContent(ctx, server.URL+"/post?ref=rss")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if article.URL != server.URL+"/posts/kafka" {
+		t.Errorf("Expected the canonical URL, got %s", article.URL)
+	}
+
+	// The minimum text length applies to PDFs as well as HTML pages
+	processor.SetMinTextLength(1000)
+	for _, path := range []string{"/paper.pdf", "/post"} {
+		if _, err := processor.ProcessContent(ctx, server.URL+path); !errors.Is(err, ErrContentTooShort) {
+			t.Errorf("%s: expected ErrContentTooShort, got %v", path, err)
+		}
+	}
+
+	// The pipeline reaches the options through a retrying wrapper
+	p := NewPipeline(nil, ContentConsumer{ContentProcessor: NewRetryingContentProcessor(processor, 0, 0)})
+	if !p.SetHeadPrecheck(true) || !p.SetMinTextLength(1) || !p.SetSoftNotFoundMarkers(nil) || !p.SetPreferCanonical(true) {
+		t.Error("Expected the pipeline to support every option of the PDF processor")
+	}
+}
+
+func TestFeedContentProcessor_ProcessContent(t *testing.T) {
+	fallback := &mockContentProcessor{}
+	index := NewFeedItemIndex()
+	index.Set("https://example.com/full", FeedItem{
+		Title:   "Full Post",
+		Summary: "Short teaser.",
+		Content: "<p>The whole post about <b>sharding</b>.</p>",
+	})
+	index.Set("https://example.com/teaser", FeedItem{Title: "Teaser Only", Summary: "Read more on the blog."})
+
+	processor := NewFeedContentProcessor(fallback)
+	processor.SetFeedItemIndex(index)
+	processor.SetKeepRawHTML(true)
+
+	article, err := processor.ProcessContent(context.Background(), "https://example.com/full")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if fallback.callCount != 0 {
+		t.Errorf("Expected no fetch for an item with feed content, got %d", fallback.callCount)
+	}
+	if article.Title != "Full Post" || article.Text != "The whole post about sharding." {
+		t.Errorf("Expected the article built from feed content, got title %q text %q", article.Title, article.Text)
+	}
+	if article.Summary != "Short teaser." || article.Host != "example.com" || article.ContentHash == "" {
+		t.Errorf("Expected summary, host and content hash to be set, got %+v", article)
+	}
+	if article.RawHTML != "<p>The whole post about <b>sharding</b>.</p>" {
+		t.Errorf("Expected the feed content as raw HTML, got %q", article.RawHTML)
+	}
+
+	article, err = processor.ProcessContent(context.Background(), "https://example.com/teaser")
+	if err != nil {
+		t.Fatalf("ProcessContent failed: %v", err)
+	}
+	if fallback.callCount != 1 {
+		t.Errorf("Expected the fallback to fetch an item without feed content, got %d calls", fallback.callCount)
+	}
+	if article.Title != "Test Article" {
+		t.Errorf("Expected the fallback's article, got title %q", article.Title)
+	}
+}
+
+// countingExtractor is a content.Extractor that counts extractions
+type countingExtractor struct {
+	calls atomic.Int32
+}
+
+func (e *countingExtractor) ExtractTitle(htmlContent string) (string, error) {
+	return "Title", nil
+}
+
+func (e *countingExtractor) ExtractText(htmlContent string) (string, error) {
+	e.calls.Add(1)
+	return "Text", nil
+}
+
+// mapArticleLookup is an ArticleLookup backed by a map from stored URL to article
+// Like db.Client, it falls back to the article fetched from url
+type mapArticleLookup map[string]*domain.Article
+
+func (m mapArticleLookup) GetArticleByURL(ctx context.Context, url string) (*domain.Article, error) {
+	if article, ok := m[url]; ok {
+		return article, nil
+	}
+	for _, article := range m {
+		if article.FetchedURL == url {
+			return article, nil
+		}
+	}
+	return nil, db.ErrArticleNotFound
+}
+
+// notModifiedServer serves a page with an ETag and Last-Modified, and 304 to requests carrying either
+func notModifiedServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == "Mon, 15 Jan 2024 10:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 15 Jan 2024 10:00:00 GMT")
+		w.Write([]byte("<html><body><article><p>Hello</p></article></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPContentProcessor_ConditionalGet_SkipsUnchangedPages(t *testing.T) {
+	var requests atomic.Int32
+	server := notModifiedServer(t, &requests)
+	pageURL := server.URL + "/post"
+
+	extractor := &countingExtractor{}
+	lookup := mapArticleLookup{}
+	processor := NewHTTPContentProcessorWithExtractor(extractor)
+	processor.SetArticleLookup(lookup)
+
+	article, err := processor.ProcessContent(context.Background(), pageURL)
+	if err != nil {
+		t.Fatalf("First fetch failed: %v", err)
+	}
+	if article.ETag != `"v1"` || article.LastModified != "Mon, 15 Jan 2024 10:00:00 GMT" {
+		t.Fatalf("Expected the response validators on the article, got ETag %q Last-Modified %q", article.ETag, article.LastModified)
+	}
+	lookup[article.URL] = article
+
+	_, err = processor.ProcessContent(context.Background(), pageURL)
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("Expected ErrNotModified on re-crawl, got %v", err)
+	}
+	if calls := extractor.calls.Load(); calls != 1 {
+		t.Errorf("Expected no re-extraction after a 304, got %d extractions", calls)
+	}
+
+	// Last-Modified alone is enough for a conditional GET
+	lookup[article.URL] = &domain.Article{URL: article.URL, LastModified: article.LastModified}
+	if _, err := processor.ProcessContent(context.Background(), pageURL); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified with only Last-Modified stored, got %v", err)
+	}
+}
+
+func TestHTTPContentProcessor_ConditionalGet_FindsArticleStoredUnderCanonicalURL(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<html><head><link rel="canonical" href="/posts/kafka"></head><body><article><p>Hello</p></article></body></html>`))
+	}))
+	defer server.Close()
+	fetchedURL := server.URL + "/posts/kafka?ref=rss"
+
+	lookup := mapArticleLookup{}
+	processor := NewHTTPContentProcessorWithExtractor(&countingExtractor{})
+	processor.SetPreferCanonical(true)
+	processor.SetArticleLookup(lookup)
+
+	article, err := processor.ProcessContent(context.Background(), fetchedURL)
+	if err != nil {
+		t.Fatalf("First fetch failed: %v", err)
+	}
+	if article.URL != server.URL+"/posts/kafka" || article.FetchedURL != fetchedURL {
+		t.Fatalf("Expected the canonical URL with the fetched URL kept, got %q and %q", article.URL, article.FetchedURL)
+	}
+	lookup[article.URL] = article
+
+	// The re-crawl of the fetched URL is conditional on the article stored under the canonical URL
+	if _, err := processor.ProcessContent(context.Background(), fetchedURL); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified on re-crawl, got %v", err)
+	}
+}
+
+func TestRetryingContentProcessor_DoesNotRetryNotModified(t *testing.T) {
+	inner := &flakyProcessor{failures: 5, err: ErrNotModified}
+	processor := NewRetryingContentProcessor(inner, 3, time.Millisecond)
+
+	if _, err := processor.ProcessContent(context.Background(), "https://example.com"); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("Expected ErrNotModified, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", inner.calls)
+	}
+}
+
+func TestRetryingContentProcessor_RetriesOnlyTransientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"503", &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}, 3},
+		{"429", &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, 3},
+		{"timeout", fmt.Errorf("failed to fetch URL: %w", context.DeadlineExceeded), 3},
+		{"connection reset", fmt.Errorf("failed to fetch URL: %w", syscall.ECONNRESET), 3},
+		{"404", &HTTPStatusError{StatusCode: http.StatusNotFound}, 1},
+		{"410", &HTTPStatusError{StatusCode: http.StatusGone}, 1},
+		{"parse failure", errors.New("failed to extract text: bad HTML"), 1},
+		{"too short", ErrContentTooShort, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &flakyProcessor{failures: 10, err: tt.err}
+			processor := NewRetryingContentProcessor(inner, 2, time.Millisecond)
+
+			if _, err := processor.ProcessContent(context.Background(), "https://example.com/post"); !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if inner.calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, inner.calls)
+			}
+		})
+	}
+}
+
+func TestRetryingContentProcessor_FetchesMissingPageOnce(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	processor := NewRetryingContentProcessor(NewHTTPContentProcessor(), 2, time.Millisecond)
+	_, err := processor.ProcessContent(context.Background(), server.URL+"/gone")
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected a 404 HTTPStatusError, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected the 404 to be fetched once, got %d requests", n)
+	}
+}
+
+// headServer serves /image.png as image/png, /big as an oversized page, /no-head as a page that
+// rejects HEAD requests, and everything else as HTML; it counts GET requests
+func headServer(t *testing.T, gets *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG"))
+		case "/big":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Length", "4096")
+			if r.Method == http.MethodGet {
+				w.Write([]byte(strings.Repeat("a", 4096)))
+			}
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body><p>Hello</p></body></html>"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body><p>Hello</p></body></html>"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPContentProcessor_HeadPrecheck(t *testing.T) {
+	var gets atomic.Int32
+	server := headServer(t, &gets)
+
+	processor := NewHTTPContentProcessorWithClientOptions(httpclient.CloudflareClient, httpclient.ClientOptions{MaxBodyBytes: 1024})
+	processor.SetExtractor(&countingExtractor{})
+	processor.SetHeadPrecheck(true)
+
+	tests := []struct {
+		path    string
+		skipped bool
+	}{
+		{"/image.png", true},
+		{"/big", true},
+		{"/post", false},
+		{"/no-head", false},
+	}
+	for _, tt := range tests {
+		gets.Store(0)
+		_, err := processor.ProcessContent(context.Background(), server.URL+tt.path)
+		if tt.skipped {
+			if !errors.Is(err, ErrSkippedResource) {
+				t.Errorf("%s: expected ErrSkippedResource, got %v", tt.path, err)
+			}
+			if gets.Load() != 0 {
+				t.Errorf("%s: expected no GET for a skipped resource, got %d", tt.path, gets.Load())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected the page to be fetched, got %v", tt.path, err)
+		}
+		if gets.Load() != 1 {
+			t.Errorf("%s: expected 1 GET, got %d", tt.path, gets.Load())
+		}
+	}
+}
+
+func TestHTTPContentProcessor_HeadPrecheckDisabledByDefault(t *testing.T) {
+	var gets atomic.Int32
+	server := headServer(t, &gets)
+
+	processor := NewHTTPContentProcessorWithExtractor(&countingExtractor{})
+	if _, err := processor.ProcessContent(context.Background(), server.URL+"/image.png"); errors.Is(err, ErrSkippedResource) {
+		t.Fatalf("Expected no HEAD precheck unless enabled, got %v", err)
+	}
+	if gets.Load() != 1 {
+		t.Errorf("Expected the URL to be fetched, got %d GETs", gets.Load())
+	}
+}
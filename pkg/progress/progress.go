@@ -0,0 +1,127 @@
+// Package progress reports crawl counts to an embedding program while a crawl runs
+package progress
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultInterval is how often a Reporter delivers progress when no interval is given
+const DefaultInterval = time.Second
+
+// Progress is a snapshot of the counts for a running crawl
+// Each count only grows during a run
+type Progress struct {
+	PagesProcessed int64 // Listing pages (paginated pages, sitemaps, feeds) fetched for URLs
+	URLsExtracted  int64 // Article URLs handed to the content workers
+	ArticlesSaved  int64 // Articles successfully saved
+}
+
+// Reporter collects counts from many workers and delivers snapshots to a callback
+// The callback is only ever called from the Reporter's own goroutine, one call at a time
+// A nil *Reporter is valid and ignores all calls, so callers don't need to check for one
+type Reporter struct {
+	onProgress func(Progress)
+	interval   time.Duration
+
+	pages atomic.Int64
+	urls  atomic.Int64
+	saved atomic.Int64
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReporter creates a Reporter that calls onProgress every interval while counts change
+// Returns nil if onProgress is nil. A non-positive interval uses DefaultInterval
+func NewReporter(onProgress func(Progress), interval time.Duration) *Reporter {
+	if onProgress == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Reporter{
+		onProgress: onProgress,
+		interval:   interval,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start starts the reporting goroutine
+func (r *Reporter) Start() {
+	if r == nil {
+		return
+	}
+	go r.run()
+}
+
+// Stop delivers a final snapshot and waits for the reporting goroutine to exit
+// Must be called after Start; calling it more than once is safe
+func (r *Reporter) Stop() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+// AddPages counts listing pages fetched
+func (r *Reporter) AddPages(n int) {
+	if r != nil {
+		r.pages.Add(int64(n))
+	}
+}
+
+// AddURLs counts article URLs extracted
+func (r *Reporter) AddURLs(n int) {
+	if r != nil {
+		r.urls.Add(int64(n))
+	}
+}
+
+// AddSaved counts articles saved
+func (r *Reporter) AddSaved(n int) {
+	if r != nil {
+		r.saved.Add(int64(n))
+	}
+}
+
+// run delivers snapshots until stopped; unchanged snapshots are skipped
+func (r *Reporter) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var last Progress
+	report := func(force bool) {
+		current := r.snapshot()
+		if force || current != last {
+			r.onProgress(current)
+			last = current
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			report(false)
+		case <-r.stop:
+			report(true)
+			return
+		}
+	}
+}
+
+// snapshot reads the current counts
+func (r *Reporter) snapshot() Progress {
+	return Progress{
+		PagesProcessed: r.pages.Load(),
+		URLsExtracted:  r.urls.Load(),
+		ArticlesSaved:  r.saved.Load(),
+	}
+}
@@ -0,0 +1,49 @@
+package progress
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReporter_StopDeliversFinalCounts(t *testing.T) {
+	var reports []Progress
+	r := NewReporter(func(p Progress) { reports = append(reports, p) }, time.Hour)
+	r.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.AddPages(1)
+			r.AddURLs(3)
+			r.AddSaved(2)
+		}()
+	}
+	wg.Wait()
+	r.Stop()
+	r.Stop() // Safe to call twice
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected exactly the final report with a long interval, got %d", len(reports))
+	}
+	expected := Progress{PagesProcessed: 10, URLsExtracted: 30, ArticlesSaved: 20}
+	if reports[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, reports[0])
+	}
+}
+
+func TestReporter_NilCallbackIsNoOp(t *testing.T) {
+	r := NewReporter(nil, time.Millisecond)
+	if r != nil {
+		t.Fatalf("Expected nil reporter without a callback, got %v", r)
+	}
+
+	// All methods are safe on a nil reporter
+	r.Start()
+	r.AddPages(1)
+	r.AddURLs(1)
+	r.AddSaved(1)
+	r.Stop()
+}
@@ -2,31 +2,50 @@ package replication
 
 import (
 	"context"
-	"crypto/md5"
-	"database/sql"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"blog-search/pkg/db"
 	"blog-search/pkg/domain"
 )
 
+// Mode controls what happens when a replicated URL already exists in Postgres.
+type Mode string
+
+const (
+	// ModeInsertOnly skips URLs that already exist in Postgres.
+	ModeInsertOnly Mode = "insert-only"
+	// ModeUpsert overwrites existing rows when the Mongo article has a newer crawled_at.
+	ModeUpsert Mode = "upsert"
+)
+
 // Config wires the replication dependencies.
 type Config struct {
 	Mongo    *db.Client
 	Postgres db.DBProvider
 
+	// FullCopy reads every Mongo article on each run instead of only the ones
+	// crawled after the newest article already in Postgres.
+	FullCopy bool
+
+	// Mode defaults to ModeInsertOnly.
+	Mode Mode
+
 	// Mongo collection name is currently baked into db.NewClient(..., collectionName).
 	// We'll keep this out of config for now to match existing patterns.
 }
 
 // Replicator replicates data from MongoDB to Postgres.
 //
-// This is intentionally a one-shot, "copy everything" flow for now.
+// By default it is incremental: only articles crawled after the newest
+// crawled_at already in Postgres are read from Mongo.
 type Replicator struct {
-	mongo *db.Client
-	pg    db.DBProvider
+	mongo    *db.Client
+	pg       db.DBProvider
+	fullCopy bool
+	mode     Mode
 }
 
 func NewReplicator(cfg Config) (*Replicator, error) {
@@ -36,44 +55,87 @@ func NewReplicator(cfg Config) (*Replicator, error) {
 	if cfg.Postgres == nil {
 		return nil, fmt.Errorf("postgres client is required")
 	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = ModeInsertOnly
+	}
+	if mode != ModeInsertOnly && mode != ModeUpsert {
+		return nil, fmt.Errorf("unknown replication mode %q (expected %q or %q)", mode, ModeInsertOnly, ModeUpsert)
+	}
+
 	return &Replicator{
-		mongo: cfg.Mongo,
-		pg:    cfg.Postgres,
+		mongo:    cfg.Mongo,
+		pg:       cfg.Postgres,
+		fullCopy: cfg.FullCopy,
+		mode:     mode,
 	}, nil
 }
 
-// ReplicateArticlesMongoToPostgres reads all Articles from Mongo and inserts them
+// ReplicationResult summarizes a replication run.
+// Processed is always Inserted + Skipped + Failed.
+type ReplicationResult struct {
+	Processed int
+	Inserted  int
+	Skipped   int
+	Failed    int
+	Duration  time.Duration
+}
+
+// ReplicateArticlesMongoToPostgres reads Articles from Mongo and inserts them
 // into the Postgres `article` table.
 //
-// Behavior: if a URL already exists in Postgres, we skip inserting it.
-// Processes articles in batches to avoid loading all URLs into memory at once.
+// Behavior: if a URL already exists in Postgres, we skip inserting it, unless the
+// mode is ModeUpsert, in which case the row is updated when the Mongo copy is newer.
+// Unless FullCopy is set, only articles newer than the Postgres high-water mark are read.
+// Articles are streamed from Mongo and processed in batches, so they are never all held in memory.
 func (r *Replicator) ReplicateArticlesMongoToPostgres(ctx context.Context) error {
+	_, err := r.ReplicateArticlesWithResult(ctx)
+	return err
+}
+
+// ReplicateArticlesWithResult behaves like ReplicateArticlesMongoToPostgres and also
+// returns the run's counts. The result is populated up to the point of failure.
+func (r *Replicator) ReplicateArticlesWithResult(ctx context.Context) (ReplicationResult, error) {
+	startTime := time.Now()
+
 	if err := r.ensureArticleSchema(ctx); err != nil {
-		return err
+		return ReplicationResult{Duration: time.Since(startTime)}, err
 	}
 
-	articles, err := r.readAllArticlesFromMongo(ctx)
+	iterate, err := r.readArticlesFromMongo(ctx)
 	if err != nil {
-		return err
+		return ReplicationResult{Duration: time.Since(startTime)}, err
 	}
 
-	log.Printf("Loaded %d articles from Mongo, processing in batches...", len(articles))
+	log.Printf("Streaming articles from Mongo, processing in batches...")
 
-	totalProcessed, totalInserted, err := r.processBatches(ctx, articles)
+	result, err := r.processBatches(ctx, iterate)
+	result.Duration = time.Since(startTime)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	log.Printf("Replication complete: processed %d articles, inserted %d new articles", totalProcessed, totalInserted)
-	return nil
+	log.Printf("Replication complete: processed %d articles, inserted %d, skipped %d, failed %d in %s",
+		result.Processed, result.Inserted, result.Skipped, result.Failed, result.Duration)
+	return result, nil
 }
 
-// processBatches processes all articles in batches in parallel and returns total processed and inserted counts.
-func (r *Replicator) processBatches(ctx context.Context, articles []domain.Article) (int, int, error) {
+// articleIterator calls fn for each article to replicate, stopping at fn's first error.
+type articleIterator func(ctx context.Context, fn func(*domain.Article) error) error
+
+// processBatches reads articles from iterate into batches, processes them in parallel and
+// returns the aggregated counts. Only a few batches are buffered ahead of the workers, so the
+// articles are never all held in memory.
+// It stops at the first failing batch, whose articles are counted as failed.
+func (r *Replicator) processBatches(ctx context.Context, iterate articleIterator) (ReplicationResult, error) {
 	const processBatchSize = 100
 	const numWorkers = 5
 
-	// Create batch jobs
+	// Cancelled on the first failed batch, which stops the reader and fails the queued batches fast
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	type batchJob struct {
 		batch []domain.Article
 		start int
@@ -83,21 +145,43 @@ func (r *Replicator) processBatches(ctx context.Context, articles []domain.Artic
 	type batchResult struct {
 		processed int
 		inserted  int
+		skipped   int
 		err       error
 	}
 
-	// Calculate number of batches
-	numBatches := (len(articles) + processBatchSize - 1) / processBatchSize
-	jobs := make(chan batchJob, numBatches)
-	results := make(chan batchResult, numBatches)
+	jobs := make(chan batchJob, numWorkers)
+	results := make(chan batchResult, numWorkers)
 
-	// Create batches and send to jobs channel
-	for start := 0; start < len(articles); start += processBatchSize {
-		end := r.calculateBatchEnd(start, processBatchSize, len(articles))
-		batch := articles[start:end]
-		jobs <- batchJob{batch: batch, start: start, end: end}
-	}
-	close(jobs)
+	// Read articles into batches and send them to the jobs channel
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+
+		var batch []domain.Article
+		start := 0
+		send := func() error {
+			select {
+			case jobs <- batchJob{batch: batch, start: start, end: start + len(batch)}:
+				start += len(batch)
+				batch = nil
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := iterate(ctx, func(a *domain.Article) error {
+			batch = append(batch, *a)
+			if len(batch) < processBatchSize {
+				return nil
+			}
+			return send()
+		})
+		if err == nil && len(batch) > 0 {
+			err = send()
+		}
+		readErr <- err
+	}()
 
 	// Start worker goroutines
 	var wg sync.WaitGroup
@@ -106,10 +190,11 @@ func (r *Replicator) processBatches(ctx context.Context, articles []domain.Artic
 		go func() {
 			defer wg.Done()
 			for job := range jobs {
-				inserted, err := r.processBatch(ctx, job.batch, job.start, job.end)
+				inserted, skipped, err := r.processBatch(ctx, job.batch, job.start, job.end)
 				results <- batchResult{
 					processed: len(job.batch),
 					inserted:  inserted,
+					skipped:   skipped,
 					err:       err,
 				}
 			}
@@ -122,93 +207,96 @@ func (r *Replicator) processBatches(ctx context.Context, articles []domain.Artic
 		close(results)
 	}()
 
-	// Collect results and fail fast on error
-	var mu sync.Mutex
-	totalProcessed := 0
-	totalInserted := 0
+	// Collect results; after the first error the remaining results are drained but not counted
+	var total ReplicationResult
+	var batchErr error
 
 	for result := range results {
+		if batchErr != nil {
+			continue
+		}
+
+		total.Processed += result.processed
 		if result.err != nil {
-			return totalProcessed, totalInserted, result.err
+			total.Failed += result.processed
+			batchErr = result.err
+			cancel()
+			continue
 		}
 
-		mu.Lock()
-		totalProcessed += result.processed
-		totalInserted += result.inserted
-		shouldLog := totalProcessed%1000 == 0 || totalProcessed == len(articles)
-		mu.Unlock()
+		total.Inserted += result.inserted
+		total.Skipped += result.skipped
 
-		if shouldLog {
-			r.logProgress(totalProcessed, len(articles), totalInserted, totalProcessed == len(articles))
+		if total.Processed%1000 == 0 {
+			r.logProgress(total.Processed, total.Inserted, false)
 		}
 	}
 
-	// Final progress log
-	r.logProgress(totalProcessed, len(articles), totalInserted, true)
+	if batchErr != nil {
+		return total, batchErr
+	}
+	if err := <-readErr; err != nil {
+		return total, fmt.Errorf("read articles: %w", err)
+	}
 
-	return totalProcessed, totalInserted, nil
-}
+	// Final progress log
+	r.logProgress(total.Processed, total.Inserted, true)
 
-// calculateBatchEnd calculates the end index for a batch, ensuring it doesn't exceed the total length.
-func (r *Replicator) calculateBatchEnd(start, batchSize, totalLen int) int {
-	end := start + batchSize
-	if end > totalLen {
-		return totalLen
-	}
-	return end
+	return total, nil
 }
 
 // processBatch processes a single batch: checks existing URLs, filters new ones, and inserts them.
-func (r *Replicator) processBatch(ctx context.Context, batch []domain.Article, start, end int) (int, error) {
+// In upsert mode every article in the batch is written and conflicts are resolved by crawled_at.
+// It returns how many articles were written and how many were skipped.
+func (r *Replicator) processBatch(ctx context.Context, batch []domain.Article, start, end int) (int, int, error) {
 	log.Printf("Processing batch [%d:%d] (%d articles)...", start, end, len(batch))
 
+	if r.mode == ModeUpsert {
+		log.Printf("  Upserting %d articles...", len(batch))
+		written, err := r.insertArticlesTx(ctx, batch)
+		if err != nil {
+			return 0, 0, fmt.Errorf("upsert batch [%d:%d]: %w", start, end, err)
+		}
+		return written, len(batch) - written, nil
+	}
+
 	existing, err := r.checkURLsExistInPostgres(ctx, batch)
 	if err != nil {
-		return 0, fmt.Errorf("check existing URLs for batch [%d:%d]: %w", start, end, err)
+		return 0, 0, fmt.Errorf("check existing URLs for batch [%d:%d]: %w", start, end, err)
 	}
 	log.Printf("  Found %d existing URLs in Postgres", len(existing))
 
 	toInsert := r.filterNewArticlesByURL(batch, existing)
 	if len(toInsert) == 0 {
 		log.Printf("  No new articles to insert")
-		return 0, nil
+		return 0, len(batch), nil
 	}
 
 	log.Printf("  Inserting %d new articles...", len(toInsert))
-	if err := r.insertArticlesTx(ctx, toInsert); err != nil {
-		return 0, fmt.Errorf("insert batch [%d:%d]: %w", start, end, err)
+	inserted, err := r.insertArticlesTx(ctx, toInsert)
+	if err != nil {
+		return 0, 0, fmt.Errorf("insert batch [%d:%d]: %w", start, end, err)
 	}
-	log.Printf("  ✓ Inserted %d articles", len(toInsert))
+	log.Printf("  ✓ Inserted %d articles", inserted)
 
-	return len(toInsert), nil
+	// Existing URLs, empty URLs and rows that lost an insert race all count as skipped
+	return inserted, len(batch) - inserted, nil
 }
 
 // logProgress logs progress at regular intervals or at completion.
-func (r *Replicator) logProgress(processed, total, inserted int, isComplete bool) {
+func (r *Replicator) logProgress(processed, inserted int, isComplete bool) {
 	if processed%1000 == 0 || isComplete {
-		log.Printf("Progress: processed %d/%d articles, inserted %d new articles", processed, total, inserted)
+		log.Printf("Progress: processed %d articles, inserted %d new articles", processed, inserted)
 	}
 }
 
 func (r *Replicator) ensureArticleSchema(ctx context.Context) error {
-	if r.pg.DB() == nil {
-		return fmt.Errorf("postgres DB not connected")
+	applied, err := r.pg.Articles().EnsureSchema(ctx)
+	if err != nil {
+		return err
 	}
-
-	// Keep schema simple: url is the primary key, which also gives us uniqueness.
-	//
-	// NOTE: we default crawled_at to now() so older Mongo docs missing crawled_at
-	// can still be inserted (implementation still sets it explicitly when present).
-	const ddl = `
-CREATE TABLE IF NOT EXISTS article (
-  url TEXT PRIMARY KEY,
-  title TEXT NOT NULL DEFAULT '',
-  text TEXT NOT NULL DEFAULT '',
-  crawled_at TIMESTAMPTZ NOT NULL DEFAULT now()
-);`
-
-	if _, err := r.pg.DB().ExecContext(ctx, ddl); err != nil {
-		return fmt.Errorf("create article table: %w", err)
+	if applied > 0 {
+		log.Printf("Applied %d article schema migration(s)", applied)
 	}
 	return nil
 }
@@ -216,90 +304,40 @@ CREATE TABLE IF NOT EXISTS article (
 // checkURLsExistInPostgres checks which URLs from the given batch already exist in Postgres.
 // This avoids loading all URLs into memory at once.
 func (r *Replicator) checkURLsExistInPostgres(ctx context.Context, batch []domain.Article) (map[string]bool, error) {
-	if r.pg.DB() == nil {
-		return nil, fmt.Errorf("postgres DB not connected")
-	}
-	if len(batch) == 0 {
-		return map[string]bool{}, nil
-	}
-
-	urls := r.extractURLsFromBatch(batch)
-	if len(urls) == 0 {
-		return map[string]bool{}, nil
-	}
-
-	query, args := r.buildURLInQuery(urls)
-	return r.executeURLQuery(ctx, query, args)
-}
-
-// extractURLsFromBatch extracts non-empty URLs from a batch of articles.
-func (r *Replicator) extractURLsFromBatch(batch []domain.Article) []interface{} {
-	urls := make([]interface{}, 0, len(batch))
+	urls := make([]string, 0, len(batch))
 	for _, a := range batch {
-		if a.URL != "" {
-			urls = append(urls, a.URL)
-		}
+		urls = append(urls, a.URL)
 	}
-	return urls
+	return r.pg.Articles().ExistingURLs(ctx, urls)
 }
 
-// buildURLInQuery builds a SQL query with IN clause and returns the query string and arguments.
-// Uses a unique identifier to prevent prepared statement cache conflicts in parallel execution.
-func (r *Replicator) buildURLInQuery(urls []interface{}) (string, []interface{}) {
-	// Use a unique query pattern to avoid prepared statement cache conflicts
-	// Each batch gets a unique query based on the number of URLs and a hash of the first URL
-	// This prevents pgx from trying to cache the same prepared statement across goroutines
-	var hashSuffix string
-	if len(urls) > 0 {
-		if urlStr, ok := urls[0].(string); ok {
-			hash := md5.Sum([]byte(urlStr))
-			hashSuffix = fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes of hash
-		}
-	}
-	query := fmt.Sprintf(`/* q_%d_%s */ SELECT url FROM article WHERE url IN (`, len(urls), hashSuffix)
-	args := make([]interface{}, len(urls))
-	for i, url := range urls {
-		if i > 0 {
-			query += ", "
-		}
-		query += fmt.Sprintf("$%d", i+1)
-		args[i] = url
+// readArticlesFromMongo returns an iterator over either all articles (full copy) or only the
+// ones crawled after the newest article already replicated to Postgres.
+func (r *Replicator) readArticlesFromMongo(ctx context.Context) (articleIterator, error) {
+	if r.fullCopy {
+		log.Printf("Full copy mode: reading all articles from Mongo")
+		return r.mongo.IterateArticles, nil
 	}
-	query += ")"
-	return query, args
-}
 
-// executeURLQuery executes a URL query and returns the results as a set.
-func (r *Replicator) executeURLQuery(ctx context.Context, query string, args []interface{}) (map[string]bool, error) {
-	rows, err := r.pg.DB().QueryContext(ctx, query, args...)
+	highWaterMark, err := r.maxCrawledAtInPostgres(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("query existing urls: %w", err)
-	}
-	defer rows.Close()
-
-	set := make(map[string]bool)
-	for rows.Next() {
-		var url string
-		if err := rows.Scan(&url); err != nil {
-			return nil, fmt.Errorf("scan url: %w", err)
-		}
-		if url != "" {
-			set[url] = true
-		}
+		return nil, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+	if highWaterMark.IsZero() {
+		log.Printf("Postgres article table is empty, reading all articles from Mongo")
+		return r.mongo.IterateArticles, nil
 	}
 
-	return set, nil
+	log.Printf("Incremental mode: reading articles crawled after %s", highWaterMark.Format(time.RFC3339Nano))
+	return func(ctx context.Context, fn func(*domain.Article) error) error {
+		return r.mongo.IterateArticlesSince(ctx, highWaterMark, fn)
+	}, nil
 }
 
-func (r *Replicator) readAllArticlesFromMongo(ctx context.Context) ([]domain.Article, error) {
-	articles, err := r.mongo.GetAllArticles(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return articles, nil
+// maxCrawledAtInPostgres returns the newest crawled_at in the article table,
+// or the zero time if the table is empty.
+func (r *Replicator) maxCrawledAtInPostgres(ctx context.Context) (time.Time, error) {
+	return r.pg.Articles().MaxCrawledAt(ctx)
 }
 
 func (r *Replicator) filterNewArticlesByURL(all []domain.Article, existing map[string]bool) []domain.Article {
@@ -320,45 +358,7 @@ func (r *Replicator) filterNewArticlesByURL(all []domain.Article, existing map[s
 	return out
 }
 
-// insertArticlesTx inserts a batch of articles within a transaction.
-func (r *Replicator) insertArticlesTx(ctx context.Context, batch []domain.Article) error {
-	tx, err := r.pg.DB().BeginTx(ctx, &sql.TxOptions{})
-	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
-	}
-	defer func() { _ = tx.Rollback() }()
-
-	if err := r.executeBatchInsert(ctx, tx, batch); err != nil {
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit: %w", err)
-	}
-	return nil
-}
-
-// executeBatchInsert executes the insert statements for a batch of articles.
-func (r *Replicator) executeBatchInsert(ctx context.Context, tx *sql.Tx, batch []domain.Article) error {
-	const insertQuery = `
-INSERT INTO article (url, title, text, crawled_at)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (url) DO NOTHING`
-
-	stmt, err := tx.PrepareContext(ctx, insertQuery)
-	if err != nil {
-		return fmt.Errorf("prepare insert: %w", err)
-	}
-	defer stmt.Close()
-
-	for _, a := range batch {
-		if a.URL == "" {
-			continue
-		}
-		if _, err := stmt.ExecContext(ctx, a.URL, a.Title, a.Text, a.CrawledAt); err != nil {
-			return fmt.Errorf("insert article url=%q: %w", a.URL, err)
-		}
-	}
-
-	return nil
+// insertArticlesTx inserts a batch of articles within a transaction and returns the number of rows written.
+func (r *Replicator) insertArticlesTx(ctx context.Context, batch []domain.Article) (int, error) {
+	return r.pg.Articles().InsertBatch(ctx, batch, r.mode == ModeUpsert)
 }
@@ -0,0 +1,70 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Namespace is the sitemap protocol's XML namespace
+const Namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// MaxEntries is the most URLs the sitemap protocol allows in a single sitemap file
+const MaxEntries = 50000
+
+// Entry is a single URL in a sitemap
+type Entry struct {
+	Loc     string    // Absolute URL of the page
+	LastMod time.Time // When the page last changed; omitted if zero
+}
+
+// urlSet is the <urlset> document written by WriteSitemap
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+// urlEntry is a single <url> element
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteSitemap writes entries to w as a <urlset> sitemap
+// URLs are XML-escaped and lastmod is written in W3C datetime format (UTC)
+// Returns an error for entries without a URL or for more than MaxEntries entries
+func WriteSitemap(w io.Writer, entries []Entry) error {
+	if len(entries) > MaxEntries {
+		return fmt.Errorf("sitemap has %d entries, more than the %d allowed in one file", len(entries), MaxEntries)
+	}
+
+	set := urlSet{
+		Xmlns: Namespace,
+		URLs:  make([]urlEntry, 0, len(entries)),
+	}
+	for i, entry := range entries {
+		if entry.Loc == "" {
+			return fmt.Errorf("sitemap entry %d has no URL", i)
+		}
+		u := urlEntry{Loc: entry.Loc}
+		if !entry.LastMod.IsZero() {
+			u.LastMod = entry.LastMod.UTC().Format(time.RFC3339)
+		}
+		set.URLs = append(set.URLs, u)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write sitemap: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(set); err != nil {
+		return fmt.Errorf("failed to write sitemap: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write sitemap: %w", err)
+	}
+	return nil
+}
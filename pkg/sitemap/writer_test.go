@@ -0,0 +1,88 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-search/pkg/urls"
+)
+
+// parsedSitemap mirrors the written document for round-trip checks
+type parsedSitemap struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+func TestWriteSitemap_RoundTrip(t *testing.T) {
+	crawledAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("IST", 2*60*60))
+	entries := []Entry{
+		{Loc: "https://example.com/post?a=1&b=2", LastMod: crawledAt},
+		{Loc: "https://example.com/<odd>\"path\"'"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSitemap(&buf, entries); err != nil {
+		t.Fatalf("WriteSitemap failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "a=1&b=2") {
+		t.Errorf("Expected the & in the URL to be escaped, got:\n%s", buf.String())
+	}
+
+	var parsed parsedSitemap
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("Written sitemap is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(parsed.URLs) != 2 {
+		t.Fatalf("Expected 2 URLs, got %d", len(parsed.URLs))
+	}
+	if parsed.URLs[0].Loc != entries[0].Loc || parsed.URLs[1].Loc != entries[1].Loc {
+		t.Errorf("Expected URLs to round-trip, got %q and %q", parsed.URLs[0].Loc, parsed.URLs[1].Loc)
+	}
+	if parsed.URLs[0].LastMod != "2024-01-15T08:30:00Z" {
+		t.Errorf("Expected lastmod in UTC W3C format, got %q", parsed.URLs[0].LastMod)
+	}
+	if parsed.URLs[1].LastMod != "" {
+		t.Errorf("Expected no lastmod for a zero time, got %q", parsed.URLs[1].LastMod)
+	}
+}
+
+func TestWriteSitemap_ParsedBySitemapParser(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSitemap(&buf, []Entry{
+		{Loc: "https://example.com/a", LastMod: time.Now()},
+		{Loc: "https://example.com/b?x=1&y=2"},
+	})
+	if err != nil {
+		t.Fatalf("WriteSitemap failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	parsed, err := urls.NewSitemapParser().Fetch(server.URL)
+	if err != nil {
+		t.Fatalf("SitemapParser failed to read the written sitemap: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Location != "https://example.com/a" || parsed[1].Location != "https://example.com/b?x=1&y=2" {
+		t.Errorf("Expected the written URLs, got %+v", parsed)
+	}
+}
+
+func TestWriteSitemap_RejectsInvalidEntries(t *testing.T) {
+	if err := WriteSitemap(&bytes.Buffer{}, []Entry{{Loc: ""}}); err == nil {
+		t.Error("Expected an error for an entry without a URL")
+	}
+	if err := WriteSitemap(&bytes.Buffer{}, make([]Entry, MaxEntries+1)); err == nil {
+		t.Error("Expected an error for more than MaxEntries entries")
+	}
+}
@@ -2,15 +2,30 @@ package sites
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	"blog-search/pkg/urls"
 	"github.com/PuerkitoBio/goquery"
 )
 
+// dataEngineeringPodcastBaseURL is the default base used to resolve relative episode links
+const dataEngineeringPodcastBaseURL = "https://www.dataengineeringpodcast.com"
+
 // ExtractDataEngineeringPodcastURLs extracts episode URLs from dataengineeringpodcast.com HTML pages
-// It looks for links with class "episodeLink" that have href starting with "/episodepage/"
+// It looks for links with class "episodeLink" that point to "/episodepage/"
 func ExtractDataEngineeringPodcastURLs(html string) ([]urls.URL, error) {
+	return ExtractDataEngineeringPodcastURLsWithBase(html, dataEngineeringPodcastBaseURL)
+}
+
+// ExtractDataEngineeringPodcastURLsWithBase extracts episode URLs like ExtractDataEngineeringPodcastURLs,
+// resolving relative hrefs against baseURL instead of the production site (e.g. for mirrors)
+func ExtractDataEngineeringPodcastURLsWithBase(html, baseURL string) ([]urls.URL, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
@@ -25,15 +40,18 @@ func ExtractDataEngineeringPodcastURLs(html string) ([]urls.URL, error) {
 			return
 		}
 
-		// Make sure it's an episode link
-		if !strings.HasPrefix(href, "/episodepage/") {
+		// Convert relative URL to absolute
+		ref, err := url.Parse(href)
+		if err != nil {
 			return
 		}
+		resolved := base.ResolveReference(ref)
 
-		// Convert relative URL to absolute
-		if strings.HasPrefix(href, "/") {
-			href = "https://www.dataengineeringpodcast.com" + href
+		// Make sure it's an episode link
+		if !strings.HasPrefix(resolved.Path, "/episodepage/") {
+			return
 		}
+		href = resolved.String()
 
 		title := strings.TrimSpace(link.Text())
 		if title == "" {
@@ -0,0 +1,53 @@
+package sites
+
+import (
+	"testing"
+)
+
+const dataEngineeringPodcastTestHTML = `<html><body>
+<a class="episodeLink" href="/episodepage/first-episode">First Episode</a>
+<a class="episodeLink" href="https://www.dataengineeringpodcast.com/episodepage/second-episode">Second Episode</a>
+<a class="episodeLink" href="/about">About</a>
+</body></html>`
+
+func TestExtractDataEngineeringPodcastURLsWithBase_ResolvesRelative(t *testing.T) {
+	result, err := ExtractDataEngineeringPodcastURLsWithBase(dataEngineeringPodcastTestHTML, "https://staging.example.com/podcast/page/2")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 episode URLs, got %d: %v", len(result), result)
+	}
+	if result[0].Location != "https://staging.example.com/episodepage/first-episode" {
+		t.Errorf("Expected relative href resolved against base, got %s", result[0].Location)
+	}
+	if result[0].Title != "First Episode" {
+		t.Errorf("Expected title 'First Episode', got %s", result[0].Title)
+	}
+}
+
+func TestExtractDataEngineeringPodcastURLsWithBase_KeepsAbsolute(t *testing.T) {
+	result, err := ExtractDataEngineeringPodcastURLsWithBase(dataEngineeringPodcastTestHTML, "https://staging.example.com")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 episode URLs, got %d: %v", len(result), result)
+	}
+	if result[1].Location != "https://www.dataengineeringpodcast.com/episodepage/second-episode" {
+		t.Errorf("Expected absolute href unchanged, got %s", result[1].Location)
+	}
+}
+
+func TestExtractDataEngineeringPodcastURLs_DefaultBase(t *testing.T) {
+	result, err := ExtractDataEngineeringPodcastURLs(dataEngineeringPodcastTestHTML)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if result[0].Location != "https://www.dataengineeringpodcast.com/episodepage/first-episode" {
+		t.Errorf("Expected production base by default, got %s", result[0].Location)
+	}
+}
@@ -16,6 +16,8 @@ import (
 // 2. Links within <main> content area
 // 3. Links with common article-related classes (entry-title, post-title, article-link, etc.)
 // 4. All links excluding navigation, footer, header, and common non-content areas
+// Relative links are only resolved if the page declares a base (<base>, canonical or og:url);
+// use ExtractGenericURLsWithBase when the page URL is known
 func ExtractGenericURLs(html string) ([]urls.URL, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
@@ -23,8 +25,34 @@ func ExtractGenericURLs(html string) ([]urls.URL, error) {
 	}
 
 	// Try to get base URL from <base> tag or use document URL
-	baseURL := getBaseURL(doc)
+	return extractGenericURLs(doc, getBaseURL(doc))
+}
+
+// ExtractGenericURLsWithBase extracts article URLs like ExtractGenericURLs, always resolving
+// relative links against requestURL (or the page's <base> tag, itself resolved against requestURL)
+func ExtractGenericURLsWithBase(html, requestURL string) ([]urls.URL, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	base, err := url.Parse(requestURL)
+	if err != nil || !base.IsAbs() {
+		return nil, fmt.Errorf("invalid request URL %q", requestURL)
+	}
+
+	// A <base> tag overrides the document URL, as it does in browsers
+	if baseHref, exists := doc.Find("base").Attr("href"); exists && baseHref != "" {
+		if ref, err := url.Parse(baseHref); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	return extractGenericURLs(doc, base.String())
+}
 
+// extractGenericURLs runs the generic extraction strategies, resolving relative links against baseURL
+func extractGenericURLs(doc *goquery.Document, baseURL string) ([]urls.URL, error) {
 	var result []urls.URL
 	seenURLs := make(map[string]bool)
 
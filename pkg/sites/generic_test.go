@@ -0,0 +1,66 @@
+package sites
+
+import (
+	"testing"
+)
+
+func TestExtractGenericURLsWithBase_ResolvesRelativeLinks(t *testing.T) {
+	html := `<html><body><main>
+<article><a href="/relative/path">Relative</a></article>
+<article><a href="../parent">Parent</a></article>
+<article><a href="//cdn.example.com/x">Protocol relative</a></article>
+<article><a href="https://other.example.com/absolute">Absolute</a></article>
+</main></body></html>`
+
+	result, err := ExtractGenericURLsWithBase(html, "https://blog.example.com/posts/page/2")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	expected := []string{
+		"https://blog.example.com/relative/path",
+		"https://blog.example.com/posts/parent",
+		"https://cdn.example.com/x",
+		"https://other.example.com/absolute",
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d URLs, got %d: %v", len(expected), len(result), result)
+	}
+	for i, want := range expected {
+		if result[i].Location != want {
+			t.Errorf("URL %d: expected %s, got %s", i, want, result[i].Location)
+		}
+	}
+}
+
+func TestExtractGenericURLsWithBase_HonorsBaseTag(t *testing.T) {
+	html := `<html><head><base href="/blog/"></head><body>
+<article><a href="first-post">First</a></article>
+</body></html>`
+
+	result, err := ExtractGenericURLsWithBase(html, "https://example.com/index.html")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if len(result) != 1 || result[0].Location != "https://example.com/blog/first-post" {
+		t.Errorf("Expected link resolved against <base>, got %v", result)
+	}
+}
+
+func TestExtractGenericURLsWithBase_InvalidRequestURL(t *testing.T) {
+	if _, err := ExtractGenericURLsWithBase(`<article><a href="/x">X</a></article>`, "/not-absolute"); err == nil {
+		t.Fatal("Expected error for relative request URL, got nil")
+	}
+}
+
+func TestExtractGenericURLs_WithoutBaseKeepsRelative(t *testing.T) {
+	result, err := ExtractGenericURLs(`<html><body><article><a href="/relative/path">Relative</a></article></body></html>`)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if len(result) != 1 || result[0].Location != "/relative/path" {
+		t.Errorf("Expected relative link left as-is, got %v", result)
+	}
+}
@@ -23,15 +23,29 @@ type Config struct {
 	MaxEntries  int
 }
 
+// Parser is one of the URL sources DownloadText tries, with a name for reports
+type Parser struct {
+	Name    string
+	Fetcher urls.URLsFetcher
+}
+
+// DefaultParsers returns the parsers DownloadText tries, in order: file, sitemap, then
+// RSS (which also reads Atom and JSON Feed)
+func DefaultParsers() []Parser {
+	return []Parser{
+		{Name: "file", Fetcher: urls.NewFileParser()},
+		{Name: "sitemap", Fetcher: urls.NewSitemapParser()},
+		{Name: "rss", Fetcher: urls.NewRSSParser()},
+	}
+}
+
 // NewService creates a new TextDownloadService
 func NewService(config Config) *Service {
 	mgr := worker.NewManager(config.WorkerCount, config.DBClient)
 
-	// Initialize parsers in order: file, sitemap, then RSS
-	parsers := []urls.URLsFetcher{
-		urls.NewFileParser(),
-		urls.NewSitemapParser(),
-		urls.NewRSSParser(),
+	var parsers []urls.URLsFetcher
+	for _, parser := range DefaultParsers() {
+		parsers = append(parsers, parser.Fetcher)
 	}
 
 	return &Service{
@@ -77,8 +91,8 @@ func (s *Service) DownloadText(ctx context.Context, feedURL string, maxEntries i
 		return fmt.Errorf("failed to parse feed from any parser")
 	}
 
-	// Get already-fetched URLs from database
-	existingUrls, err := s.dbClient.GetAllURLs(ctx)
+	// Get already-fetched URLs from database (only the candidates, not the whole collection)
+	existingUrls, err := s.dbClient.GetExistingArticleURLs(ctx, result)
 	if err != nil {
 		return fmt.Errorf("failed to get fetched URLs: %w", err)
 	}
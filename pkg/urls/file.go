@@ -60,3 +60,12 @@ func (p *FileParser) Fetch(filePath string) ([]URL, error) {
 
 	return urls, nil
 }
+
+// FetchWithMeta is like Fetch, but returns the URLs as a FetchResult; files have no title
+func (p *FileParser) FetchWithMeta(filePath string) (*FetchResult, error) {
+	urls, err := p.Fetch(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return newFetchResult(urls, filePath, SourceFile, ""), nil
+}
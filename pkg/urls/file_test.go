@@ -106,3 +106,20 @@ https://example.com/article2
 		t.Fatalf("Expected 2 URLs (comments should be skipped), got %d", len(urls))
 	}
 }
+
+// staticFetcher is a URLsFetcher without FetchWithMeta
+type staticFetcher []URL
+
+func (f staticFetcher) Fetch(string) ([]URL, error) {
+	return f, nil
+}
+
+func TestFetchWithMeta_FallsBackToFetch(t *testing.T) {
+	result, err := FetchWithMeta(staticFetcher{{Location: "https://example.com/a"}}, "https://example.com/list")
+	if err != nil {
+		t.Fatalf("FetchWithMeta failed: %v", err)
+	}
+	if result.Count != 1 || result.SourceURL != "https://example.com/list" || result.SourceType != "" || result.Title != "" {
+		t.Errorf("Expected only the URLs and source URL, got %+v", result)
+	}
+}
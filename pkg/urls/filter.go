@@ -2,8 +2,11 @@ package urls
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 // UrlFilter defines the interface for URL filtering
@@ -11,6 +14,14 @@ type UrlFilter interface {
 	ShouldKeep(ctx context.Context, url string) (bool, error)
 }
 
+// BatchUrlFilter is implemented by filters that are cheaper to apply to many URLs at once
+// (e.g., one database query per batch); callers should prefer FilterURLs when available
+type BatchUrlFilter interface {
+	UrlFilter
+	// FilterURLs returns the URLs to keep, in their original order
+	FilterURLs(ctx context.Context, urls []string) ([]string, error)
+}
+
 // BaseURLFilter filters out base/root URLs
 type BaseURLFilter struct{}
 
@@ -33,24 +44,216 @@ func (f *BaseURLFilter) ShouldKeep(ctx context.Context, urlStr string) (bool, er
 }
 
 // AlreadyFetchedFilter filters out URLs that already exist in the provided set
+// URLs are compared in normalized form (see Normalize), so "https://x.com/a/?utm_source=rss"
+// matches a stored "https://x.com/a"
 type AlreadyFetchedFilter struct {
 	fetchedURLs map[string]bool
 }
 
 // NewAlreadyFetchedFilter creates a new already-fetched filter
 func NewAlreadyFetchedFilter(fetchedURLs map[string]bool) *AlreadyFetchedFilter {
+	normalized := make(map[string]bool, len(fetchedURLs))
+	for u, fetched := range fetchedURLs {
+		if fetched {
+			normalized[NormalizeOrRaw(u)] = true
+		}
+	}
 	return &AlreadyFetchedFilter{
-		fetchedURLs: fetchedURLs,
+		fetchedURLs: normalized,
 	}
 }
 
 // ShouldKeep returns false if URL is already in the fetched set
 func (f *AlreadyFetchedFilter) ShouldKeep(ctx context.Context, urlStr string) (bool, error) {
 	// Check if URL exists in the fetched set
-	exists := f.fetchedURLs[urlStr]
+	exists := f.fetchedURLs[NormalizeOrRaw(urlStr)]
 	return !exists, nil
 }
 
+// ExistingURLChecker reports which of the candidate URLs are already stored; db.Client satisfies it
+type ExistingURLChecker interface {
+	GetExistingArticleURLs(ctx context.Context, candidates []string) (map[string]bool, error)
+}
+
+// DefaultDBFilterBatchSize is how many URLs DBAlreadyFetchedFilter checks per query
+const DefaultDBFilterBatchSize = 100
+
+// DBAlreadyFetchedFilter filters out URLs that are already stored, like AlreadyFetchedFilter,
+// but queries the store for the URLs it is asked about instead of loading every stored URL up front
+// Suitable for collections too large to load; results are cached for the filter's lifetime
+type DBAlreadyFetchedFilter struct {
+	store     ExistingURLChecker
+	batchSize int
+
+	mu     sync.Mutex
+	stored map[string]bool // Normalized URL -> already stored, for every URL checked so far
+}
+
+// NewDBAlreadyFetchedFilter creates a filter that checks URLs against store in batches of
+// batchSize (DefaultDBFilterBatchSize if zero or negative)
+func NewDBAlreadyFetchedFilter(store ExistingURLChecker, batchSize int) *DBAlreadyFetchedFilter {
+	if batchSize <= 0 {
+		batchSize = DefaultDBFilterBatchSize
+	}
+	return &DBAlreadyFetchedFilter{
+		store:     store,
+		batchSize: batchSize,
+		stored:    make(map[string]bool),
+	}
+}
+
+// ShouldKeep returns false if the URL is already stored
+func (f *DBAlreadyFetchedFilter) ShouldKeep(ctx context.Context, urlStr string) (bool, error) {
+	kept, err := f.FilterURLs(ctx, []string{urlStr})
+	if err != nil {
+		return false, err
+	}
+	return len(kept) == 1, nil
+}
+
+// FilterURLs returns the URLs that aren't stored yet, checking uncached URLs in batches
+func (f *DBAlreadyFetchedFilter) FilterURLs(ctx context.Context, urls []string) ([]string, error) {
+	if err := f.checkUncached(ctx, urls); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if !f.stored[NormalizeOrRaw(u)] {
+			kept = append(kept, u)
+		}
+	}
+	return kept, nil
+}
+
+// checkUncached queries the store for the URLs that haven't been checked yet and caches the results
+// Each URL is looked up both as given and normalized, since older articles were stored unnormalized
+func (f *DBAlreadyFetchedFilter) checkUncached(ctx context.Context, urls []string) error {
+	f.mu.Lock()
+	var uncached []string
+	queued := make(map[string]bool)
+	for _, u := range urls {
+		key := NormalizeOrRaw(u)
+		if _, ok := f.stored[key]; !ok && !queued[key] {
+			queued[key] = true
+			uncached = append(uncached, u)
+		}
+	}
+	f.mu.Unlock()
+
+	for start := 0; start < len(uncached); start += f.batchSize {
+		end := start + f.batchSize
+		if end > len(uncached) {
+			end = len(uncached)
+		}
+		if err := f.checkBatch(ctx, uncached[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBatch looks up one batch of URLs and caches whether each is stored
+func (f *DBAlreadyFetchedFilter) checkBatch(ctx context.Context, batch []string) error {
+	candidates := make([]string, 0, 2*len(batch))
+	for _, u := range batch {
+		candidates = append(candidates, u)
+		if normalized := NormalizeOrRaw(u); normalized != u {
+			candidates = append(candidates, normalized)
+		}
+	}
+
+	existing, err := f.store.GetExistingArticleURLs(ctx, candidates)
+	if err != nil {
+		return fmt.Errorf("failed to check stored URLs: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range batch {
+		key := NormalizeOrRaw(u)
+		f.stored[key] = f.stored[key] || existing[u] || existing[key]
+	}
+	return nil
+}
+
+// CrawlTimeChecker reports when the stored candidate URLs were crawled; db.Client satisfies it
+// URLs that aren't stored are missing from the returned map
+type CrawlTimeChecker interface {
+	GetArticleCrawlTimes(ctx context.Context, candidates []string) (map[string]time.Time, error)
+}
+
+// RecrawlFilter keeps URLs that aren't stored or were crawled longer than its interval ago,
+// so a source checked often (e.g., an hourly feed) only re-fetches articles that are due
+// Unlike DBAlreadyFetchedFilter, stored URLs are fetched again once they are old enough
+type RecrawlFilter struct {
+	store        CrawlTimeChecker
+	recrawlAfter time.Duration
+	batchSize    int
+	now          func() time.Time // Replaced in tests
+}
+
+// NewRecrawlFilter creates a filter that keeps URLs not crawled within interval,
+// looking up their crawl times in store DefaultDBFilterBatchSize URLs at a time
+func NewRecrawlFilter(store CrawlTimeChecker, interval time.Duration) *RecrawlFilter {
+	return &RecrawlFilter{
+		store:        store,
+		recrawlAfter: interval,
+		batchSize:    DefaultDBFilterBatchSize,
+		now:          time.Now,
+	}
+}
+
+// ShouldKeep returns false if the URL was crawled within the filter's interval
+func (f *RecrawlFilter) ShouldKeep(ctx context.Context, urlStr string) (bool, error) {
+	kept, err := f.FilterURLs(ctx, []string{urlStr})
+	if err != nil {
+		return false, err
+	}
+	return len(kept) == 1, nil
+}
+
+// FilterURLs returns the URLs that aren't stored or are due for a recrawl, in their original order
+// Each URL is looked up both as given and normalized, and the most recent crawl of either counts
+func (f *RecrawlFilter) FilterURLs(ctx context.Context, urls []string) ([]string, error) {
+	cutoff := f.now().Add(-f.recrawlAfter)
+	kept := make([]string, 0, len(urls))
+
+	for start := 0; start < len(urls); start += f.batchSize {
+		end := start + f.batchSize
+		if end > len(urls) {
+			end = len(urls)
+		}
+		batch := urls[start:end]
+
+		candidates := make([]string, 0, 2*len(batch))
+		for _, u := range batch {
+			candidates = append(candidates, u)
+			if normalized := NormalizeOrRaw(u); normalized != u {
+				candidates = append(candidates, normalized)
+			}
+		}
+
+		crawled, err := f.store.GetArticleCrawlTimes(ctx, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up crawl times: %w", err)
+		}
+
+		for _, u := range batch {
+			last := crawled[u]
+			if normalized := crawled[NormalizeOrRaw(u)]; normalized.After(last) {
+				last = normalized
+			}
+			if !last.After(cutoff) {
+				kept = append(kept, u)
+			}
+		}
+	}
+	return kept, nil
+}
+
 // ContainsPathFilter filters URLs to only keep those that contain a specific path segment
 type ContainsPathFilter struct {
 	pathSegment string // The path segment to check for (e.g., "/blog")
@@ -1,9 +1,10 @@
 package urls
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"blog-search/pkg/httpclient"
@@ -14,11 +15,23 @@ import (
 // URLExtractor is a function type that extracts URLs from HTML content
 type URLExtractor func(html string) ([]URL, error)
 
+// BaseURLExtractor is like URLExtractor but also receives the URL the HTML was fetched from,
+// so relative links can always be resolved
+type BaseURLExtractor func(html, pageURL string) ([]URL, error)
+
+// IgnoreBase adapts a URLExtractor to a BaseURLExtractor that ignores the page URL
+func IgnoreBase(extractor URLExtractor) BaseURLExtractor {
+	return func(html, pageURL string) ([]URL, error) {
+		return extractor(html)
+	}
+}
+
 // HTMLFetcher handles fetching HTML pages and extracting URLs using a provided extractor
 type HTMLFetcher struct {
-	client     *httpclient.HTTPClient
-	extractor  URLExtractor
-	clientType httpclient.ClientType
+	client        *httpclient.HTTPClient
+	extractor     URLExtractor
+	baseExtractor BaseURLExtractor
+	clientType    httpclient.ClientType
 }
 
 // NewHTMLFetcher creates a new HTML fetcher with the given extractor function
@@ -36,14 +49,44 @@ func NewHTMLFetcherWithClient(extractor URLExtractor, clientType httpclient.Clie
 	}
 }
 
+// NewHTMLFetcherWithBaseExtractor creates a new HTML fetcher whose extractor receives the fetched page URL
+// Uses CloudflareClient like NewHTMLFetcher
+func NewHTMLFetcherWithBaseExtractor(extractor BaseURLExtractor) *HTMLFetcher {
+	return &HTMLFetcher{
+		client:        httpclient.NewClient(httpclient.CloudflareClient),
+		baseExtractor: extractor,
+		clientType:    httpclient.CloudflareClient,
+	}
+}
+
+// SetClientType switches the headers sent with page fetches to those of clientType
+func (f *HTMLFetcher) SetClientType(clientType httpclient.ClientType) {
+	f.client = f.client.WithClientType(clientType)
+	f.clientType = clientType
+}
+
+// SetClientOptions replaces the options of the page fetch client (e.g., its extra headers)
+func (f *HTMLFetcher) SetClientOptions(opts httpclient.ClientOptions) {
+	f.client = f.client.WithOptions(opts)
+}
+
 // Fetch implements URLsFetcher interface - fetches HTML from the given URL and extracts URLs
 func (f *HTMLFetcher) Fetch(url string) ([]URL, error) {
+	result, err := f.FetchWithMeta(url)
+	if err != nil {
+		return nil, err
+	}
+	return result.URLs, nil
+}
+
+// FetchWithMeta is like Fetch, but also returns the page's <title>
+func (f *HTMLFetcher) FetchWithMeta(url string) (*FetchResult, error) {
 	html, err := f.fetchHTML(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
 	}
 
-	urls, err := f.extractURLsFromHTML(html)
+	urls, err := f.extractURLsFromHTML(html, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract URLs: %w", err)
 	}
@@ -52,12 +95,139 @@ func (f *HTMLFetcher) Fetch(url string) ([]URL, error) {
 		return nil, fmt.Errorf("no URLs found in HTML")
 	}
 
-	return urls, nil
+	return newFetchResult(urls, url, SourceHTML, pageTitle(html)), nil
+}
+
+// pageTitle returns the text of the page's <title>, or "" if it has none
+func pageTitle(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return ""
+	}
+	return doc.Find("title").First().Text()
+}
+
+// FetchWithNext fetches the HTML page at pageURL and returns its article URLs together with
+// the page's next-page link (see ExtractNextPageURL), or "" on the last page
+// The next-page link is returned even if extracting the article URLs fails, so callers can
+// skip a page without losing their place
+func (f *HTMLFetcher) FetchWithNext(pageURL string) ([]URL, string, error) {
+	html, err := f.fetchHTML(pageURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch HTML: %w", err)
+	}
+
+	next, _ := ExtractNextPageURL(html, pageURL)
+
+	urls, err := f.extractURLsFromHTML(html, pageURL)
+	if err != nil {
+		return nil, next, fmt.Errorf("failed to extract URLs: %w", err)
+	}
+	return urls, next, nil
+}
+
+// nextPageSelectors find a listing page's "next page" link, most reliable first:
+// the rel="next" hint (in <head> or on the link itself), then common "next" CSS classes
+var nextPageSelectors = []string{
+	"link[rel~='next'][href]",
+	"a[rel~='next'][href]",
+	"a.next[href]",
+	".next a[href]",
+	"a.next-page[href]",
+	".next-page a[href]",
+	".pagination-next a[href]",
+}
+
+// ExtractNextPageURL finds the link to the next page of a listing page (e.g., a blog's
+// category or archive index) and returns it resolved against baseURL
+// Returns false on the last page, i.e. when no selector matches a usable link or the link
+// points back to baseURL
+func ExtractNextPageURL(html, baseURL string) (string, bool) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", false
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", false
+	}
+	if baseHref, exists := doc.Find("base").Attr("href"); exists && baseHref != "" {
+		if ref, err := url.Parse(baseHref); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	for _, selector := range nextPageSelectors {
+		var next string
+		doc.Find(selector).EachWithBreak(func(i int, link *goquery.Selection) bool {
+			next = resolveNextPageLink(base, link.AttrOr("href", ""), baseURL)
+			return next == ""
+		})
+		if next != "" {
+			return next, true
+		}
+	}
+	return "", false
+}
+
+// resolveNextPageLink resolves href against base, returning "" for links that don't lead to
+// another page (empty, fragment-only, javascript: or back to currentURL)
+func resolveNextPageLink(base *url.URL, href, currentURL string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	resolved := base.ResolveReference(ref)
+	resolved.Fragment = ""
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	if current, err := url.Parse(currentURL); err == nil {
+		current.Fragment = ""
+		if resolved.String() == current.String() {
+			return ""
+		}
+	}
+	return resolved.String()
+}
+
+// ExtractCanonicalURL returns the page's <link rel="canonical"> resolved against pageURL
+// Returns false if the page declares no canonical link or it isn't an http(s) URL
+func ExtractCanonicalURL(html, pageURL string) (string, bool) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", false
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+
+	href := strings.TrimSpace(doc.Find(`link[rel~="canonical"]`).First().AttrOr("href", ""))
+	if href == "" {
+		return "", false
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	canonical := base.ResolveReference(ref)
+	canonical.Fragment = ""
+	if canonical.Scheme != "http" && canonical.Scheme != "https" {
+		return "", false
+	}
+	return canonical.String(), true
 }
 
 // fetchHTML fetches the HTML content from the given URL
 func (f *HTMLFetcher) fetchHTML(url string) (string, error) {
-	resp, err := f.client.Get(url)
+	resp, err := f.client.GetWithAccept(context.Background(), url, httpclient.AcceptHTML)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch URL: %w", err)
 	}
@@ -67,7 +237,7 @@ func (f *HTMLFetcher) fetchHTML(url string) (string, error) {
 		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := f.client.ReadBody(resp)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -76,7 +246,11 @@ func (f *HTMLFetcher) fetchHTML(url string) (string, error) {
 }
 
 // extractURLsFromHTML extracts URLs from HTML using the configured extractor
-func (f *HTMLFetcher) extractURLsFromHTML(html string) ([]URL, error) {
+// pageURL is passed to base-aware extractors for resolving relative links
+func (f *HTMLFetcher) extractURLsFromHTML(html, pageURL string) ([]URL, error) {
+	if f.baseExtractor != nil {
+		return f.baseExtractor(html, pageURL)
+	}
 	if f.extractor == nil {
 		return nil, fmt.Errorf("extractor function is not set")
 	}
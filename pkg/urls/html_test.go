@@ -2,6 +2,9 @@ package urls
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -25,3 +28,139 @@ func TestHTMLFetcher_FetchSERadioPage(t *testing.T) {
 	}
 }
 
+
+func TestHTMLFetcher_PassesPageURLToBaseExtractor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body></body></html>")
+	}))
+	defer server.Close()
+
+	var gotPageURL string
+	fetcher := NewHTMLFetcherWithBaseExtractor(func(html, pageURL string) ([]URL, error) {
+		gotPageURL = pageURL
+		return []URL{{Location: pageURL + "/article"}}, nil
+	})
+
+	pageURL := server.URL + "/page/2"
+	if _, err := fetcher.Fetch(pageURL); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if gotPageURL != pageURL {
+		t.Errorf("Expected extractor to receive %s, got %s", pageURL, gotPageURL)
+	}
+}
+
+func TestExtractNextPageURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "rel=next link in head",
+			html:   `<html><head><link rel="prev" href="/category/eng/"><link rel="next" href="/category/eng/page/3/"></head><body></body></html>`,
+			want:   "https://example.com/category/eng/page/3/",
+			wantOK: true,
+		},
+		{
+			name:   "rel=next anchor",
+			html:   `<html><body><a rel="nofollow next" href="?page=3">Older</a></body></html>`,
+			want:   "https://example.com/category/eng/page/2/?page=3",
+			wantOK: true,
+		},
+		{
+			name: ".next a class",
+			html: `<html><body><ul class="pager">
+				<li class="prev"><a href="/category/eng/">Newer</a></li>
+				<li class="next"><a href="/category/eng/page/3/">Older</a></li>
+			</ul></body></html>`,
+			want:   "https://example.com/category/eng/page/3/",
+			wantOK: true,
+		},
+		{
+			name:   "last page without a next link",
+			html:   `<html><body><ul class="pager"><li class="prev"><a href="/category/eng/">Newer</a></li></ul></body></html>`,
+			wantOK: false,
+		},
+		{
+			name:   "next link back to the same page",
+			html:   `<html><body><a class="next" href="/category/eng/page/2/#top">Next</a></body></html>`,
+			wantOK: false,
+		},
+		{
+			name:   "disabled next link",
+			html:   `<html><body><span class="next"><a href="#">Next</a></span></body></html>`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractNextPageURL(tt.html, "https://example.com/category/eng/page/2/")
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestExtractCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "absolute canonical",
+			html:   `<html><head><link rel="canonical" href="https://example.com/post"></head></html>`,
+			want:   "https://example.com/post",
+			wantOK: true,
+		},
+		{
+			name:   "relative canonical",
+			html:   `<html><head><link rel="canonical" href="/post#comments"></head></html>`,
+			want:   "https://example.com/post",
+			wantOK: true,
+		},
+		{
+			name:   "no canonical",
+			html:   `<html><head><title>Post</title></head></html>`,
+			wantOK: false,
+		},
+		{
+			name:   "non-http canonical",
+			html:   `<html><head><link rel="canonical" href="mailto:editor@example.com"></head></html>`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractCanonicalURL(tt.html, "https://example.com/post?ref=rss")
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestHTMLFetcher_AcceptsHTML(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		fmt.Fprint(w, `<html><body><a href="/article">Article</a></body></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := NewHTMLFetcherWithBaseExtractor(func(html, pageURL string) ([]URL, error) {
+		return []URL{{Location: pageURL + "/article"}}, nil
+	})
+	if _, err := fetcher.Fetch(server.URL); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !strings.HasPrefix(accept, "text/html") {
+		t.Errorf("Expected an HTML Accept header, got %q", accept)
+	}
+}
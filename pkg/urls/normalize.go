@@ -0,0 +1,86 @@
+package urls
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a click came from
+var trackingParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+}
+
+// Normalize returns the canonical form of an absolute URL, so that links to the same page
+// compare equal for dedup and storage:
+//   - scheme and host are lowercased, and default ports (:80, :443) are removed
+//   - tracking parameters (utm_*, fbclid, gclid) and the fragment are removed
+//   - the remaining query parameters are sorted
+//   - a trailing slash is removed from the path, except for the root path "/"
+func Normalize(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL %q: %w", raw, err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("URL %q is not absolute", raw)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = normalizeHost(parsed.Scheme, parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	query := parsed.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	parsed.ForceQuery = false
+
+	parsed.Path = canonicalPath(parsed.Path)
+	if parsed.RawPath != "" {
+		parsed.RawPath = canonicalPath(parsed.RawPath)
+	}
+
+	return parsed.String(), nil
+}
+
+// NormalizeOrRaw returns Normalize(raw), or raw unchanged if it can't be normalized
+func NormalizeOrRaw(raw string) string {
+	normalized, err := Normalize(raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// normalizeHost lowercases host and drops the scheme's default port
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case scheme == "http" && strings.HasSuffix(host, ":80"):
+		return strings.TrimSuffix(host, ":80")
+	case scheme == "https" && strings.HasSuffix(host, ":443"):
+		return strings.TrimSuffix(host, ":443")
+	}
+	return host
+}
+
+// canonicalPath removes trailing slashes from path; an empty path becomes the root path "/"
+func canonicalPath(path string) string {
+	trimmed := strings.TrimRight(path, "/")
+	if trimmed == "" {
+		return "/"
+	}
+	return trimmed
+}
+
+// isTrackingParam reports whether a query parameter only carries click tracking data
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
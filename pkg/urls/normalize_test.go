@@ -0,0 +1,96 @@
+package urls
+
+import "testing"
+
+func TestNormalize_EquivalenceClasses(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		variants  []string
+	}{
+		{
+			name:      "trailing slash and tracking params",
+			canonical: "https://x.com/a",
+			variants: []string{
+				"https://x.com/a",
+				"https://x.com/a/",
+				"https://x.com/a?utm_source=rss",
+				"https://x.com/a/?utm_source=rss&utm_medium=feed&fbclid=abc",
+				"https://x.com/a#comments",
+			},
+		},
+		{
+			name:      "host case and default port",
+			canonical: "https://x.com/Post",
+			variants: []string{
+				"https://X.COM/Post",
+				"HTTPS://x.com:443/Post",
+				"https://x.com:443/Post/",
+			},
+		},
+		{
+			name:      "http default port",
+			canonical: "http://x.com/a",
+			variants: []string{
+				"http://x.com:80/a",
+				"http://x.com/a/",
+			},
+		},
+		{
+			name:      "root path",
+			canonical: "https://x.com/",
+			variants: []string{
+				"https://x.com",
+				"https://x.com/",
+				"https://x.com/?utm_campaign=launch",
+			},
+		},
+		{
+			name:      "other query params are kept and sorted",
+			canonical: "https://x.com/search?p=2&q=kafka",
+			variants: []string{
+				"https://x.com/search?q=kafka&p=2",
+				"https://x.com/search/?p=2&utm_source=rss&q=kafka",
+			},
+		},
+		{
+			name:      "non-default port is kept",
+			canonical: "https://x.com:8443/a",
+			variants: []string{
+				"https://x.com:8443/a/",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, variant := range tt.variants {
+				got, err := Normalize(variant)
+				if err != nil {
+					t.Fatalf("Normalize(%q) failed: %v", variant, err)
+				}
+				if got != tt.canonical {
+					t.Errorf("Normalize(%q) = %q, expected %q", variant, got, tt.canonical)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize_DistinctPagesStayDistinct(t *testing.T) {
+	a, _ := Normalize("https://x.com/a")
+	b, _ := Normalize("https://x.com/b")
+	page2, _ := Normalize("https://x.com/a?page=2")
+	if a == b || a == page2 {
+		t.Errorf("Expected distinct pages to stay distinct, got %q, %q and %q", a, b, page2)
+	}
+}
+
+func TestNormalize_RejectsRelativeURLs(t *testing.T) {
+	if _, err := Normalize("/a/b"); err == nil {
+		t.Error("Expected an error for a relative URL")
+	}
+	if got := NormalizeOrRaw("/a/b"); got != "/a/b" {
+		t.Errorf("Expected NormalizeOrRaw to return the raw URL, got %q", got)
+	}
+}
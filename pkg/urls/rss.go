@@ -1,26 +1,80 @@
 package urls
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
+	"blog-search/pkg/httpclient"
+	"blog-search/pkg/logging"
+
+	"github.com/PuerkitoBio/goquery"
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/atom"
+	ext "github.com/mmcdole/gofeed/extensions"
 )
 
+// DefaultMaxFeedPages bounds how many pages of an RFC 5005 paginated feed Fetch follows
+const DefaultMaxFeedPages = 10
+
+// nextPageKey is the gofeed.Feed.Custom key holding an Atom feed's rel="next" link
+const nextPageKey = "next"
+
 // RSSParser handles RSS/Atom feed parsing operations
 type RSSParser struct {
 	feedParser *gofeed.Parser
+	client     *httpclient.HTTPClient // Fetches feeds, and HTML pages during feed autodiscovery
+	maxPages   int
 }
 
 // NewRSSParser creates a new RSS parser
 func NewRSSParser() *RSSParser {
+	feedParser := gofeed.NewParser()
+	feedParser.AtomTranslator = &pagedAtomTranslator{}
+
 	return &RSSParser{
-		feedParser: gofeed.NewParser(),
+		feedParser: feedParser,
+		client:     httpclient.NewClient(httpclient.CloudflareClient),
+		maxPages:   DefaultMaxFeedPages,
 	}
 }
 
+// SetClientOptions replaces the options of the client that fetches feeds and autodiscovery pages
+func (p *RSSParser) SetClientOptions(opts httpclient.ClientOptions) {
+	p.client = p.client.WithOptions(opts)
+}
+
+// SetMaxPages sets how many feed pages Fetch follows via rel="next" links; 1 disables paging
+func (p *RSSParser) SetMaxPages(maxPages int) {
+	if maxPages < 1 {
+		maxPages = 1
+	}
+	p.maxPages = maxPages
+}
+
 // Fetch fetches and parses an RSS/Atom feed from the given URL
+// Feeds paginated with rel="next" links (RFC 5005) are followed up to the parser's max pages,
+// and entries from all pages are returned
+// If feedURL serves an HTML page (e.g., a blog homepage), the feed it links to is used instead
 func (p *RSSParser) Fetch(feedURL string) ([]URL, error) {
-	feed, err := p.feedParser.ParseURL(feedURL)
+	result, err := p.FetchWithMeta(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	return result.URLs, nil
+}
+
+// FetchWithMeta is like Fetch, but also returns the feed's title and the URL of the feed that was
+// parsed, which differs from feedURL when the feed was discovered from an HTML page
+func (p *RSSParser) FetchWithMeta(feedURL string) (*FetchResult, error) {
+	feed, err := p.parseFeedURL(feedURL)
+	if errors.Is(err, gofeed.ErrFeedTypeNotDetected) {
+		feedURL, feed, err = p.parseDiscoveredFeed(feedURL)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
 	}
@@ -29,20 +83,246 @@ func (p *RSSParser) Fetch(feedURL string) ([]URL, error) {
 		return nil, fmt.Errorf("feed contains no items")
 	}
 
+	urls := feedItemURLs(feed)
+	urls = append(urls, p.fetchNextPages(feedURL, feed)...)
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no valid URLs found in feed items")
+	}
+
+	return newFetchResult(urls, feedURL, SourceRSS, feed.Title), nil
+}
+
+// parseDiscoveredFeed fetches the HTML page at pageURL and parses the feed it advertises
+// Returns the discovered feed URL along with the feed
+func (p *RSSParser) parseDiscoveredFeed(pageURL string) (string, *gofeed.Feed, error) {
+	html, err := p.fetchPage(pageURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("not a feed and failed to fetch page for feed discovery: %w", err)
+	}
+
+	feedURL, err := DiscoverFeedURL(html, pageURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("not a feed: %w", err)
+	}
+	logging.Infof("RSSParser: %s is not a feed, using discovered feed %s", pageURL, feedURL)
+
+	feed, err := p.parseFeedURL(feedURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return feedURL, feed, nil
+}
+
+// parseFeedURL fetches the feed at feedURL, asking for XML, and parses it
+func (p *RSSParser) parseFeedURL(feedURL string) (*gofeed.Feed, error) {
+	body, err := p.fetch(feedURL, httpclient.AcceptXML)
+	if err != nil {
+		return nil, err
+	}
+	return p.feedParser.Parse(bytes.NewReader(body))
+}
+
+// fetchPage fetches the HTML of a page that didn't parse as a feed
+func (p *RSSParser) fetchPage(pageURL string) (string, error) {
+	body, err := p.fetch(pageURL, httpclient.AcceptHTML)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// fetch returns the body of the document at rawURL, requested with the given Accept header
+func (p *RSSParser) fetch(rawURL, accept string) ([]byte, error) {
+	resp, err := p.client.GetWithAccept(context.Background(), rawURL, accept)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := p.client.ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
+
+// DiscoverFeedURL finds the RSS or Atom feed advertised by an HTML page
+// (<link rel="alternate" type="application/rss+xml" href="...">) and returns it resolved against baseURL
+// The first advertised feed wins; an error is returned if the page advertises none
+func DiscoverFeedURL(html, baseURL string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var href string
+	doc.Find("link[rel~='alternate'][href]").EachWithBreak(func(i int, link *goquery.Selection) bool {
+		if !isFeedMediaType(link.AttrOr("type", "")) {
+			return true
+		}
+		href = strings.TrimSpace(link.AttrOr("href", ""))
+		return href == ""
+	})
+	if href == "" {
+		return "", fmt.Errorf("no RSS or Atom feed link found")
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed link %q: %w", href, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// isFeedMediaType reports whether a <link> type names an RSS or Atom feed
+func isFeedMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+	return mediaType == "application/rss+xml" || mediaType == "application/atom+xml"
+}
+
+// fetchNextPages follows the rel="next" links starting at the first page's feed
+// A page that fails to load ends paging; entries already collected are kept
+func (p *RSSParser) fetchNextPages(feedURL string, feed *gofeed.Feed) []URL {
+	var urls []URL
+	seen := map[string]bool{feedURL: true}
+	pageURL := feedURL
+
+	for page := 2; page <= p.maxPages; page++ {
+		next := nextPageURL(pageURL, feed)
+		if next == "" || seen[next] {
+			break
+		}
+		seen[next] = true
+		pageURL = next
+
+		var err error
+		feed, err = p.parseFeedURL(pageURL)
+		if err != nil {
+			logging.Warnf("RSSParser: Stopping at feed page %d (%s): %v", page, pageURL, err)
+			break
+		}
+		urls = append(urls, feedItemURLs(feed)...)
+	}
+	return urls
+}
+
+// feedItemURLs returns the links of a feed's items, skipping items without one
+func feedItemURLs(feed *gofeed.Feed) []URL {
 	urls := make([]URL, 0, len(feed.Items))
 	for _, item := range feed.Items {
 		if item.Link != "" {
-			url := URL{
+			urls = append(urls, URL{
 				Location: item.Link,
 				Title:    item.Title,
+				Summary:  feedItemSummary(item),
+				Content:  strings.TrimSpace(item.Content),
+
+				EnclosureURL: feedItemEnclosureURL(item),
+			})
+		}
+	}
+	return urls
+}
+
+// feedItemSummary returns the item's RSS <description> or Atom <summary> as plain text
+// Descriptions often carry HTML markup, which is stripped
+func feedItemSummary(item *gofeed.Item) string {
+	description := strings.TrimSpace(item.Description)
+	if description == "" {
+		return ""
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(description))
+	if err != nil {
+		return description
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+// feedItemEnclosureURL returns the URL of the item's media file, or "" if there is none
+// It prefers <enclosure> (Atom: <link rel="enclosure">) and falls back to <media:content>,
+// also looking inside <media:group>
+func feedItemEnclosureURL(item *gofeed.Item) string {
+	for _, enclosure := range item.Enclosures {
+		if enclosure.URL != "" {
+			return enclosure.URL
+		}
+	}
+
+	media := item.Extensions["media"]
+	contents := append([]ext.Extension{}, media["content"]...)
+	for _, group := range media["group"] {
+		contents = append(contents, group.Children["content"]...)
+	}
+	for _, content := range contents {
+		if href := content.Attrs["url"]; href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+// nextPageURL returns the feed's rel="next" link resolved against pageURL, or "" if there is none
+// Atom feeds carry it as a <link>; RSS feeds as an <atom:link> extension
+func nextPageURL(pageURL string, feed *gofeed.Feed) string {
+	next := feed.Custom[nextPageKey]
+	if next == "" {
+		for _, link := range feed.Extensions["atom"]["link"] {
+			if link.Attrs["rel"] == "next" {
+				next = link.Attrs["href"]
+				break
 			}
-			urls = append(urls, url)
 		}
 	}
+	if next == "" {
+		return ""
+	}
 
-	if len(urls) == 0 {
-		return nil, fmt.Errorf("no valid URLs found in feed items")
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return ""
 	}
+	return base.ResolveReference(ref).String()
+}
+
+// pagedAtomTranslator keeps the rel="next" link, which gofeed's default translator drops
+type pagedAtomTranslator struct {
+	gofeed.DefaultAtomTranslator
+}
 
-	return urls, nil
+// Translate converts an Atom feed like the default translator and records its next-page link
+func (t *pagedAtomTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultAtomTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if atomFeed, ok := feed.(*atom.Feed); ok {
+		for _, link := range atomFeed.Links {
+			if link.Rel == "next" && link.Href != "" {
+				if result.Custom == nil {
+					result.Custom = make(map[string]string)
+				}
+				result.Custom[nextPageKey] = link.Href
+				break
+			}
+		}
+	}
+	return result, nil
 }
@@ -1,8 +1,11 @@
 package urls
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -212,3 +215,353 @@ func TestRSSParser_ParseFromURL_DatadogFormat(t *testing.T) {
 		}
 	}
 }
+
+// pagedAtomFeed returns an Atom feed page with the given entry slugs and optional rel="next" link
+func pagedAtomFeed(next string, slugs ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Paged Feed</title>`)
+	if next != "" {
+		b.WriteString(`<link rel="next" href="` + next + `"/>`)
+	}
+	for _, slug := range slugs {
+		b.WriteString(`<entry><title>` + slug + `</title><link href="https://example.com/` + slug + `"/></entry>`)
+	}
+	b.WriteString(`</feed>`)
+	return b.String()
+}
+
+func TestRSSParser_Fetch_FollowsAtomNextLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(pagedAtomFeed("", "post-3")))
+			return
+		}
+		w.Write([]byte(pagedAtomFeed("/feed?page=2", "post-1", "post-2")))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	urls, err := NewRSSParser().Fetch(server.URL + "/feed")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var got []string
+	for _, u := range urls {
+		got = append(got, u.Location)
+	}
+	want := []string{"https://example.com/post-1", "https://example.com/post-2", "https://example.com/post-3"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected entries from both pages %v, got %v", want, got)
+	}
+}
+
+func TestRSSParser_Fetch_StopsAtMaxPages(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		// Every page links to another one, so only the page limit ends paging
+		w.Write([]byte(pagedAtomFeed(fmt.Sprintf("/feed?page=%d", n+1), fmt.Sprintf("post-%d", n))))
+	}))
+	defer server.Close()
+
+	parser := NewRSSParser()
+	parser.SetMaxPages(3)
+	urls, err := parser.Fetch(server.URL + "/feed")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(urls) != 3 || requests.Load() != 3 {
+		t.Errorf("Expected 3 pages to be fetched, got %d entries from %d requests", len(urls), requests.Load())
+	}
+}
+
+func TestRSSParser_Fetch_ParsesSummaries(t *testing.T) {
+	feeds := map[string]string{
+		"/rss": `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Blog</title>
+	<item>
+		<title>RSS Post</title>
+		<link>https://example.com/rss-post</link>
+		<description><![CDATA[<p>How we <b>sharded</b> Postgres.</p>]]></description>
+	</item>
+	<item>
+		<title>No Description</title>
+		<link>https://example.com/bare</link>
+	</item>
+</channel></rss>`,
+		"/atom": `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Blog</title>
+	<entry>
+		<title>Atom Post</title>
+		<link href="https://example.com/atom-post"/>
+		<summary>Scaling Kafka consumers.</summary>
+	</entry>
+</feed>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feeds[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	parser := NewRSSParser()
+
+	rssURLs, err := parser.Fetch(server.URL + "/rss")
+	if err != nil {
+		t.Fatalf("Failed to parse RSS feed: %v", err)
+	}
+	if len(rssURLs) != 2 {
+		t.Fatalf("Expected 2 RSS items, got %d", len(rssURLs))
+	}
+	if rssURLs[0].Summary != "How we sharded Postgres." {
+		t.Errorf("Expected plain-text RSS description, got %q", rssURLs[0].Summary)
+	}
+	if rssURLs[1].Summary != "" {
+		t.Errorf("Expected no summary for an item without description, got %q", rssURLs[1].Summary)
+	}
+
+	atomURLs, err := parser.Fetch(server.URL + "/atom")
+	if err != nil {
+		t.Fatalf("Failed to parse Atom feed: %v", err)
+	}
+	if len(atomURLs) != 1 || atomURLs[0].Summary != "Scaling Kafka consumers." {
+		t.Errorf("Expected the Atom summary, got %+v", atomURLs)
+	}
+}
+
+func TestRSSParser_Fetch_ParsesContentEncoded(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel><title>Blog</title>
+	<item>
+		<title>Full Post</title>
+		<link>https://example.com/full</link>
+		<description>Short teaser.</description>
+		<content:encoded><![CDATA[<p>The whole post about <b>sharding</b>.</p>]]></content:encoded>
+	</item>
+	<item>
+		<title>Teaser Only</title>
+		<link>https://example.com/teaser</link>
+		<description>Read more on the blog.</description>
+	</item>
+</channel></rss>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	urls, err := NewRSSParser().Fetch(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse RSS feed: %v", err)
+	}
+	if len(urls) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(urls))
+	}
+	if urls[0].Content != "<p>The whole post about <b>sharding</b>.</p>" {
+		t.Errorf("Expected the content:encoded HTML, got %q", urls[0].Content)
+	}
+	if urls[0].Summary != "Short teaser." {
+		t.Errorf("Expected the description as summary, got %q", urls[0].Summary)
+	}
+	if urls[1].Content != "" {
+		t.Errorf("Expected no content for an item without content:encoded, got %q", urls[1].Content)
+	}
+}
+
+func TestRSSParser_Fetch_ParsesEnclosures(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel><title>Podcast</title>
+	<item>
+		<title>Episode 1</title>
+		<link>https://example.com/ep1</link>
+		<enclosure url="https://cdn.example.com/ep1.mp3" length="1234" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Episode 2</title>
+		<link>https://example.com/ep2</link>
+		<media:content url="https://cdn.example.com/ep2.mp3" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Episode 3</title>
+		<link>https://example.com/ep3</link>
+		<media:group><media:content url="https://cdn.example.com/ep3.mp3" type="audio/mpeg"/></media:group>
+	</item>
+	<item>
+		<title>Show Notes</title>
+		<link>https://example.com/notes</link>
+	</item>
+</channel></rss>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	urls, err := NewRSSParser().Fetch(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse RSS feed: %v", err)
+	}
+	want := []string{
+		"https://cdn.example.com/ep1.mp3",
+		"https://cdn.example.com/ep2.mp3",
+		"https://cdn.example.com/ep3.mp3",
+		"",
+	}
+	if len(urls) != len(want) {
+		t.Fatalf("Expected %d items, got %d", len(want), len(urls))
+	}
+	for i, u := range urls {
+		if u.EnclosureURL != want[i] {
+			t.Errorf("Item %d: expected enclosure %q, got %q", i, want[i], u.EnclosureURL)
+		}
+	}
+}
+
+func TestDiscoverFeedURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "RSS alternate link",
+			html: `<html><head>
+				<link rel="alternate" type="text/html" hreflang="fr" href="/fr/">
+				<link rel="alternate" type="application/rss+xml" title="Blog" href="/feed.xml">
+			</head><body></body></html>`,
+			want: "https://example.com/feed.xml",
+		},
+		{
+			name: "Atom alternate link",
+			html: `<html><head>
+				<link rel="alternate" type="application/atom+xml" href="https://feeds.example.com/atom">
+			</head><body></body></html>`,
+			want: "https://feeds.example.com/atom",
+		},
+		{
+			name:    "no feed link",
+			html:    `<html><head><link rel="stylesheet" href="/style.css"></head><body></body></html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiscoverFeedURL(tt.html, "https://example.com/blog/")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got feed URL %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiscoverFeedURL failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRSSParser_Fetch_DiscoversFeedFromHTMLPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/feed.xml"></head>
+				<body><h1>My Blog</h1></body></html>`))
+		case "/feed.xml":
+			w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Blog</title>
+	<item><title>Post</title><link>https://example.com/post</link></item>
+</channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	urls, err := NewRSSParser().Fetch(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(urls) != 1 || urls[0].Location != "https://example.com/post" {
+		t.Errorf("Expected the discovered feed's item, got %+v", urls)
+	}
+}
+
+func TestRSSParser_FetchWithMeta_ReportsFeedTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/feed.xml"></head></html>`))
+		case "/feed.xml":
+			w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title> The Monitor blog </title>
+	<item><title>Post 1</title><link>https://example.com/post-1</link></item>
+	<item><title>Post 2</title><link>https://example.com/post-2</link></item>
+</channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	result, err := NewRSSParser().FetchWithMeta(server.URL + "/")
+	if err != nil {
+		t.Fatalf("FetchWithMeta failed: %v", err)
+	}
+	if result.Title != "The Monitor blog" {
+		t.Errorf("Expected the feed title, got %q", result.Title)
+	}
+	if result.SourceType != SourceRSS || result.SourceURL != server.URL+"/feed.xml" {
+		t.Errorf("Expected the discovered feed as the rss source, got %s %s", result.SourceType, result.SourceURL)
+	}
+	if result.Count != 2 || len(result.URLs) != 2 || result.URLs[1].Location != "https://example.com/post-2" {
+		t.Errorf("Expected the feed's 2 items, got %d: %+v", result.Count, result.URLs)
+	}
+}
+
+func TestRSSParser_AcceptsXMLForFeedsAndHTMLForPages(t *testing.T) {
+	accepts := make(map[string][]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		accepts[r.URL.Path] = append(accepts[r.URL.Path], r.Header.Get("Accept"))
+		fmt.Fprint(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/feed"></head><body></body></html>`)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		accepts[r.URL.Path] = append(accepts[r.URL.Path], r.Header.Get("Accept"))
+		// Like a CDN that only serves the feed to clients asking for XML
+		if !strings.Contains(r.Header.Get("Accept"), "application/xml") {
+			fmt.Fprint(w, `{"error": "not acceptable"}`)
+			return
+		}
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title><item><link>https://example.com/a</link></item></channel></rss>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if _, err := NewRSSParser().Fetch(server.URL + "/feed"); err != nil {
+		t.Fatalf("Fetch of the feed failed: %v", err)
+	}
+	if _, err := NewRSSParser().Fetch(server.URL + "/"); err != nil {
+		t.Fatalf("Fetch via feed discovery failed: %v", err)
+	}
+
+	for i, accept := range accepts["/feed"] {
+		if !strings.HasPrefix(accept, "application/xml") {
+			t.Errorf("Feed request %d: expected an XML Accept header, got %q", i+1, accept)
+		}
+	}
+	// The page is first tried as a feed, then fetched as HTML to discover the feed
+	page := accepts["/"]
+	if len(page) != 2 || !strings.HasPrefix(page[0], "application/xml") || !strings.HasPrefix(page[1], "text/html") {
+		t.Errorf("Expected the page to be requested as XML, then HTML, got %q", page)
+	}
+}
@@ -0,0 +1,151 @@
+package urls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SeenStore persists the set of URLs already attempted, so repeated runs can skip them
+type SeenStore interface {
+	// MarkSeen records that the URL was attempted now
+	MarkSeen(ctx context.Context, url string) error
+	// SeenWithin returns true if the URL was attempted within the given TTL
+	SeenWithin(ctx context.Context, url string, ttl time.Duration) (bool, error)
+	// Flush persists entries buffered by MarkSeen; stores that write immediately return nil
+	Flush(ctx context.Context) error
+}
+
+// FileSeenStore is a SeenStore backed by a JSON file (URL -> last seen time)
+// Entries are kept in memory and written to the file by Flush, once per run
+type FileSeenStore struct {
+	path  string
+	seen  map[string]time.Time
+	dirty bool
+	mu    sync.Mutex
+	now   func() time.Time
+}
+
+// NewFileSeenStore creates a file-backed seen store, loading existing entries from path if present
+func NewFileSeenStore(path string) (*FileSeenStore, error) {
+	store := &FileSeenStore{
+		path: path,
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seen store: %w", err)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.seen); err != nil {
+			return nil, fmt.Errorf("failed to decode seen store: %w", err)
+		}
+	}
+
+	return store, nil
+}
+
+// MarkSeen records the URL as seen now; the entry is written to disk by the next Flush
+func (s *FileSeenStore) MarkSeen(ctx context.Context, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen[url] = s.now()
+	s.dirty = true
+	return nil
+}
+
+// SeenWithin returns true if the URL was seen less than ttl ago
+func (s *FileSeenStore) SeenWithin(ctx context.Context, url string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seenAt, ok := s.seen[url]
+	if !ok {
+		return false, nil
+	}
+	return s.now().Sub(seenAt) < ttl, nil
+}
+
+// Flush writes the store to its file if anything was marked since the last flush
+// The file is replaced atomically (temp file + rename), so a crash mid-write keeps the previous contents
+func (s *FileSeenStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.seen)
+	if err != nil {
+		return fmt.Errorf("failed to encode seen store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write seen store: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write seen store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write seen store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace seen store: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// SeenFilter filters out URLs that were attempted within the TTL
+// It only checks the store; callers mark a URL with MarkSeen once its attempt has a final
+// outcome, so URLs that failed for a transient reason are tried again on the next run
+type SeenFilter struct {
+	store SeenStore
+	ttl   time.Duration
+}
+
+// NewSeenFilter creates a new seen filter backed by the given store
+func NewSeenFilter(store SeenStore, ttl time.Duration) *SeenFilter {
+	return &SeenFilter{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// ShouldKeep returns false if the URL was seen within the TTL
+// URLs are looked up in normalized form (see Normalize)
+func (f *SeenFilter) ShouldKeep(ctx context.Context, urlStr string) (bool, error) {
+	seen, err := f.store.SeenWithin(ctx, NormalizeOrRaw(urlStr), f.ttl)
+	if err != nil {
+		return false, err
+	}
+	return !seen, nil
+}
+
+// MarkSeen records the URL (in normalized form) as attempted now
+func (f *SeenFilter) MarkSeen(ctx context.Context, urlStr string) error {
+	return f.store.MarkSeen(ctx, NormalizeOrRaw(urlStr))
+}
+
+// Flush persists the URLs marked since the last flush
+func (f *SeenFilter) Flush(ctx context.Context) error {
+	return f.store.Flush(ctx)
+}
@@ -0,0 +1,122 @@
+package urls
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// runSeenFilter loads the seen store from path as a run at now would, and returns whether
+// url passes the filter; kept URLs are marked and the store is flushed, like a finished run
+func runSeenFilter(t *testing.T, path, url string, ttl time.Duration, now time.Time) bool {
+	t.Helper()
+	ctx := context.Background()
+
+	store, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSeenStore failed: %v", err)
+	}
+	store.now = func() time.Time { return now }
+	filter := NewSeenFilter(store, ttl)
+
+	keep, err := filter.ShouldKeep(ctx, url)
+	if err != nil {
+		t.Fatalf("ShouldKeep failed: %v", err)
+	}
+	if keep {
+		if err := filter.MarkSeen(ctx, url); err != nil {
+			t.Fatalf("MarkSeen failed: %v", err)
+		}
+	}
+	if err := filter.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	return keep
+}
+
+func TestSeenFilter_SkipsWithinTTLAndRetriesAfter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.json")
+	ttl := time.Hour
+	url := "https://example.com/article1"
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Run 1: URL is new and should be kept
+	if !runSeenFilter(t, path, url, ttl, now) {
+		t.Fatal("Expected URL to be kept on first run")
+	}
+
+	// Run 2 (within TTL): reload from disk, URL should be skipped
+	if runSeenFilter(t, path, url, ttl, now.Add(30*time.Minute)) {
+		t.Fatal("Expected URL to be skipped within TTL")
+	}
+
+	// Run 3 (after TTL): URL should be retried
+	if !runSeenFilter(t, path, url, ttl, now.Add(2*time.Hour)) {
+		t.Fatal("Expected URL to be retried after TTL")
+	}
+}
+
+func TestSeenFilter_ShouldKeepDoesNotMark(t *testing.T) {
+	store, err := NewFileSeenStore(filepath.Join(t.TempDir(), "seen.json"))
+	if err != nil {
+		t.Fatalf("NewFileSeenStore failed: %v", err)
+	}
+	filter := NewSeenFilter(store, time.Hour)
+	ctx := context.Background()
+
+	// A URL that was never marked (e.g., its fetch failed transiently) is kept again
+	for i := 0; i < 2; i++ {
+		if keep, err := filter.ShouldKeep(ctx, "https://example.com/a"); err != nil || !keep {
+			t.Fatalf("Expected unmarked URL to be kept, got %v, %v", keep, err)
+		}
+	}
+
+	if err := filter.MarkSeen(ctx, "https://example.com/a/?utm_source=rss"); err != nil {
+		t.Fatalf("MarkSeen failed: %v", err)
+	}
+	if keep, _ := filter.ShouldKeep(ctx, "https://example.com/a"); keep {
+		t.Error("Expected the normalized URL to be skipped after MarkSeen")
+	}
+}
+
+func TestFileSeenStore_WritesOnlyOnFlush(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "seen.json")
+	ctx := context.Background()
+
+	store, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSeenStore failed: %v", err)
+	}
+	for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		if err := store.MarkSeen(ctx, url); err != nil {
+			t.Fatalf("MarkSeen failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected no file before Flush, got %v", err)
+	}
+
+	if err := store.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	reloaded, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSeenStore failed: %v", err)
+	}
+	if seen, _ := reloaded.SeenWithin(ctx, "https://example.com/b", time.Hour); !seen {
+		t.Error("Expected flushed entries to be reloaded")
+	}
+
+	// Only the store file is left behind, not the temp file it was written to
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the store file, got %d entries", len(entries))
+	}
+}
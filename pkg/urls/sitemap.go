@@ -1,28 +1,79 @@
 package urls
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+
+	"blog-search/pkg/httpclient"
+	"blog-search/pkg/logging"
 )
 
 // SitemapParser handles sitemap parsing operations
 type SitemapParser struct {
-	client *http.Client
+	client *httpclient.HTTPClient
 }
 
 // NewSitemapParser creates a new sitemap parser
 func NewSitemapParser() *SitemapParser {
 	return &SitemapParser{
-		client: &http.Client{},
+		client: httpclient.NewClient(httpclient.CloudflareClient),
 	}
 }
 
+// SetClientOptions replaces the options of the client that fetches sitemaps
+func (p *SitemapParser) SetClientOptions(opts httpclient.ClientOptions) {
+	p.client = p.client.WithOptions(opts)
+}
+
+// maxSitemapDepth bounds how many sitemap indexes may be nested below the one fetched
+const maxSitemapDepth = 5
+
+var (
+	// ErrSitemapCycle is returned when a sitemap index lists itself or one of the indexes above it
+	ErrSitemapCycle = errors.New("sitemap index cycle")
+
+	// ErrSitemapTooDeep is returned when sitemap indexes are nested more than maxSitemapDepth levels
+	ErrSitemapTooDeep = errors.New("sitemap indexes nested too deep")
+)
+
 // Fetch fetches and parses a sitemap from the given URL
+// For a sitemap index, the entries of its sitemaps are combined without duplicates
+// An index that refers back to itself or nests too deep fails with ErrSitemapCycle or ErrSitemapTooDeep
 func (p *SitemapParser) Fetch(url string) ([]URL, error) {
-	resp, err := p.client.Get(url)
+	return p.fetch(url, 0, make(map[string]bool))
+}
+
+// enterSitemap marks url as being read, failing if it is nested too deep or is already being read
+// visited maps each sitemap seen so far to whether it is still being read, i.e., is an index above url
+func enterSitemap(url string, depth int, visited map[string]bool) error {
+	if depth > maxSitemapDepth {
+		return fmt.Errorf("%w: %s is more than %d levels deep", ErrSitemapTooDeep, url, maxSitemapDepth)
+	}
+	if visited[url] {
+		return fmt.Errorf("%w: %s refers back to itself", ErrSitemapCycle, url)
+	}
+	visited[url] = true
+	return nil
+}
+
+// isSitemapLimitError reports whether err stops a whole sitemap index rather than one of its sitemaps
+func isSitemapLimitError(err error) bool {
+	return errors.Is(err, ErrSitemapCycle) || errors.Is(err, ErrSitemapTooDeep)
+}
+
+// fetch is Fetch for a sitemap depth indexes below the one fetched
+func (p *SitemapParser) fetch(url string, depth int, visited map[string]bool) ([]URL, error) {
+	if err := enterSitemap(url, depth, visited); err != nil {
+		return nil, err
+	}
+	defer func() { visited[url] = false }()
+
+	resp, err := p.client.GetWithAccept(context.Background(), url, httpclient.AcceptXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch sitemap: %w", err)
 	}
@@ -53,16 +104,32 @@ func (p *SitemapParser) Fetch(url string) ([]URL, error) {
 			return nil, fmt.Errorf("sitemap index contained no sitemap URLs")
 		}
 
-		// Parse all sitemaps in the index and combine their entries
+		// Parse all sitemaps in the index and combine their entries in document order
+		// Sitemaps often overlap (e.g., posts listed by date and by category), so each location
+		// is kept once, where it first appears; repeated runs produce the same list
 		var allURLs []URL
+		seen := make(map[string]bool)
 		for _, sitemapURL := range sitemapURLs {
-			urls, err := p.Fetch(sitemapURL)
+			if reading, ok := visited[sitemapURL]; ok && !reading {
+				// Already fetched from this or another index
+				continue
+			}
+
+			urls, err := p.fetch(sitemapURL, depth+1, visited)
+			if isSitemapLimitError(err) {
+				return nil, err
+			}
 			if err != nil {
-				// Log error but continue with other sitemaps
-				// TODO: Consider adding a logger or error collection mechanism
+				// The other sitemaps are still used
+				logging.Warnf("SitemapParser: Skipping sitemap %s from index %s: %v", sitemapURL, url, err)
 				continue
 			}
-			allURLs = append(allURLs, urls...)
+			for _, u := range urls {
+				if !seen[u.Location] {
+					seen[u.Location] = true
+					allURLs = append(allURLs, u)
+				}
+			}
 		}
 
 		if len(allURLs) == 0 {
@@ -76,6 +143,151 @@ func (p *SitemapParser) Fetch(url string) ([]URL, error) {
 	return p.parseSitemap(reader)
 }
 
+// FetchWithMeta is like Fetch, but returns the entries as a FetchResult; sitemaps have no title
+func (p *SitemapParser) FetchWithMeta(url string) (*FetchResult, error) {
+	urls, err := p.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	return newFetchResult(urls, url, SourceSitemap, ""), nil
+}
+
+// Stream fetches the sitemap at url and calls emit for each entry while the document is being
+// parsed, so huge sitemaps are never held in memory. The entries of a sitemap index's sitemaps
+// are streamed in turn; sitemaps in the index that fail to load are skipped, as in Fetch
+// Stops with emit's error if it returns one
+func (p *SitemapParser) Stream(ctx context.Context, url string, emit func(URL) error) error {
+	state := &sitemapStream{emit: emit, visited: make(map[string]bool)}
+	err := p.stream(ctx, url, 0, state)
+	if state.emitErr != nil {
+		return state.emitErr
+	}
+	return err
+}
+
+// sitemapStream is the state of a Stream call shared by the sitemaps of an index
+type sitemapStream struct {
+	emit    func(URL) error
+	emitErr error // First error returned by emit; stops the stream
+	emitted int
+	visited map[string]bool // See enterSitemap
+}
+
+// emitURL passes u to emit and counts it
+func (s *sitemapStream) emitURL(u URL) error {
+	if err := s.emit(u); err != nil {
+		s.emitErr = err
+		return err
+	}
+	s.emitted++
+	return nil
+}
+
+// stream streams the sitemap at url, depth indexes below the one streamed, recursing into the
+// sitemaps of a sitemap index
+func (p *SitemapParser) stream(ctx context.Context, url string, depth int, state *sitemapStream) error {
+	if err := enterSitemap(url, depth, state.visited); err != nil {
+		return err
+	}
+	defer func() { state.visited[url] = false }()
+
+	resp, err := p.client.GetWithAccept(ctx, url, httpclient.AcceptXML)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sitemap: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	sitemapURLs, err := streamSitemap(resp.Body, state.emitURL)
+	resp.Body.Close()
+	if err != nil || sitemapURLs == nil {
+		return err
+	}
+
+	if len(sitemapURLs) == 0 {
+		return fmt.Errorf("sitemap index contained no sitemap URLs")
+	}
+	before := state.emitted
+	for _, sitemapURL := range sitemapURLs {
+		if reading, ok := state.visited[sitemapURL]; ok && !reading {
+			continue
+		}
+		// Sitemaps that fail to load are skipped, unless the stream was stopped or the index is broken
+		err := p.stream(ctx, sitemapURL, depth+1, state)
+		if err != nil && (state.emitErr != nil || ctx.Err() != nil || isSitemapLimitError(err)) {
+			return err
+		}
+	}
+	if state.emitted == before {
+		return fmt.Errorf("no entries found in any sitemap from index")
+	}
+	return nil
+}
+
+// streamSitemap reads a sitemap or sitemap index token by token
+// Calls emit for each entry of a regular sitemap and returns nil sitemap URLs; for a sitemap
+// index, returns the locations of its sitemaps (non-nil, possibly empty) without calling emit
+func streamSitemap(reader io.Reader, emit func(URL) error) ([]string, error) {
+	decoder := xml.NewDecoder(reader)
+	var sitemapURLs []string
+	root := ""
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode sitemap XML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if root == "" {
+			root = start.Name.Local
+			switch root {
+			case "urlset":
+			case "sitemapindex":
+				sitemapURLs = []string{}
+			default:
+				return nil, fmt.Errorf("failed to decode sitemap XML: unexpected root element <%s>", root)
+			}
+			continue
+		}
+
+		switch {
+		case root == "urlset" && start.Name.Local == "url":
+			var entry urlEntry
+			if err := decoder.DecodeElement(&entry, &start); err != nil {
+				return nil, fmt.Errorf("failed to decode sitemap XML: %w", err)
+			}
+			if entry.Location != "" {
+				if err := emit(URL{Location: entry.Location}); err != nil {
+					return nil, err
+				}
+			}
+		case root == "sitemapindex" && start.Name.Local == "sitemap":
+			var ref sitemapRef
+			if err := decoder.DecodeElement(&ref, &start); err != nil {
+				return nil, fmt.Errorf("failed to decode sitemap index XML: %w", err)
+			}
+			if ref.Location != "" {
+				sitemapURLs = append(sitemapURLs, ref.Location)
+			}
+		}
+	}
+
+	if root == "" {
+		return nil, fmt.Errorf("failed to decode sitemap XML: %w", io.ErrUnexpectedEOF)
+	}
+	return sitemapURLs, nil
+}
+
 // parseSitemapIndex parses a sitemap index file
 func (p *SitemapParser) parseSitemapIndex(reader io.Reader) ([]string, error) {
 	var index sitemapIndex
@@ -1,8 +1,13 @@
 package urls
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -202,3 +207,242 @@ func TestSitemapParser_ParseFromURL_SitemapIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestSitemapParser_Fetch_SitemapIndexDedupsAcrossSitemaps(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/sitemap-index.xml":
+			// posts.xml is listed twice and missing.xml fails to load
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>` + serverURL + `/posts.xml</loc></sitemap>
+	<sitemap><loc>` + serverURL + `/missing.xml</loc></sitemap>
+	<sitemap><loc>` + serverURL + `/categories.xml</loc></sitemap>
+	<sitemap><loc>` + serverURL + `/posts.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/posts.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://example.com/b</loc></url>
+	<url><loc>https://example.com/shared</loc></url>
+</urlset>`))
+		case "/categories.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://example.com/shared</loc></url>
+	<url><loc>https://example.com/a</loc></url>
+</urlset>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	expected := []string{"https://example.com/b", "https://example.com/shared", "https://example.com/a"}
+	for run := 0; run < 3; run++ {
+		urls, err := NewSitemapParser().Fetch(server.URL + "/sitemap-index.xml")
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+
+		locations := make([]string, len(urls))
+		for i, u := range urls {
+			locations[i] = u.Location
+		}
+		if !reflect.DeepEqual(locations, expected) {
+			t.Fatalf("Run %d: expected each location once in document order %v, got %v", run, expected, locations)
+		}
+	}
+}
+
+// generatedSitemap is a reader producing a sitemap with n entries without holding it in memory
+// and counting the bytes read so far
+type generatedSitemap struct {
+	n       int
+	next    int
+	pending []byte
+	read    int
+}
+
+func (g *generatedSitemap) Read(b []byte) (int, error) {
+	if len(g.pending) == 0 {
+		switch {
+		case g.next == 0:
+			g.pending = []byte(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		case g.next <= g.n:
+			g.pending = []byte(fmt.Sprintf("<url><loc>https://example.com/post-%d</loc></url>\n", g.next))
+		case g.next == g.n+1:
+			g.pending = []byte("</urlset>")
+		default:
+			return 0, io.EOF
+		}
+		g.next++
+	}
+	n := copy(b, g.pending)
+	g.pending = g.pending[n:]
+	g.read += n
+	return n, nil
+}
+
+func TestStreamSitemap_LargeSitemapIsStreamed(t *testing.T) {
+	const entries = 200000
+	source := &generatedSitemap{n: entries}
+
+	count := 0
+	readAtFirstURL := 0
+	sitemapURLs, err := streamSitemap(source, func(u URL) error {
+		if count == 0 {
+			readAtFirstURL = source.read
+		}
+		count++
+		if want := fmt.Sprintf("https://example.com/post-%d", count); u.Location != want {
+			return fmt.Errorf("expected %s, got %s", want, u.Location)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("streamSitemap failed: %v", err)
+	}
+	if sitemapURLs != nil {
+		t.Errorf("Expected no sitemap URLs for a regular sitemap, got %v", sitemapURLs)
+	}
+	if count != entries {
+		t.Fatalf("Expected %d URLs, got %d", entries, count)
+	}
+
+	// The first URL must arrive after reading a few KB, not the whole multi-MB document
+	if readAtFirstURL == 0 || readAtFirstURL > 64*1024 {
+		t.Errorf("Expected the first URL after reading at most 64KB, got it after %d of %d bytes", readAtFirstURL, source.read)
+	}
+}
+
+func TestStreamSitemap_StopsWhenEmitFails(t *testing.T) {
+	source := &generatedSitemap{n: 200000}
+	stop := errors.New("stop")
+
+	count := 0
+	_, err := streamSitemap(source, func(u URL) error {
+		count++
+		if count == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected emit's error, got %v", err)
+	}
+	if source.next > 1000 {
+		t.Errorf("Expected reading to stop soon after emit failed, generated %d entries", source.next)
+	}
+}
+
+func TestSitemapParser_Stream_SitemapIndex(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap-index.xml":
+			fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>%[1]s/sitemap1.xml</loc></sitemap>
+	<sitemap><loc>%[1]s/missing.xml</loc></sitemap>
+	<sitemap><loc>%[1]s/sitemap2.xml</loc></sitemap>
+</sitemapindex>`, serverURL)
+		case "/sitemap1.xml":
+			fmt.Fprint(w, `<urlset><url><loc>https://example.com/a</loc></url><url><loc></loc></url></urlset>`)
+		case "/sitemap2.xml":
+			fmt.Fprint(w, `<urlset><url><loc>https://example.com/b</loc></url></urlset>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	var locations []string
+	err := NewSitemapParser().Stream(context.Background(), server.URL+"/sitemap-index.xml", func(u URL) error {
+		locations = append(locations, u.Location)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if strings.Join(locations, " ") != "https://example.com/a https://example.com/b" {
+		t.Errorf("Expected the entries of both sitemaps, skipping the missing one, got %v", locations)
+	}
+
+	err = NewSitemapParser().Stream(context.Background(), server.URL+"/missing.xml", func(u URL) error { return nil })
+	if err == nil {
+		t.Error("Expected an error for a missing sitemap")
+	}
+}
+
+func TestSitemapParser_AcceptsXML(t *testing.T) {
+	var accepts []string
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s/posts.xml</loc></sitemap></sitemapindex>`, server.URL)
+	})
+	mux.HandleFunc("/posts.xml", func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+		fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://example.com/a</loc></url></urlset>`)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	if _, err := NewSitemapParser().Fetch(server.URL + "/sitemap-index.xml"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err := NewSitemapParser().Stream(context.Background(), server.URL+"/sitemap-index.xml", func(u URL) error { return nil }); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(accepts) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(accepts))
+	}
+	for i, accept := range accepts {
+		if !strings.HasPrefix(accept, "application/xml") {
+			t.Errorf("Request %d: expected an XML Accept header, got %q", i+1, accept)
+		}
+	}
+}
+
+func TestSitemapParser_SelfReferentialIndexFails(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s/sitemap-index.xml</loc></sitemap></sitemapindex>`, server.URL)
+	}))
+	defer server.Close()
+
+	_, err := NewSitemapParser().Fetch(server.URL + "/sitemap-index.xml")
+	if !errors.Is(err, ErrSitemapCycle) {
+		t.Errorf("Fetch: expected ErrSitemapCycle, got %v", err)
+	}
+
+	err = NewSitemapParser().Stream(context.Background(), server.URL+"/sitemap-index.xml", func(u URL) error { return nil })
+	if !errors.Is(err, ErrSitemapCycle) {
+		t.Errorf("Stream: expected ErrSitemapCycle, got %v", err)
+	}
+}
+
+func TestSitemapParser_DeeplyNestedIndexFails(t *testing.T) {
+	// Each index lists a new one below it, so the chain never repeats a URL
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s%s/next.xml</loc></sitemap></sitemapindex>`, server.URL, strings.TrimSuffix(r.URL.Path, ".xml"))
+	}))
+	defer server.Close()
+
+	_, err := NewSitemapParser().Fetch(server.URL + "/index.xml")
+	if !errors.Is(err, ErrSitemapTooDeep) {
+		t.Errorf("Fetch: expected ErrSitemapTooDeep, got %v", err)
+	}
+
+	err = NewSitemapParser().Stream(context.Background(), server.URL+"/index.xml", func(u URL) error { return nil })
+	if !errors.Is(err, ErrSitemapTooDeep) {
+		t.Errorf("Stream: expected ErrSitemapTooDeep, got %v", err)
+	}
+}
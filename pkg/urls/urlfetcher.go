@@ -1,9 +1,15 @@
 package urls
 
+import "strings"
+
 // URL represents a URL entry from a parser (sitemap or RSS)
 type URL struct {
-	Location string // URL of the article
-	Title    string // Title of the article (optional)
+	Location string `json:"location"`          // URL of the article
+	Title    string `json:"title"`             // Title of the article (optional)
+	Summary  string `json:"summary,omitempty"` // Plain-text description from the feed (optional)
+	Content  string `json:"content,omitempty"` // Full post HTML from the feed, e.g. RSS <content:encoded> (optional)
+
+	EnclosureURL string `json:"enclosure_url,omitempty"` // Media file attached to a feed item, e.g. a podcast episode's mp3 (optional)
 	// Add more fields as needed (LastMod, PublishDate, etc.)
 }
 
@@ -11,3 +17,50 @@ type URL struct {
 type URLsFetcher interface {
 	Fetch(baseUrl string) ([]URL, error)
 }
+
+// Source types reported in FetchResult.SourceType
+const (
+	SourceRSS     = "rss"
+	SourceSitemap = "sitemap"
+	SourceHTML    = "html"
+	SourceFile    = "file"
+)
+
+// FetchResult holds the URLs found at a source together with where they came from
+type FetchResult struct {
+	URLs       []URL
+	SourceURL  string // URL or file path the URLs were read from (for discovered feeds, the feed's URL)
+	SourceType string // One of SourceRSS, SourceSitemap, SourceHTML or SourceFile
+	Title      string // Feed or page title (empty for sitemaps and files)
+	Count      int    // Number of URLs
+}
+
+// MetaFetcher is implemented by URL fetchers that also describe the source of their URLs
+// RSSParser, SitemapParser, HTMLFetcher and FileParser implement it
+type MetaFetcher interface {
+	FetchWithMeta(source string) (*FetchResult, error)
+}
+
+// FetchWithMeta fetches the URLs at source with fetcher's FetchWithMeta if it has one; for other
+// fetchers the result only has the URLs, SourceURL and Count
+func FetchWithMeta(fetcher URLsFetcher, source string) (*FetchResult, error) {
+	if meta, ok := fetcher.(MetaFetcher); ok {
+		return meta.FetchWithMeta(source)
+	}
+	urls, err := fetcher.Fetch(source)
+	if err != nil {
+		return nil, err
+	}
+	return newFetchResult(urls, source, "", ""), nil
+}
+
+// newFetchResult returns a FetchResult for urls with Count filled in
+func newFetchResult(urls []URL, sourceURL, sourceType, title string) *FetchResult {
+	return &FetchResult{
+		URLs:       urls,
+		SourceURL:  sourceURL,
+		SourceType: sourceType,
+		Title:      strings.TrimSpace(title),
+		Count:      len(urls),
+	}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"blog-search/pkg/db"
+	"blog-search/pkg/domain"
+	"blog-search/pkg/logging"
+	"blog-search/pkg/metrics"
+)
+
+// articleBatch buffers the articles extracted by a manager's workers and saves them with one
+// SaveArticles call per batch instead of one round trip per article
+// Articles that fail to save are logged and recorded as failed URLs for retry-failed
+type articleBatch struct {
+	store   db.ArticleStore
+	saver   db.BulkSaver
+	size    int
+	onSaved func(n int) // Called with the number of articles saved by each write (optional)
+
+	mu      sync.Mutex
+	pending []*domain.Article
+	failed  int // Articles whose save failed, across all writes
+}
+
+// newArticleBatch creates a batch that saves size articles at a time
+// Returns nil (save one at a time) if size is below 2 or store can't save in bulk
+func newArticleBatch(store db.ArticleStore, size int) *articleBatch {
+	saver, ok := store.(db.BulkSaver)
+	if !ok || size < 2 {
+		return nil
+	}
+	return &articleBatch{store: store, saver: saver, size: size}
+}
+
+// add buffers article and saves the batch once it is full
+func (b *articleBatch) add(ctx context.Context, article *domain.Article) {
+	b.mu.Lock()
+	b.pending = append(b.pending, article)
+	if len(b.pending) < b.size {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	b.write(ctx, batch)
+}
+
+// flush saves the articles still buffered; managers call it once their workers are done
+func (b *articleBatch) flush(ctx context.Context) {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.write(ctx, batch)
+	}
+}
+
+// failedCount returns how many buffered articles failed to save so far
+func (b *articleBatch) failedCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed
+}
+
+// write saves one batch and records the articles that weren't saved
+func (b *articleBatch) write(ctx context.Context, batch []*domain.Article) {
+	err := b.saver.SaveArticles(ctx, batch)
+	failed := failedBatchURLs(batch, err)
+
+	saved := len(batch) - len(failed)
+	metrics.ArticlesSaved.Add(uint64(saved))
+	if b.onSaved != nil && saved > 0 {
+		b.onSaved(saved)
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	b.failed += len(failed)
+	b.mu.Unlock()
+
+	logging.Warnf("Worker: Failed to save %d of %d batched articles: %v", len(failed), len(batch), err)
+	saveErr := fmt.Errorf("failed to save article: %w", err)
+	for _, url := range failed {
+		if err := db.RecordFailure(ctx, b.store, url, saveErr); err != nil {
+			logging.Warnf("Worker: Failed to record failed URL %s: %v", url, err)
+		}
+	}
+}
+
+// failedBatchURLs returns the URLs of the articles a SaveArticles call that returned err didn't save
+// Without a *db.BulkSaveError saying which ones failed, the whole batch is assumed lost
+func failedBatchURLs(batch []*domain.Article, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var saveErr *db.BulkSaveError
+	if errors.As(err, &saveErr) {
+		return saveErr.FailedURLs
+	}
+
+	failed := make([]string, len(batch))
+	for i, article := range batch {
+		failed[i] = article.URL
+	}
+	return failed
+}
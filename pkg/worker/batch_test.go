@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"blog-search/pkg/db"
+	"blog-search/pkg/domain"
+)
+
+// bulkArticleStore is a fakeArticleStore that also saves in bulk; URLs in failURLs are
+// reported as failed by SaveArticles
+type bulkArticleStore struct {
+	fakeArticleStore
+	failURLs   map[string]bool
+	batchSizes []int
+}
+
+func (s *bulkArticleStore) SaveArticles(ctx context.Context, articles []*domain.Article) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchSizes = append(s.batchSizes, len(articles))
+
+	var failed []string
+	for _, article := range articles {
+		if s.failURLs[article.URL] {
+			failed = append(failed, article.URL)
+			continue
+		}
+		s.saved = append(s.saved, article)
+	}
+	if len(failed) > 0 {
+		return &db.BulkSaveError{FailedURLs: failed, Total: len(articles), Err: errors.New("write error")}
+	}
+	return nil
+}
+
+func TestManager_ProcessURLs_SavesInBatches(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	store := &bulkArticleStore{failURLs: map[string]bool{"https://example.com/post-3": true}}
+	m := &Manager{
+		workerCount: 3,
+		store:       store,
+		newWorker: func() *Worker {
+			return &Worker{store: store, fetch: countingFetch(calls, &mu)}
+		},
+	}
+	m.SetSaveBatchSize(4)
+
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/post-%d", i)
+	}
+	if err := m.ProcessURLs(context.Background(), urls); err != nil {
+		t.Fatalf("ProcessURLs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(store.batchSizes, []int{4, 4, 2}) {
+		t.Errorf("Expected two full batches and a final flush, got batch sizes %v", store.batchSizes)
+	}
+	if len(store.saved) != 9 {
+		t.Errorf("Expected 9 saved articles, got %d", len(store.saved))
+	}
+	if store.failed["https://example.com/post-3"] != 1 || len(store.failed) != 1 {
+		t.Errorf("Expected only the failed article to be recorded, got %v", store.failed)
+	}
+}
+
+func TestNewArticleBatch_FallsBackToSingleSaves(t *testing.T) {
+	if newArticleBatch(&fakeArticleStore{}, 50) != nil {
+		t.Error("Expected no batch for a store without SaveArticles")
+	}
+	if newArticleBatch(&bulkArticleStore{}, 1) != nil {
+		t.Error("Expected no batch for a batch size of 1")
+	}
+}
+
+func TestFailedBatchURLs_WholeBatchOnOtherErrors(t *testing.T) {
+	batch := []*domain.Article{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}}
+	got := failedBatchURLs(batch, errors.New("connection reset"))
+	if !reflect.DeepEqual(got, []string{"https://example.com/a", "https://example.com/b"}) {
+		t.Errorf("Expected every URL of the batch, got %v", got)
+	}
+}
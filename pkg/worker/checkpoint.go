@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"blog-search/pkg/db"
+)
+
+// pageCheckpoint tracks which page ranges of a TwoLevelManager crawl are fully processed (all of
+// their pages were read and every article URL on them was handled by a content worker) and saves
+// the last page before which every range is done
+// Ranges finish out of order, so a range that finishes early waits for the ones before it
+// A nil *pageCheckpoint tracks nothing, so callers don't need to check whether checkpoints are used
+type pageCheckpoint struct {
+	ctx   context.Context // Saves outlive the crawl's context, so an interrupted crawl keeps its progress
+	store db.CrawlCheckpointer
+	key   string // The crawl's page URL pattern
+
+	mu     sync.Mutex
+	next   int                    // First page of the earliest range not fully processed yet
+	ranges map[int]*rangeProgress // Ranges handed out and not checkpointed yet, by first page
+}
+
+// rangeProgress is the state of one page range handed out to the URL fetchers
+type rangeProgress struct {
+	end       int
+	pending   int  // Article URLs sent to content workers and not processed yet
+	extracted bool // All pages of the range were read
+	failed    bool // Some page of the range couldn't be read, so the range is never done this run
+}
+
+// newPageCheckpoint returns a tracker for a crawl of key starting at firstPage
+func newPageCheckpoint(ctx context.Context, store db.CrawlCheckpointer, key string, firstPage int) *pageCheckpoint {
+	return &pageCheckpoint{
+		ctx:    context.WithoutCancel(ctx),
+		store:  store,
+		key:    key,
+		next:   firstPage,
+		ranges: make(map[int]*rangeProgress),
+	}
+}
+
+// add starts tracking r; ranges must be added in page order before they are handed out
+func (c *pageCheckpoint) add(r PageRange) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges[r.Start] = &rangeProgress{end: r.End}
+}
+
+// urlSent records that an article URL of the range starting at start is about to be sent to the
+// content workers; the returned function must be called once the URL was processed
+func (c *pageCheckpoint) urlSent(start int) (processed func() error) {
+	if c == nil {
+		return func() error { return nil }
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges[start].pending++
+
+	return func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.ranges[start].pending--
+		return c.advance()
+	}
+}
+
+// extracted records that all pages of the range starting at start were read
+func (c *pageCheckpoint) extracted(start int) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges[start].extracted = true
+	return c.advance()
+}
+
+// failed records that some page of the range starting at start couldn't be read
+// The checkpoint then stays before the range, so a resumed crawl reads it again
+func (c *pageCheckpoint) failed(start int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges[start].failed = true
+}
+
+// advance saves a new checkpoint if the earliest unfinished ranges are now done
+// c.mu must be held; saving under it keeps checkpoints from being written out of order
+func (c *pageCheckpoint) advance() error {
+	lastPage, advanced := 0, false
+	for {
+		r, ok := c.ranges[c.next]
+		if !ok || !r.extracted || r.failed || r.pending > 0 {
+			break
+		}
+		delete(c.ranges, c.next)
+		lastPage, advanced = r.end, true
+		c.next = r.end + 1
+	}
+	if !advanced {
+		return nil
+	}
+	return c.store.SaveCrawlCheckpoint(c.ctx, c.key, lastPage)
+}
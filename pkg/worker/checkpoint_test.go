@@ -0,0 +1,274 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"blog-search/pkg/urls"
+)
+
+// checkpointStore is a fakeArticleStore that also keeps crawl checkpoints
+type checkpointStore struct {
+	fakeArticleStore
+	checkpoints map[string]int
+	saves       []int // Every saved checkpoint, in order
+}
+
+func (s *checkpointStore) SaveCrawlCheckpoint(ctx context.Context, baseURL string, lastPage int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[string]int)
+	}
+	s.checkpoints[baseURL] = lastPage
+	s.saves = append(s.saves, lastPage)
+	return nil
+}
+
+func (s *checkpointStore) LoadCrawlCheckpoint(ctx context.Context, baseURL string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastPage, found := s.checkpoints[baseURL]
+	return lastPage, found, nil
+}
+
+func (s *checkpointStore) ClearCrawlCheckpoint(ctx context.Context, baseURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, baseURL)
+	return nil
+}
+
+// paginatedSite serves pages 1 to lastPage listing two posts each, and an empty page after them
+// It records which page numbers were requested
+type paginatedSite struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	requested map[int]bool
+	failing   map[int]bool // Pages that answer with a server error
+}
+
+func newPaginatedSite(t *testing.T, lastPage int) *paginatedSite {
+	t.Helper()
+	site := &paginatedSite{requested: make(map[int]bool)}
+	paragraph := "<p>" + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p>"
+	site.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page int
+		if _, err := fmt.Sscanf(r.URL.Path, "/page/%d", &page); err == nil {
+			site.mu.Lock()
+			site.requested[page] = true
+			failing := site.failing[page]
+			site.mu.Unlock()
+			if failing {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			} else if page <= lastPage {
+				fmt.Fprintf(w, "<html><body>post-%d-a post-%d-b</body></html>", page, page)
+			} else {
+				fmt.Fprint(w, "<html><body></body></html>")
+			}
+			return
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body><article><h1>%s</h1>%s%s</article></body></html>", r.URL.Path, r.URL.Path, paragraph, paragraph)
+	}))
+	t.Cleanup(site.server.Close)
+	return site
+}
+
+// extractor returns every "post-..." word as an article URL on the site
+func (s *paginatedSite) extractor(html string) ([]urls.URL, error) {
+	var found []urls.URL
+	for _, word := range strings.FieldsFunc(html, func(r rune) bool { return r == ' ' || r == '<' || r == '>' }) {
+		if strings.HasPrefix(word, "post-") {
+			found = append(found, urls.URL{Location: s.server.URL + "/" + word})
+		}
+	}
+	return found, nil
+}
+
+func (s *paginatedSite) pattern() string {
+	return s.server.URL + "/page/%d"
+}
+
+func (s *paginatedSite) wasRequested(page int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requested[page]
+}
+
+// savedPaths returns the paths of the saved articles, sorted
+func savedPaths(store *checkpointStore, site *paginatedSite) []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var paths []string
+	for _, article := range store.saved {
+		paths = append(paths, strings.TrimPrefix(article.URL, site.server.URL))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestPageCheckpoint_WaitsForEarlierRanges(t *testing.T) {
+	store := &checkpointStore{}
+	checkpoint := newPageCheckpoint(context.Background(), store, "https://example.com/page/%d", 1)
+	checkpoint.add(PageRange{Start: 1, End: 2})
+	checkpoint.add(PageRange{Start: 3, End: 4})
+
+	processed := checkpoint.urlSent(1)
+	if err := checkpoint.extracted(3); err != nil {
+		t.Fatalf("extracted failed: %v", err)
+	}
+	if err := checkpoint.extracted(1); err != nil {
+		t.Fatalf("extracted failed: %v", err)
+	}
+	if len(store.saves) != 0 {
+		t.Fatalf("Expected no checkpoint while a URL of pages 1-2 is pending, got %v", store.saves)
+	}
+
+	// Finishing the first range also completes the second, which finished earlier
+	if err := processed(); err != nil {
+		t.Fatalf("processed failed: %v", err)
+	}
+	if !reflect.DeepEqual(store.saves, []int{4}) {
+		t.Errorf("Expected a single checkpoint at page 4, got %v", store.saves)
+	}
+}
+
+func TestPageCheckpoint_StopsBeforeFailedRange(t *testing.T) {
+	store := &checkpointStore{}
+	checkpoint := newPageCheckpoint(context.Background(), store, "https://example.com/page/%d", 1)
+	checkpoint.add(PageRange{Start: 1, End: 2})
+	checkpoint.add(PageRange{Start: 3, End: 4})
+	checkpoint.add(PageRange{Start: 5, End: 6})
+
+	if err := checkpoint.extracted(1); err != nil {
+		t.Fatalf("extracted failed: %v", err)
+	}
+	checkpoint.failed(3)
+	if err := checkpoint.extracted(5); err != nil {
+		t.Fatalf("extracted failed: %v", err)
+	}
+
+	// Pages 1-2 are done, but nothing after the failed range is checkpointed
+	if !reflect.DeepEqual(store.saves, []int{2}) {
+		t.Errorf("Expected a single checkpoint at page 2, got %v", store.saves)
+	}
+}
+
+func TestPageCheckpoint_NilTracksNothing(t *testing.T) {
+	var checkpoint *pageCheckpoint
+	checkpoint.add(PageRange{Start: 1, End: 1})
+	if err := checkpoint.urlSent(1)(); err != nil {
+		t.Errorf("Expected no error from a nil checkpoint, got %v", err)
+	}
+	if err := checkpoint.extracted(1); err != nil {
+		t.Errorf("Expected no error from a nil checkpoint, got %v", err)
+	}
+	checkpoint.failed(1)
+}
+
+func TestTwoLevelManager_ResumesAfterCheckpoint(t *testing.T) {
+	site := newPaginatedSite(t, 4)
+	store := &checkpointStore{checkpoints: map[string]int{site.pattern(): 2}}
+	manager := NewTwoLevelManager(Config{
+		URLFetcherWorkers: 2,
+		ContentWorkers:    3,
+		DBClient:          store,
+		PagesPerBatch:     1,
+		BaseURLPattern:    site.pattern(),
+		Extractor:         site.extractor,
+	})
+
+	if err := manager.ProcessPaginatedPages(context.Background()); err != nil {
+		t.Fatalf("ProcessPaginatedPages failed: %v", err)
+	}
+
+	if site.wasRequested(1) || site.wasRequested(2) {
+		t.Error("Expected pages 1 and 2 to be skipped")
+	}
+	expected := []string{"/post-3-a", "/post-3-b", "/post-4-a", "/post-4-b"}
+	if got := savedPaths(store, site); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected only the posts of pages 3 and 4, got %v", got)
+	}
+	// The crawl reached the last page, so the next one starts over
+	if _, found, _ := store.LoadCrawlCheckpoint(context.Background(), site.pattern()); found {
+		t.Error("Expected the checkpoint to be cleared after a finished crawl")
+	}
+}
+
+func TestTwoLevelManager_FreshIgnoresCheckpoint(t *testing.T) {
+	site := newPaginatedSite(t, 2)
+	store := &checkpointStore{checkpoints: map[string]int{site.pattern(): 2}}
+	manager := NewTwoLevelManager(Config{
+		URLFetcherWorkers: 1,
+		ContentWorkers:    2,
+		DBClient:          store,
+		PagesPerBatch:     1,
+		BaseURLPattern:    site.pattern(),
+		Extractor:         site.extractor,
+		Fresh:             true,
+	})
+
+	if err := manager.ProcessPaginatedPages(context.Background()); err != nil {
+		t.Fatalf("ProcessPaginatedPages failed: %v", err)
+	}
+
+	if got := savedPaths(store, site); len(got) != 4 {
+		t.Errorf("Expected the posts of pages 1 and 2, got %v", got)
+	}
+}
+
+func TestTwoLevelManager_SavesCheckpointForUnfinishedCrawl(t *testing.T) {
+	site := newPaginatedSite(t, 5)
+	store := &checkpointStore{}
+	manager := NewTwoLevelManager(Config{
+		URLFetcherWorkers: 2,
+		ContentWorkers:    3,
+		DBClient:          store,
+		PagesPerBatch:     1,
+		BaseURLPattern:    site.pattern(),
+		Extractor:         site.extractor,
+		MaxPages:          3,
+	})
+
+	if err := manager.ProcessPaginatedPages(context.Background()); err != nil {
+		t.Fatalf("ProcessPaginatedPages failed: %v", err)
+	}
+
+	lastPage, found, _ := store.LoadCrawlCheckpoint(context.Background(), site.pattern())
+	if !found || lastPage != 3 {
+		t.Errorf("Expected a checkpoint at page 3, got found=%v page=%d", found, lastPage)
+	}
+}
+
+func TestTwoLevelManager_CheckpointStaysBeforeFailedPage(t *testing.T) {
+	site := newPaginatedSite(t, 6)
+	site.failing = map[int]bool{4: true}
+	store := &checkpointStore{}
+	manager := NewTwoLevelManager(Config{
+		URLFetcherWorkers: 2,
+		ContentWorkers:    3,
+		DBClient:          store,
+		PagesPerBatch:     2,
+		BaseURLPattern:    site.pattern(),
+		Extractor:         site.extractor,
+		MaxPages:          6,
+	})
+
+	if err := manager.ProcessPaginatedPages(context.Background()); err != nil {
+		t.Fatalf("ProcessPaginatedPages failed: %v", err)
+	}
+
+	// Page 4 failed, so pages 3-4 and everything after them are read again on resume
+	lastPage, found, _ := store.LoadCrawlCheckpoint(context.Background(), site.pattern())
+	if !found || lastPage != 2 {
+		t.Errorf("Expected a checkpoint at page 2, got found=%v page=%d", found, lastPage)
+	}
+}
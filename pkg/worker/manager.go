@@ -2,44 +2,70 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 
 	"blog-search/pkg/db"
+	"blog-search/pkg/logging"
 )
 
 // Manager manages workers and distributes URLs to them
 type Manager struct {
 	workerCount int
-	dbClient    *db.Client
+	store       db.ArticleStore
+	newWorker   func() *Worker
+	batchSize   int // Articles saved per SaveArticles call (0 or 1 = one at a time)
+	minTextLen  int // Passed to each worker's MinTextLength
 }
 
 // NewManager creates a new manager
-func NewManager(workerCount int, dbClient *db.Client) *Manager {
+func NewManager(workerCount int, store db.ArticleStore) *Manager {
 	return &Manager{
 		workerCount: workerCount,
-		dbClient:    dbClient,
+		store:       store,
+		newWorker:   func() *Worker { return NewWorker(store) },
 	}
 }
 
+// SetSaveBatchSize makes workers buffer articles and save them size at a time when the store
+// implements db.BulkSaver; 0 or 1 saves each article as soon as it is extracted
+func (m *Manager) SetSaveBatchSize(size int) {
+	m.batchSize = size
+}
+
+// SetMinTextLength makes workers skip pages whose extracted text has fewer than n characters
+// instead of saving them; skipped pages (including soft 404s) are counted separately from errors
+func (m *Manager) SetMinTextLength(n int) {
+	m.minTextLen = n
+}
+
 // ProcessURLs distributes URLs to workers and processes them concurrently
+// When ctx is canceled, no new URLs are handed out and the context error is returned
+// once the URLs already being processed finish
 func (m *Manager) ProcessURLs(ctx context.Context, urls []string) error {
-	// Create job channel
-	jobChan := make(chan string, len(urls))
-
-	// Send all URLs to job channel
-	for _, url := range urls {
-		jobChan <- url
-	}
-	close(jobChan)
+	// Feed jobs as workers become free, so cancellation stops dispatch right away
+	jobChan := make(chan string, m.workerCount)
+	go func() {
+		defer close(jobChan)
+		for _, url := range urls {
+			select {
+			case jobChan <- url:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	// Create wait group to wait for all workers
 	var wg sync.WaitGroup
+	batch := newArticleBatch(m.store, m.batchSize)
 
 	// Results channel to collect success/error from workers (no contention)
 	type result struct {
 		success  bool
+		skipped  bool
 		url      string
 		workerID int
 		err      error
@@ -52,15 +78,23 @@ func (m *Manager) ProcessURLs(ctx context.Context, urls []string) error {
 		go func(workerID int) {
 			defer wg.Done()
 
-			w := NewWorker(m.dbClient)
+			w := m.newWorker()
+			w.batch = batch
+			w.MinTextLength = m.minTextLen
 
 			// Process jobs from channel - each worker tracks its own counts
 			for url := range jobChan {
+				// Don't start new jobs once canceled; the feeder stops and closes jobChan
+				if ctx.Err() != nil {
+					continue
+				}
 				err := w.ProcessURL(ctx, url)
 
 				// Send result to channel (no contention during processing)
+				// Articles stored in the meantime count as done
 				resultsChan <- result{
-					success:  err == nil,
+					success:  err == nil || errors.Is(err, ErrAlreadyFetched),
+					skipped:  isSkippedPage(err),
 					url:      url,
 					workerID: workerID,
 					err:      err,
@@ -76,7 +110,10 @@ func (m *Manager) ProcessURLs(ctx context.Context, urls []string) error {
 	}()
 
 	// Aggregate results (no contention - single goroutine reads from channel)
-	var successCount, errorCount uint64
+	// Repeated errors (e.g., the same 403 on every URL) are rolled up instead of logged one by one
+	var successCount, skippedCount, errorCount uint64
+	errorLog := logging.NewErrorAggregator("Manager", logging.DefaultRollupInterval)
+	errorLog.Start()
 
 	for res := range resultsChan {
 		if res.success {
@@ -85,13 +122,29 @@ func (m *Manager) ProcessURLs(ctx context.Context, urls []string) error {
 			if successCount%100 == 0 {
 				log.Printf("Progress: %d successful, %d errors", successCount, errorCount)
 			}
+		} else if res.skipped {
+			skippedCount++
+			logging.Debugf("Worker %d: Skipping %s: %v", res.workerID, res.url, res.err)
 		} else {
 			errorCount++
-			log.Printf("Worker %d: Error processing %s: %v", res.workerID, res.url, res.err)
+			errorLog.Warnf(res.err, "Worker %d: Error processing %s: %v", res.workerID, res.url, res.err)
 		}
 	}
+	errorLog.Stop()
+
+	// Articles still buffered are saved now; those that failed count as errors, not successes
+	if batch != nil {
+		batch.flush(context.WithoutCancel(ctx))
+		failed := uint64(batch.failedCount())
+		successCount -= failed
+		errorCount += failed
+	}
+
+	log.Printf("Completed: %d successful, %d skipped, %d errors (total: %d)", successCount, skippedCount, errorCount, len(urls))
 
-	log.Printf("Completed: %d successful, %d errors (total: %d)", successCount, errorCount, len(urls))
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("canceled after processing %d of %d URLs: %w", successCount+errorCount, len(urls), err)
+	}
 
 	if errorCount > 0 && successCount == 0 {
 		return fmt.Errorf("all %d URLs failed to process", errorCount)
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestManager_ProcessURLs_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	fetch := countingFetch(calls, &mu)
+	store := &fakeArticleStore{}
+	m := &Manager{
+		workerCount: 1,
+		newWorker: func() *Worker {
+			return &Worker{store: store, fetch: func(ctx context.Context, url string) (string, error) {
+				// Cancel as soon as the first job starts
+				cancel()
+				return fetch(ctx, url)
+			}}
+		},
+	}
+
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/post-%d", i)
+	}
+
+	err := m.ProcessURLs(ctx, urls)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	mu.Lock()
+	fetched := len(calls)
+	mu.Unlock()
+	if fetched == 0 || fetched >= len(urls) {
+		t.Errorf("Expected some but not all URLs to be fetched, got %d of %d", fetched, len(urls))
+	}
+}
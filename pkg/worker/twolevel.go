@@ -2,11 +2,15 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"blog-search/pkg/db"
+	"blog-search/pkg/logging"
+	"blog-search/pkg/progress"
 	"blog-search/pkg/urls"
 )
 
@@ -22,22 +26,69 @@ type PageRange struct {
 type TwoLevelManager struct {
 	urlFetcherWorkers int // Number of Level 1 workers (fetch URLs from pages)
 	contentWorkers    int // Number of Level 2 workers (fetch content and save)
-	dbClient          *db.Client
+	store             db.ArticleStore
 	pagesPerBatch     int
 	baseURLPattern    string
 	extractor         urls.URLExtractor
 	maxPages          int // Maximum number of pages to process (0 = unlimited)
+	onProgress        func(progress.Progress)
+	progressInterval  time.Duration
+	summaryInterval   time.Duration           // How often startSummaries logs the running totals (0 = never)
+	summarize         func(progress.Progress) // Logs a summary; replaced in tests
+	saveBatchSize     int                     // Articles saved per SaveArticles call (0 or 1 = one at a time)
+	minTextLength     int                     // Passed to each content worker's MinTextLength
+	fresh             bool                    // Ignore the saved checkpoint and start from page 1
+
+	// Running totals for the current ProcessPaginatedPages call, shared by all workers
+	pagesProcessed atomic.Int64
+	urlsExtracted  atomic.Int64
+	articlesSaved  atomic.Int64
+
+	// Rolls up repeated page and content errors for the current ProcessPaginatedPages call
+	errorLog *logging.ErrorAggregator
+
+	// Saves how far the current ProcessPaginatedPages call got; nil without checkpoints
+	checkpoint *pageCheckpoint
+}
+
+// crawlURL is an article URL sent from a URL fetcher to the content workers
+type crawlURL struct {
+	url       string
+	processed func() error // Called once a content worker handled the URL (see pageCheckpoint)
 }
 
 // Config holds configuration for TwoLevelManager
 type Config struct {
 	URLFetcherWorkers int
 	ContentWorkers    int
-	DBClient          *db.Client
+	DBClient          db.ArticleStore // Usually a *db.Client
 	PagesPerBatch     int
 	BaseURLPattern    string
 	Extractor         urls.URLExtractor
 	MaxPages          int // Maximum number of pages to process (0 = unlimited, useful for testing)
+
+	// OnProgress, if set, is called every ProgressInterval (default 1s) while counts change,
+	// and once more when processing ends. Calls come from a single goroutine.
+	OnProgress       func(progress.Progress)
+	ProgressInterval time.Duration
+
+	// SummaryInterval, if positive, logs the running totals at this interval while processing
+	SummaryInterval time.Duration
+
+	// SaveBatchSize, if above 1 and DBClient implements db.BulkSaver, buffers extracted articles
+	// and saves them this many at a time instead of one by one
+	SaveBatchSize int
+
+	// MinTextLength, if positive, skips pages whose extracted text has fewer characters
+	// instead of saving them
+	MinTextLength int
+
+	// If DBClient implements db.CrawlCheckpointer, the last fully processed page is saved as the
+	// crawl goes (keyed by BaseURLPattern) and a later run resumes after it; the checkpoint is
+	// cleared once the crawl reaches the last page. Fresh ignores it and starts from page 1
+	// With SaveBatchSize, articles still buffered when the process dies are lost and their pages
+	// aren't crawled again on resume
+	Fresh bool
 }
 
 // NewTwoLevelManager creates a new two-level worker manager
@@ -45,11 +96,28 @@ func NewTwoLevelManager(config Config) *TwoLevelManager {
 	return &TwoLevelManager{
 		urlFetcherWorkers: config.URLFetcherWorkers,
 		contentWorkers:    config.ContentWorkers,
-		dbClient:          config.DBClient,
+		store:             config.DBClient,
 		pagesPerBatch:     config.PagesPerBatch,
 		baseURLPattern:    config.BaseURLPattern,
 		extractor:         config.Extractor,
 		maxPages:          config.MaxPages,
+		onProgress:        config.OnProgress,
+		progressInterval:  config.ProgressInterval,
+		summaryInterval:   config.SummaryInterval,
+		summarize:         logSummary,
+		saveBatchSize:     config.SaveBatchSize,
+		minTextLength:     config.MinTextLength,
+		fresh:             config.Fresh,
+	}
+}
+
+// Progress returns the running totals of the current (or last) ProcessPaginatedPages call
+// Safe to call from any goroutine while processing
+func (m *TwoLevelManager) Progress() progress.Progress {
+	return progress.Progress{
+		PagesProcessed: m.pagesProcessed.Load(),
+		URLsExtracted:  m.urlsExtracted.Load(),
+		ArticlesSaved:  m.articlesSaved.Load(),
 	}
 }
 
@@ -61,36 +129,102 @@ func NewTwoLevelManager(config Config) *TwoLevelManager {
 func (m *TwoLevelManager) ProcessPaginatedPages(ctx context.Context) error {
 	// Create channels
 	pageRangeChan := make(chan PageRange, m.urlFetcherWorkers*2) // Buffered channel for page ranges
-	urlChan := make(chan string, m.contentWorkers*2)             // Buffered channel for article URLs
+	urlChan := make(chan crawlURL, m.contentWorkers*2)           // Buffered channel for article URLs
+
+	reporter := progress.NewReporter(m.onProgress, m.progressInterval)
+	reporter.Start()
+	defer reporter.Stop()
+
+	m.pagesProcessed.Store(0)
+	m.urlsExtracted.Store(0)
+	m.articlesSaved.Store(0)
+	stopSummaries := m.startSummaries()
+	defer stopSummaries()
+
+	// Repeated errors are rolled up with the summaries, or every DefaultRollupInterval without them
+	m.errorLog = logging.NewErrorAggregator("TwoLevelManager", m.summaryInterval)
+	m.errorLog.Start()
+	defer m.errorLog.Stop()
+
+	firstPage := 1
+	m.checkpoint = nil
+	if store, ok := m.store.(db.CrawlCheckpointer); ok {
+		firstPage = m.resumePage(ctx, store)
+		m.checkpoint = newPageCheckpoint(ctx, store, m.baseURLPattern, firstPage)
+	}
 
 	// Start Level 2 workers first (content workers that save to MongoDB)
+	// Overlapping pages can list the same article; claimed makes sure it's fetched once
 	var contentWg sync.WaitGroup
-	m.startContentWorkers(ctx, &contentWg, urlChan)
+	batch := newArticleBatch(m.store, m.saveBatchSize)
+	if batch != nil {
+		batch.onSaved = func(n int) {
+			m.articlesSaved.Add(int64(n))
+			reporter.AddSaved(n)
+		}
+	}
+	m.startContentWorkers(ctx, &contentWg, urlChan, newURLSet(), batch, reporter)
 
 	// Start Level 1 workers (URL fetchers)
 	var urlFetcherWg sync.WaitGroup
-	m.startURLFetcherWorkers(ctx, &urlFetcherWg, pageRangeChan, urlChan)
+	m.startURLFetcherWorkers(ctx, &urlFetcherWg, pageRangeChan, urlChan, reporter)
 
 	// Manager generates page ranges and sends to pageRangeChan
-	go m.generatePageRanges(ctx, pageRangeChan)
+	// reachedEnd is written before the channel is closed, so it is safe to read once the URL fetchers are done
+	var reachedEnd bool
+	go func() {
+		reachedEnd = m.generatePageRanges(ctx, pageRangeChan, firstPage)
+		close(pageRangeChan)
+	}()
 
 	// Wait for all URL fetcher workers to finish
 	urlFetcherWg.Wait()
 	close(urlChan) // Close URL channel when all URLs are extracted
 
-	// Wait for all content workers to finish
+	// Wait for all content workers to finish, then save the articles still buffered
 	contentWg.Wait()
+	if batch != nil {
+		batch.flush(context.WithoutCancel(ctx))
+	}
+
+	// A finished crawl starts over next time, picking up posts added to the first pages since
+	if m.checkpoint != nil && reachedEnd && ctx.Err() == nil {
+		if err := m.checkpoint.store.ClearCrawlCheckpoint(ctx, m.baseURLPattern); err != nil {
+			logging.Warnf("Failed to clear crawl checkpoint: %v", err)
+		}
+	}
 
 	return nil
 }
 
-// generatePageRanges generates page ranges and sends them to the channel
+// resumePage returns the page to start crawling from: the one after the saved checkpoint, or
+// page 1 if there is none, it can't be loaded or the manager is configured to start fresh
+func (m *TwoLevelManager) resumePage(ctx context.Context, store db.CrawlCheckpointer) int {
+	if m.fresh {
+		if err := store.ClearCrawlCheckpoint(ctx, m.baseURLPattern); err != nil {
+			logging.Warnf("Failed to clear crawl checkpoint: %v", err)
+		}
+		return 1
+	}
+
+	lastPage, found, err := store.LoadCrawlCheckpoint(ctx, m.baseURLPattern)
+	if err != nil {
+		logging.Warnf("Failed to load crawl checkpoint, starting from page 1: %v", err)
+		return 1
+	}
+	if !found {
+		return 1
+	}
+	logging.Infof("Resuming crawl after page %d (use -fresh to start over)", lastPage)
+	return lastPage + 1
+}
+
+// generatePageRanges generates page ranges starting at firstPage and sends them to the channel
 // Continues until a page returns no URLs (indicating end of pagination)
 // or until MaxPages is reached (if set)
-func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan chan<- PageRange) {
-	defer close(pageRangeChan)
-
-	currentPage := 1
+// Returns true if it stopped at the end of pagination rather than at MaxPages or cancellation
+func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan chan<- PageRange, firstPage int) bool {
+	currentPage := firstPage
 	htmlFetcher := urls.NewHTMLFetcher(m.extractor)
 	pagesProcessed := 0
 
@@ -98,14 +232,14 @@ func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan
 		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		default:
 		}
 
 		// Check if we've reached the max pages limit
 		if m.maxPages > 0 && pagesProcessed >= m.maxPages {
-			log.Printf("Reached max pages limit (%d), stopping pagination", m.maxPages)
-			return
+			logging.Infof("Reached max pages limit (%d), stopping pagination", m.maxPages)
+			return false
 		}
 
 		// Check if the first page of this batch has URLs
@@ -114,8 +248,8 @@ func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan
 		urls, err := htmlFetcher.Fetch(firstPageURL)
 		if err != nil || len(urls) == 0 {
 			// No URLs found, we've reached the end
-			log.Printf("No URLs found at page %d, stopping pagination", currentPage)
-			return
+			logging.Infof("No URLs found at page %d, stopping pagination", currentPage)
+			return true
 		}
 
 		// Create a range for the current batch
@@ -125,11 +259,12 @@ func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan
 		}
 
 		// Send the range to workers
+		m.checkpoint.add(pageRange)
 		select {
 		case pageRangeChan <- pageRange:
-			log.Printf("Generated page range: %d-%d", pageRange.Start, pageRange.End)
+			logging.Infof("Generated page range: %d-%d", pageRange.Start, pageRange.End)
 		case <-ctx.Done():
-			return
+			return false
 		}
 
 		// Move to next batch
@@ -143,7 +278,7 @@ func (m *TwoLevelManager) generatePageRanges(ctx context.Context, pageRangeChan
 // - Fetch HTML from each page in the range
 // - Extract article URLs from each page
 // - Send each URL to urlChan
-func (m *TwoLevelManager) startURLFetcherWorkers(ctx context.Context, wg *sync.WaitGroup, pageRangeChan <-chan PageRange, urlChan chan<- string) {
+func (m *TwoLevelManager) startURLFetcherWorkers(ctx context.Context, wg *sync.WaitGroup, pageRangeChan <-chan PageRange, urlChan chan<- crawlURL, reporter *progress.Reporter) {
 	for i := 0; i < m.urlFetcherWorkers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
@@ -160,8 +295,8 @@ func (m *TwoLevelManager) startURLFetcherWorkers(ctx context.Context, wg *sync.W
 					}
 
 					// Process this page range
-					if err := m.processPageRange(ctx, workerID, pageRange, htmlFetcher, urlChan); err != nil {
-						log.Printf("Worker %d: Error processing page range %d-%d: %v", workerID, pageRange.Start, pageRange.End, err)
+					if err := m.processPageRange(ctx, workerID, pageRange, htmlFetcher, urlChan, reporter); err != nil {
+						m.errorLog.Warnf(err, "Worker %d: Error processing page range %d-%d: %v", workerID, pageRange.Start, pageRange.End, err)
 					}
 
 				case <-ctx.Done():
@@ -176,27 +311,48 @@ func (m *TwoLevelManager) startURLFetcherWorkers(ctx context.Context, wg *sync.W
 // - Read URLs from urlChan
 // - Fetch article content from each URL
 // - Save content to MongoDB
-func (m *TwoLevelManager) startContentWorkers(ctx context.Context, wg *sync.WaitGroup, urlChan <-chan string) {
+// Articles are saved through batch when it is non-nil, which counts them as they are written
+func (m *TwoLevelManager) startContentWorkers(ctx context.Context, wg *sync.WaitGroup, urlChan <-chan crawlURL, claimed *urlSet, batch *articleBatch, reporter *progress.Reporter) {
 	for i := 0; i < m.contentWorkers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
 
-			contentWorker := NewWorker(m.dbClient)
+			contentWorker := NewWorker(m.store)
+			contentWorker.claimed = claimed
+			contentWorker.batch = batch
+			contentWorker.MinTextLength = m.minTextLength
 
 			for {
 				select {
-				case url, ok := <-urlChan:
+				case crawled, ok := <-urlChan:
 					if !ok {
 						// Channel closed, no more URLs
 						return
 					}
+					url := crawled.url
 
 					// Process this URL: fetch content and save to MongoDB
-					if err := contentWorker.ProcessURL(ctx, url); err != nil {
-						log.Printf("Content worker %d: Error processing URL %s: %v", workerID, url, err)
+					err := contentWorker.ProcessURL(ctx, url)
+					if errors.Is(err, ErrAlreadyFetched) {
+						logging.Debugf("Content worker %d: Skipping already fetched URL %s", workerID, url)
+					} else if isSkippedPage(err) {
+						logging.Debugf("Content worker %d: Skipping URL %s: %v", workerID, url, err)
+					} else if err != nil {
+						m.errorLog.Warnf(err, "Content worker %d: Error processing URL %s: %v", workerID, url, err)
 					} else {
-						log.Printf("Content worker %d: Successfully processed %s", workerID, url)
+						logging.Debugf("Content worker %d: Successfully processed %s", workerID, url)
+						if batch == nil {
+							m.articlesSaved.Add(1)
+							reporter.AddSaved(1)
+						}
+					}
+
+					// A URL cut short by cancellation is fetched again when the crawl resumes
+					if ctx.Err() == nil {
+						if err := crawled.processed(); err != nil {
+							m.errorLog.Warnf(err, "Content worker %d: %v", workerID, err)
+						}
 					}
 
 				case <-ctx.Done():
@@ -209,10 +365,11 @@ func (m *TwoLevelManager) startContentWorkers(ctx context.Context, wg *sync.Wait
 
 // processPageRange processes a single page range (used by Level 1 workers)
 // Fetches URLs from all pages in the range and sends them to urlChan
-func (m *TwoLevelManager) processPageRange(ctx context.Context, workerID int, pageRange PageRange, htmlFetcher *urls.HTMLFetcher, urlChan chan<- string) error {
-	log.Printf("Worker %d: Processing page range %d-%d", workerID, pageRange.Start, pageRange.End)
+func (m *TwoLevelManager) processPageRange(ctx context.Context, workerID int, pageRange PageRange, htmlFetcher *urls.HTMLFetcher, urlChan chan<- crawlURL, reporter *progress.Reporter) error {
+	logging.Debugf("Worker %d: Processing page range %d-%d", workerID, pageRange.Start, pageRange.End)
 
 	totalURLs := 0
+	failedPages := 0
 
 	// Process each page in the range
 	for pageNum := pageRange.Start; pageNum <= pageRange.End; pageNum++ {
@@ -227,23 +384,34 @@ func (m *TwoLevelManager) processPageRange(ctx context.Context, workerID int, pa
 		urls, err := m.fetchURLsFromPage(ctx, pageNum, htmlFetcher)
 		if err != nil {
 			// Log error but continue with next page
-			log.Printf("Worker %d: Error fetching URLs from page %d: %v", workerID, pageNum, err)
+			m.errorLog.Warnf(err, "Worker %d: Error fetching URLs from page %d: %v", workerID, pageNum, err)
+			failedPages++
 			continue
 		}
+		m.pagesProcessed.Add(1)
+		reporter.AddPages(1)
 
 		// Send each URL to the channel
 		for _, url := range urls {
+			processed := m.checkpoint.urlSent(pageRange.Start)
 			select {
-			case urlChan <- url:
+			case urlChan <- crawlURL{url: url, processed: processed}:
 				totalURLs++
+				m.urlsExtracted.Add(1)
+				reporter.AddURLs(1)
 			case <-ctx.Done():
 				return ctx.Err()
 			}
 		}
 	}
 
-	log.Printf("Worker %d: Extracted %d URLs from pages %d-%d", workerID, totalURLs, pageRange.Start, pageRange.End)
-	return nil
+	logging.Infof("Worker %d: Extracted %d URLs from pages %d-%d", workerID, totalURLs, pageRange.Start, pageRange.End)
+	// The checkpoint must not move past pages that weren't read, or a resumed crawl would skip them
+	if failedPages > 0 {
+		m.checkpoint.failed(pageRange.Start)
+		return fmt.Errorf("failed to fetch %d of %d pages", failedPages, pageRange.End-pageRange.Start+1)
+	}
+	return m.checkpoint.extracted(pageRange.Start)
 }
 
 // fetchURLsFromPage fetches URLs from a single page
@@ -267,3 +435,41 @@ func (m *TwoLevelManager) fetchURLsFromPage(ctx context.Context, pageNum int, ht
 
 	return result, nil
 }
+
+// startSummaries starts a goroutine that calls summarize with the running totals every
+// summaryInterval. The returned function stops it, waiting for it to exit, and then
+// summarizes the final totals once. Does nothing if summaryInterval isn't positive
+func (m *TwoLevelManager) startSummaries() (stop func()) {
+	if m.summaryInterval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+
+		ticker := time.NewTicker(m.summaryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.summarize(m.Progress())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+		m.summarize(m.Progress())
+	}
+}
+
+// logSummary logs the running totals of a two-level run
+func logSummary(p progress.Progress) {
+	logging.Infof("Progress: %d pages processed, %d URLs extracted, %d articles saved", p.PagesProcessed, p.URLsExtracted, p.ArticlesSaved)
+}
@@ -2,10 +2,16 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"blog-search/pkg/db"
+	"blog-search/pkg/progress"
 	"blog-search/pkg/urls"
 )
 
@@ -123,3 +129,78 @@ func TestTwoLevelManager_ProcessPaginatedPages_MultipleBatches(t *testing.T) {
 		t.Error("Expected articles to be saved, but found none")
 	}
 }
+
+func TestTwoLevelManager_CountsProgress(t *testing.T) {
+	// Pages 1-3 each list 2 posts; page 4 is empty, which ends pagination
+	paragraph := "<p>" + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page int
+		if _, err := fmt.Sscanf(r.URL.Path, "/page/%d", &page); err == nil {
+			if page <= 3 {
+				fmt.Fprintf(w, "<html><body>post-%d-a post-%d-b</body></html>", page, page)
+			} else {
+				fmt.Fprint(w, "<html><body></body></html>")
+			}
+			return
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body><article><h1>%s</h1>%s%s</article></body></html>", r.URL.Path, r.URL.Path, paragraph, paragraph)
+	}))
+	defer server.Close()
+
+	// Extracts every "post-..." word as an article URL on the test server
+	extractor := func(html string) ([]urls.URL, error) {
+		var found []urls.URL
+		for _, word := range strings.FieldsFunc(html, func(r rune) bool { return r == ' ' || r == '<' || r == '>' }) {
+			if strings.HasPrefix(word, "post-") {
+				found = append(found, urls.URL{Location: server.URL + "/" + word})
+			}
+		}
+		return found, nil
+	}
+
+	store := &fakeArticleStore{}
+	manager := NewTwoLevelManager(Config{
+		URLFetcherWorkers: 2,
+		ContentWorkers:    3,
+		DBClient:          store,
+		PagesPerBatch:     1,
+		BaseURLPattern:    server.URL + "/page/%d",
+		Extractor:         extractor,
+		SummaryInterval:   time.Millisecond,
+	})
+	var mu sync.Mutex
+	var summaries []progress.Progress
+	manager.summarize = func(p progress.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		summaries = append(summaries, p)
+	}
+
+	if err := manager.ProcessPaginatedPages(context.Background()); err != nil {
+		t.Fatalf("ProcessPaginatedPages failed: %v", err)
+	}
+
+	expected := progress.Progress{PagesProcessed: 3, URLsExtracted: 6, ArticlesSaved: 6}
+	if got := manager.Progress(); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if len(store.saved) != 6 {
+		t.Errorf("Expected 6 saved articles, got %d", len(store.saved))
+	}
+
+	mu.Lock()
+	count := len(summaries)
+	last := summaries[count-1]
+	mu.Unlock()
+	if last != expected {
+		t.Errorf("Expected the final summary to have the totals %+v, got %+v", expected, last)
+	}
+
+	// The summary goroutine has exited, so no more summaries arrive
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(summaries) != count {
+		t.Errorf("Expected no summaries after processing finished, got %d more", len(summaries)-count)
+	}
+}
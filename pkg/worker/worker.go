@@ -2,71 +2,217 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"blog-search/pkg/clock"
 	"blog-search/pkg/content"
 	"blog-search/pkg/db"
 	"blog-search/pkg/domain"
 	"blog-search/pkg/httpclient"
+	"blog-search/pkg/logging"
+	"blog-search/pkg/metrics"
+	"blog-search/pkg/urls"
 )
 
+// ErrAlreadyFetched is returned by ProcessURL for URLs that were skipped because they are
+// already stored or being fetched by another worker
+var ErrAlreadyFetched = errors.New("article already fetched")
+
 // Worker processes articles from URLs
 type Worker struct {
-	dbClient *db.Client
+	store   db.ArticleStore
+	claimed *urlSet       // Shared by a manager's workers so each URL is fetched once per run (optional)
+	batch   *articleBatch // Shared by a manager's workers to save articles in bulk (optional)
+	fetch   func(ctx context.Context, url string) (string, error)
+
+	// MinTextLength rejects pages with fewer characters of extracted text with
+	// content.ErrContentTooShort instead of saving them (0 = no minimum)
+	MinTextLength int
+
+	// SoftNotFoundMarkers are the texts that mark a page served with HTTP 200 as an error page,
+	// rejected with content.ErrSoftNotFound; nil uses content.DefaultSoftNotFoundMarkers
+	SoftNotFoundMarkers []string
+
+	// Clock stamps Article.CrawledAt; nil uses clock.Real
+	Clock clock.Clock
 }
 
 // NewWorker creates a new worker
-func NewWorker(dbClient *db.Client) *Worker {
+func NewWorker(store db.ArticleStore) *Worker {
 	return &Worker{
-		dbClient: dbClient,
+		store: store,
+		fetch: fetchHTML,
 	}
 }
 
 // ProcessURL processes a single URL: fetches, extracts, and saves to DB
+// Returns ErrAlreadyFetched without fetching if the article is already stored, and
+// content.ErrContentTooShort or content.ErrSoftNotFound without saving if the page has less
+// text than MinTextLength or is an error page
 func (w *Worker) ProcessURL(ctx context.Context, url string) error {
+	// Articles are claimed and stored under the normalized URL, so variants of a link dedup
+	key := urls.NormalizeOrRaw(url)
+
+	// Another worker of the same run already picked this URL up (e.g., overlapping pages)
+	if w.claimed != nil && !w.claimed.claim(key) {
+		return ErrAlreadyFetched
+	}
+
+	// Saved by an earlier run; a failed check only costs a redundant fetch
+	// Articles saved before URLs were normalized are stored under the raw URL, so both forms are checked
+	candidates := []string{key}
+	if url != key {
+		candidates = append(candidates, url)
+	}
+	existing, err := w.store.GetExistingArticleURLs(ctx, candidates)
+	if err != nil {
+		logging.Warnf("Worker: Failed to check whether %s is already stored: %v", url, err)
+	} else if existing[key] || existing[url] {
+		return ErrAlreadyFetched
+	}
+
+	article, err := w.fetchArticle(ctx, url, key)
+	if isSkippedPage(err) {
+		// Not a failure: the page is too thin to be an article or is an error page, so there is nothing to retry
+		return err
+	}
+	if err != nil {
+		metrics.FetchErrors.Inc()
+		w.recordFailure(ctx, url, err)
+		return err
+	}
+
+	// Buffered articles are saved by the batch; the manager flushes the rest when its workers are done
+	if w.batch != nil {
+		w.batch.add(context.WithoutCancel(ctx), article)
+		return nil
+	}
+
+	// Save to database, even if the crawl is being cancelled; a dropped connection is retried once
+	if err := db.SaveWithRetry(context.WithoutCancel(ctx), w.store, article); err != nil {
+		return fmt.Errorf("failed to save article: %w", err)
+	}
+	metrics.ArticlesSaved.Inc()
+
+	return nil
+}
+
+// fetchArticle fetches url and extracts its article, stored under key
+func (w *Worker) fetchArticle(ctx context.Context, url, key string) (*domain.Article, error) {
 	// Fetch HTML content
-	htmlContent, err := fetchHTML(url)
+	fetchStart := time.Now()
+	htmlContent, err := w.fetch(ctx, url)
+	metrics.FetchLatency.ObserveSince(fetchStart)
 	if err != nil {
-		return fmt.Errorf("failed to fetch HTML: %w", err)
+		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
+	}
+	if err := content.CheckSoftNotFound(htmlContent, w.SoftNotFoundMarkers); err != nil {
+		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
 	}
 
-	// Extract text and title
-	text, err := content.ExtractText(htmlContent)
+	// Extract text and title; the page URL lets relative links resolve
+	extractor := content.NewDefaultExtractorWithOptions(parsePageURL(url))
+	text, err := extractor.ExtractText(htmlContent)
 	if err != nil {
-		return fmt.Errorf("failed to extract text: %w", err)
+		return nil, fmt.Errorf("failed to extract text: %w", err)
 	}
 
-	title, err := content.ExtractTitle(htmlContent)
+	title, err := extractor.ExtractTitle(htmlContent)
 	if err != nil {
-		return fmt.Errorf("failed to extract title: %w", err)
+		return nil, fmt.Errorf("failed to extract title: %w", err)
+	}
+
+	if err := content.CheckTextLength(text, w.MinTextLength); err != nil {
+		return nil, err
 	}
 
 	// Create article document
-	article := &domain.Article{
-		URL:       url,
+	return &domain.Article{
+		URL:       key,
+		Host:      domain.HostFromURL(url),
 		Title:     title,
 		Text:      text,
-		CrawledAt: time.Now(),
+		Tags:      content.ExtractTags(htmlContent),
+		CrawledAt: w.now(),
+
+		ContentHash: domain.ContentHash(text),
+	}, nil
+}
+
+// now returns the current time from the worker's clock
+func (w *Worker) now() time.Time {
+	if w.Clock == nil {
+		return clock.Real.Now()
 	}
+	return w.Clock.Now()
+}
 
-	// Save to database
-	if err := w.dbClient.SaveArticle(ctx, article); err != nil {
-		return fmt.Errorf("failed to save article: %w", err)
+// isSkippedPage reports whether a ProcessURL error means the page isn't an article,
+// which managers count as skipped rather than failed
+func isSkippedPage(err error) bool {
+	return errors.Is(err, content.ErrContentTooShort) || errors.Is(err, content.ErrSoftNotFound)
+}
+
+// parsePageURL parses the URL a page was fetched from for the extractor; nil if it isn't absolute
+func parsePageURL(rawURL string) *url.URL {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !parsed.IsAbs() {
+		return nil
 	}
+	return parsed
+}
 
-	return nil
+// recordFailure records url as failed in the store (if it keeps failed URLs) for retry-failed
+// Failures caused by the crawl being cancelled aren't recorded
+func (w *Worker) recordFailure(ctx context.Context, url string, fetchErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := db.RecordFailure(ctx, w.store, url, fetchErr); err != nil {
+		logging.Warnf("Worker: Failed to record failed URL %s: %v", url, err)
+	}
+}
+
+// urlSet is a goroutine-safe set of URLs claimed by workers
+type urlSet struct {
+	mu   sync.Mutex
+	urls map[string]bool
+}
+
+func newURLSet() *urlSet {
+	return &urlSet{urls: make(map[string]bool)}
 }
 
+// claim adds url to the set and reports whether it was not already there
+func (s *urlSet) claim(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.urls[url] {
+		return false
+	}
+	s.urls[url] = true
+	return true
+}
+
+// fetchTimeout bounds a single page fetch so one slow server can't hold a worker indefinitely
+const fetchTimeout = 30 * time.Second
+
 // fetchHTML fetches HTML content from a URL
 // Uses CloudflareClient to avoid 403 errors from Cloudflare-protected sites
-func fetchHTML(url string) (string, error) {
+// The request is aborted when ctx is done or after fetchTimeout
+func fetchHTML(ctx context.Context, url string) (string, error) {
 	client := httpclient.NewClient(httpclient.CloudflareClient)
 
-	resp, err := client.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	resp, err := client.GetWithContext(ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch URL: %w", err)
 	}
@@ -76,15 +222,15 @@ func fetchHTML(url string) (string, error) {
 		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.ReadBody(resp)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	bodyStr := string(body)
 
-	// Check if we got an error page instead of actual HTML
-	if strings.Contains(bodyStr, "Not Acceptable") || strings.TrimSpace(bodyStr) == "" {
+	// Error pages served with 200 (e.g., "Not Acceptable") are caught by content.CheckSoftNotFound
+	if strings.TrimSpace(bodyStr) == "" {
 		return "", fmt.Errorf("server returned error or empty response (status: %d)", resp.StatusCode)
 	}
 
@@ -0,0 +1,247 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-search/pkg/clock"
+	"blog-search/pkg/content"
+	"blog-search/pkg/domain"
+)
+
+// fakeArticleStore is an in-memory db.ArticleStore
+type fakeArticleStore struct {
+	mu       sync.Mutex
+	existing map[string]bool
+	saved    []*domain.Article
+	checkErr error
+	failed   map[string]int // URL -> recorded failures
+}
+
+func (s *fakeArticleStore) GetExistingArticleURLs(ctx context.Context, candidates []string) (map[string]bool, error) {
+	if s.checkErr != nil {
+		return nil, s.checkErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := make(map[string]bool)
+	for _, url := range candidates {
+		if s.existing[url] {
+			found[url] = true
+		}
+	}
+	return found, nil
+}
+
+func (s *fakeArticleStore) GetAllURLs(ctx context.Context) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make(map[string]bool)
+	for url := range s.existing {
+		all[url] = true
+	}
+	for _, article := range s.saved {
+		all[article.URL] = true
+	}
+	return all, nil
+}
+
+func (s *fakeArticleStore) SaveArticle(ctx context.Context, article *domain.Article) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, article)
+	return nil
+}
+
+func (s *fakeArticleStore) RecordFailedURL(ctx context.Context, url string, fetchErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed == nil {
+		s.failed = make(map[string]int)
+	}
+	s.failed[url]++
+	return nil
+}
+
+// countingFetch returns a fetch function serving a small article and counting calls per URL
+func countingFetch(calls map[string]int, mu *sync.Mutex) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, url string) (string, error) {
+		mu.Lock()
+		calls[url]++
+		mu.Unlock()
+		paragraph := "<p>" + strings.Repeat("Partitions let consumers scale horizontally. ", 20) + "</p>"
+		return "<html><head><title>Kafka</title></head><body><article><h1>Kafka</h1>" + paragraph + paragraph + "</article></body></html>", nil
+	}
+}
+
+func TestWorker_ProcessURL_SkipsStoredURL(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	store := &fakeArticleStore{existing: map[string]bool{"https://example.com/seeded": true}}
+	w := &Worker{store: store, fetch: countingFetch(calls, &mu)}
+
+	err := w.ProcessURL(context.Background(), "https://example.com/seeded")
+	if !errors.Is(err, ErrAlreadyFetched) {
+		t.Fatalf("Expected ErrAlreadyFetched, got %v", err)
+	}
+	if calls["https://example.com/seeded"] != 0 {
+		t.Errorf("Expected no fetch for a stored URL, got %d", calls["https://example.com/seeded"])
+	}
+	if len(store.saved) != 0 {
+		t.Errorf("Expected nothing saved, got %d articles", len(store.saved))
+	}
+}
+
+func TestWorker_ProcessURL_SkipsURLStoredUnderRawKey(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	// Saved before URLs were normalized, with its trailing slash and tracking parameter
+	raw := "https://example.com/post/?utm_source=rss"
+	store := &fakeArticleStore{existing: map[string]bool{raw: true}}
+	w := &Worker{store: store, fetch: countingFetch(calls, &mu)}
+
+	if err := w.ProcessURL(context.Background(), raw); !errors.Is(err, ErrAlreadyFetched) {
+		t.Fatalf("Expected ErrAlreadyFetched, got %v", err)
+	}
+	if len(calls) != 0 || len(store.saved) != 0 {
+		t.Errorf("Expected no fetch or save, got %d fetches and %d saves", len(calls), len(store.saved))
+	}
+}
+
+func TestWorker_ProcessURL_SharedClaimFetchesOnce(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	store := &fakeArticleStore{}
+	claimed := newURLSet()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &Worker{store: store, claimed: claimed, fetch: countingFetch(calls, &mu)}
+			if err := w.ProcessURL(context.Background(), "https://example.com/overlap"); err != nil && !errors.Is(err, ErrAlreadyFetched) {
+				t.Errorf("ProcessURL failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls["https://example.com/overlap"] != 1 {
+		t.Errorf("Expected exactly one fetch for a URL emitted by several workers, got %d", calls["https://example.com/overlap"])
+	}
+	if len(store.saved) != 1 {
+		t.Errorf("Expected one saved article, got %d", len(store.saved))
+	}
+}
+
+func TestWorker_ProcessURL_CheckFailureStillFetches(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	store := &fakeArticleStore{checkErr: errors.New("mongo unavailable")}
+	w := &Worker{store: store, fetch: countingFetch(calls, &mu)}
+
+	if err := w.ProcessURL(context.Background(), "https://example.com/new"); err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	if calls["https://example.com/new"] != 1 {
+		t.Errorf("Expected the URL to be fetched once, got %d", calls["https://example.com/new"])
+	}
+}
+
+func TestFetchHTML_ContextDeadlineAbortsFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := fetchHTML(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected fetch to abort promptly, took %v", elapsed)
+	}
+}
+
+func TestWorker_ProcessURL_RecordsFetchFailure(t *testing.T) {
+	store := &fakeArticleStore{}
+	w := &Worker{store: store, fetch: func(ctx context.Context, url string) (string, error) {
+		return "", errors.New("unexpected status code: 503")
+	}}
+
+	if err := w.ProcessURL(context.Background(), "https://example.com/down"); err == nil {
+		t.Fatal("Expected the fetch error, got nil")
+	}
+	if store.failed["https://example.com/down"] != 1 {
+		t.Errorf("Expected the URL to be recorded as failed once, got %v", store.failed)
+	}
+	if len(store.saved) != 0 {
+		t.Errorf("Expected nothing saved, got %d articles", len(store.saved))
+	}
+}
+
+func TestWorker_ProcessURL_MinTextLength(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	store := &fakeArticleStore{}
+	w := &Worker{store: store, fetch: countingFetch(calls, &mu), MinTextLength: 5000}
+
+	err := w.ProcessURL(context.Background(), "https://example.com/thin")
+	if !errors.Is(err, content.ErrContentTooShort) {
+		t.Fatalf("Expected ErrContentTooShort, got %v", err)
+	}
+	if len(store.saved) != 0 || len(store.failed) != 0 {
+		t.Errorf("Expected a thin page to be neither saved nor recorded as failed, got %d saved, failed %v", len(store.saved), store.failed)
+	}
+
+	w.MinTextLength = 100
+	if err := w.ProcessURL(context.Background(), "https://example.com/long"); err != nil {
+		t.Fatalf("Expected a page over the minimum to be saved, got %v", err)
+	}
+	if len(store.saved) != 1 {
+		t.Errorf("Expected 1 saved article, got %d", len(store.saved))
+	}
+}
+
+func TestWorker_ProcessURL_SkipsSoftNotFound(t *testing.T) {
+	store := &fakeArticleStore{}
+	w := &Worker{store: store, fetch: func(ctx context.Context, url string) (string, error) {
+		return `<html><head><title>404 - Not Found</title></head><body><h1>404 - Not Found</h1></body></html>`, nil
+	}}
+
+	err := w.ProcessURL(context.Background(), "https://example.com/gone")
+	if !errors.Is(err, content.ErrSoftNotFound) {
+		t.Fatalf("Expected ErrSoftNotFound, got %v", err)
+	}
+	if len(store.saved) != 0 || len(store.failed) != 0 {
+		t.Errorf("Expected a soft 404 to be neither saved nor recorded as failed, got %d saved, failed %v", len(store.saved), store.failed)
+	}
+}
+
+func TestWorker_ProcessURL_StampsCrawledAtFromClock(t *testing.T) {
+	var mu sync.Mutex
+	crawledAt := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	store := &fakeArticleStore{}
+	w := &Worker{store: store, fetch: countingFetch(make(map[string]int), &mu), Clock: clock.NewFake(crawledAt)}
+
+	if err := w.ProcessURL(context.Background(), "https://example.com/kafka"); err != nil {
+		t.Fatalf("ProcessURL failed: %v", err)
+	}
+	if len(store.saved) != 1 || !store.saved[0].CrawledAt.Equal(crawledAt) {
+		t.Errorf("Expected one article crawled at %v, got %v", crawledAt, store.saved)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"blog-search/pkg/content"
+	"blog-search/pkg/domain"
+)
+
+const defaultReprocessPageSize = 200
+
+// reprocessStore reads and updates stored articles; db.Client satisfies it
+type reprocessStore interface {
+	GetArticlesPaged(ctx context.Context, offset, limit int) ([]domain.Article, error)
+	SaveArticle(ctx context.Context, article *domain.Article) error
+}
+
+// reprocessStats counts what happened to each article during reprocessing
+type reprocessStats struct {
+	Updated   int // Title or text changed and was saved
+	Unchanged int // Re-extraction produced the same title and text
+	NoRawHTML int // Crawled without -keep-raw-html
+	Failed    int // Extraction or save failed
+}
+
+// runReprocess re-runs extraction on the raw HTML stored with each article
+func runReprocess(args []string) {
+	fs := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	extractorName := fs.String("extractor", "default", "Extractor to re-run: default or data-engineering-podcast")
+	pageSize := fs.Int("page-size", defaultReprocessPageSize, "Articles read from MongoDB per query")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	extractor, err := contentExtractorByName(*extractorName)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	ctx := context.Background()
+	dbClient := initializeDatabase(ctx)
+	defer dbClient.Close(ctx)
+
+	stats, err := reprocessArticles(ctx, dbClient, extractor, *pageSize)
+	log.Printf("Reprocess: %d updated, %d unchanged, %d without raw HTML, %d failed",
+		stats.Updated, stats.Unchanged, stats.NoRawHTML, stats.Failed)
+	if err != nil {
+		log.Fatalf("Reprocess failed: %v", err)
+	}
+}
+
+// contentExtractorByName returns the content extractor for the -extractor flag
+func contentExtractorByName(name string) (content.Extractor, error) {
+	switch name {
+	case "default":
+		return content.NewDefaultExtractor(), nil
+	case "data-engineering-podcast":
+		return content.NewDataEngineeringPodcastExtractor(), nil
+	default:
+		return nil, fmt.Errorf("unknown extractor %q (want default or data-engineering-podcast)", name)
+	}
+}
+
+// reprocessArticles re-extracts title and text from each stored article's raw HTML and saves
+// articles whose content changed. Per-article failures are logged and counted; an error is
+// returned only if reading articles fails.
+func reprocessArticles(ctx context.Context, store reprocessStore, extractor content.Extractor, pageSize int) (reprocessStats, error) {
+	var stats reprocessStats
+	if pageSize <= 0 {
+		pageSize = defaultReprocessPageSize
+	}
+
+	// Updates don't change crawled_at, so the newest-first page order stays stable while saving
+	for offset := 0; ; offset += pageSize {
+		articles, err := store.GetArticlesPaged(ctx, offset, pageSize)
+		if err != nil {
+			return stats, fmt.Errorf("failed to read articles at offset %d: %w", offset, err)
+		}
+
+		for i := range articles {
+			article := &articles[i]
+			if strings.TrimSpace(article.RawHTML) == "" {
+				stats.NoRawHTML++
+				continue
+			}
+
+			changed, err := reextractArticle(article, extractor)
+			if err != nil {
+				log.Printf("Reprocess: failed to extract %s: %v", article.URL, err)
+				stats.Failed++
+				continue
+			}
+			if !changed {
+				stats.Unchanged++
+				continue
+			}
+
+			if err := store.SaveArticle(ctx, article); err != nil {
+				log.Printf("Reprocess: failed to save %s: %v", article.URL, err)
+				stats.Failed++
+				continue
+			}
+			stats.Updated++
+		}
+
+		if len(articles) < pageSize {
+			return stats, nil
+		}
+	}
+}
+
+// reextractArticle replaces the article's title, text, tags and content hash with a fresh
+// extraction from its raw HTML, and reports whether anything changed
+func reextractArticle(article *domain.Article, extractor content.Extractor) (bool, error) {
+	title, err := extractor.ExtractTitle(article.RawHTML)
+	if err != nil {
+		return false, fmt.Errorf("failed to extract title: %w", err)
+	}
+	text, err := extractor.ExtractText(article.RawHTML)
+	if err != nil {
+		return false, fmt.Errorf("failed to extract text: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return false, fmt.Errorf("extracted text is empty")
+	}
+
+	tags := content.ExtractTags(article.RawHTML)
+
+	if title == article.Title && text == article.Text && slices.Equal(tags, article.Tags) {
+		return false, nil
+	}
+	article.Title = title
+	article.Text = text
+	article.Tags = tags
+	article.ContentHash = domain.ContentHash(text)
+	return true, nil
+}
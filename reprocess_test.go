@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"blog-search/pkg/domain"
+)
+
+// memoryArticles is an in-memory reprocessStore that keeps articles in page order
+type memoryArticles struct {
+	articles []domain.Article
+	saves    int
+}
+
+func (m *memoryArticles) GetArticlesPaged(ctx context.Context, offset, limit int) ([]domain.Article, error) {
+	if offset >= len(m.articles) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(m.articles) {
+		end = len(m.articles)
+	}
+	return append([]domain.Article(nil), m.articles[offset:end]...), nil
+}
+
+func (m *memoryArticles) SaveArticle(ctx context.Context, article *domain.Article) error {
+	m.saves++
+	for i := range m.articles {
+		if m.articles[i].URL == article.URL {
+			m.articles[i] = *article
+			return nil
+		}
+	}
+	return fmt.Errorf("article %s not found", article.URL)
+}
+
+// headingExtractor uses the first <h2> as the text, like a site-specific extractor would
+type headingExtractor struct{}
+
+func (headingExtractor) ExtractTitle(htmlContent string) (string, error) {
+	return "Kafka Internals", nil
+}
+
+func (headingExtractor) ExtractText(htmlContent string) (string, error) {
+	start := strings.Index(htmlContent, "<h2>")
+	end := strings.Index(htmlContent, "</h2>")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("no <h2> found")
+	}
+	return htmlContent[start+len("<h2>") : end], nil
+}
+
+func TestReprocessArticles_DifferentExtractorUpdatesText(t *testing.T) {
+	rawHTML := "<html><body><h1>Kafka Internals</h1><h2>Log segments and compaction</h2><p>Footer links</p></body></html>"
+	store := &memoryArticles{articles: []domain.Article{
+		{URL: "https://example.com/kafka", Title: "Kafka Internals", Text: "Footer links", RawHTML: rawHTML},
+		{URL: "https://example.com/old", Title: "Old Post", Text: "Crawled without raw HTML"},
+		{URL: "https://example.com/same", Title: "Kafka Internals", Text: "Already right", RawHTML: "<h2>Already right</h2>"},
+	}}
+
+	stats, err := reprocessArticles(context.Background(), store, headingExtractor{}, 2)
+	if err != nil {
+		t.Fatalf("reprocessArticles failed: %v", err)
+	}
+
+	if got := store.articles[0]; got.Text != "Log segments and compaction" || got.ContentHash != domain.ContentHash(got.Text) {
+		t.Errorf("Expected re-extracted text and hash, got text %q hash %q", got.Text, got.ContentHash)
+	}
+	if store.articles[1].Text != "Crawled without raw HTML" {
+		t.Errorf("Expected the article without raw HTML to be left alone, got %q", store.articles[1].Text)
+	}
+
+	expected := reprocessStats{Updated: 1, Unchanged: 1, NoRawHTML: 1}
+	if stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+	if store.saves != 1 {
+		t.Errorf("Expected only the changed article to be saved, got %d saves", store.saves)
+	}
+}
+
+func TestReprocessArticles_CountsExtractionFailures(t *testing.T) {
+	store := &memoryArticles{articles: []domain.Article{
+		{URL: "https://example.com/no-heading", Text: "Body", RawHTML: "<p>No heading here</p>"},
+	}}
+
+	stats, err := reprocessArticles(context.Background(), store, headingExtractor{}, 10)
+	if err != nil {
+		t.Fatalf("reprocessArticles failed: %v", err)
+	}
+	if stats.Failed != 1 || store.articles[0].Text != "Body" {
+		t.Errorf("Expected a counted failure and untouched text, got %+v with text %q", stats, store.articles[0].Text)
+	}
+}
+
+func TestContentExtractorByName_Unknown(t *testing.T) {
+	if _, err := contentExtractorByName("readability"); err == nil {
+		t.Error("Expected an error for an unknown extractor")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"blog-search/pkg/domain"
+	"blog-search/pkg/pipeline"
+)
+
+// failedURLStore reads, re-records and clears failed URLs and saves retried articles; db.Client satisfies it
+type failedURLStore interface {
+	GetFailedURLs(ctx context.Context) ([]domain.FailedURL, error)
+	RecordFailedURL(ctx context.Context, url string, fetchErr error) error
+	ClearFailedURL(ctx context.Context, url string) error
+	SaveArticle(ctx context.Context, article *domain.Article) error
+}
+
+// retryStats counts what happened to each failed URL during a retry
+type retryStats struct {
+	Succeeded int // Fetched, saved and cleared from the failed URLs
+	Failed    int // Failed again; the attempt is recorded
+	Skipped   int // Already failed -max-attempts times
+}
+
+// runRetryFailed re-processes the URLs recorded as failed by earlier crawls
+func runRetryFailed(args []string) {
+	fs := flag.NewFlagSet("retry-failed", flag.ExitOnError)
+	maxAttempts := fs.Int("max-attempts", 0, "Skip URLs that already failed this many times (0 = retry all)")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	ctx := context.Background()
+	dbClient := initializeDatabase(ctx)
+	defer dbClient.Close(ctx)
+
+	runCtx, stop := signalContext()
+	defer stop()
+
+	stats, err := retryFailedURLs(runCtx, dbClient, pipeline.NewHTTPContentProcessor(), *maxAttempts)
+	log.Printf("Retry failed: %d succeeded, %d failed again, %d skipped", stats.Succeeded, stats.Failed, stats.Skipped)
+	if err != nil {
+		log.Fatalf("Retry failed: %v", err)
+	}
+}
+
+// retryFailedURLs processes each failed URL again: successes are saved and cleared, and
+// failures are recorded as another attempt. An error is returned only if reading the failed
+// URLs fails or ctx is cancelled.
+func retryFailedURLs(ctx context.Context, store failedURLStore, processor pipeline.ContentProcessor, maxAttempts int) (retryStats, error) {
+	var stats retryStats
+
+	failed, err := store.GetFailedURLs(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read failed URLs: %w", err)
+	}
+
+	for _, entry := range failed {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if maxAttempts > 0 && entry.Attempts >= maxAttempts {
+			stats.Skipped++
+			continue
+		}
+
+		if err := retryURL(ctx, store, processor, entry.URL); err != nil {
+			if ctx.Err() != nil {
+				// Interrupted, not a real failure
+				return stats, ctx.Err()
+			}
+			log.Printf("Retry failed: %s failed again: %v", entry.URL, err)
+			if recordErr := store.RecordFailedURL(ctx, entry.URL, err); recordErr != nil {
+				log.Printf("Retry failed: failed to record attempt for %s: %v", entry.URL, recordErr)
+			}
+			stats.Failed++
+			continue
+		}
+
+		if err := store.ClearFailedURL(ctx, entry.URL); err != nil {
+			log.Printf("Retry failed: saved %s but failed to clear it: %v", entry.URL, err)
+		}
+		stats.Succeeded++
+	}
+
+	return stats, nil
+}
+
+// retryURL fetches and saves a single URL
+func retryURL(ctx context.Context, store failedURLStore, processor pipeline.ContentProcessor, url string) error {
+	article, err := processor.ProcessContent(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := store.SaveArticle(ctx, article); err != nil {
+		return fmt.Errorf("failed to save article: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+// memoryFailedURLs is an in-memory failedURLStore
+type memoryFailedURLs struct {
+	failed map[string]*domain.FailedURL
+	order  []string
+	saved  []*domain.Article
+}
+
+func newMemoryFailedURLs(entries ...domain.FailedURL) *memoryFailedURLs {
+	m := &memoryFailedURLs{failed: make(map[string]*domain.FailedURL)}
+	for i := range entries {
+		m.failed[entries[i].URL] = &entries[i]
+		m.order = append(m.order, entries[i].URL)
+	}
+	return m
+}
+
+func (m *memoryFailedURLs) GetFailedURLs(ctx context.Context) ([]domain.FailedURL, error) {
+	var out []domain.FailedURL
+	for _, url := range m.order {
+		if entry, ok := m.failed[url]; ok {
+			out = append(out, *entry)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryFailedURLs) RecordFailedURL(ctx context.Context, url string, fetchErr error) error {
+	entry, ok := m.failed[url]
+	if !ok {
+		entry = &domain.FailedURL{URL: url}
+		m.failed[url] = entry
+		m.order = append(m.order, url)
+	}
+	entry.Attempts++
+	entry.Error = fetchErr.Error()
+	entry.LastTried = time.Now()
+	return nil
+}
+
+func (m *memoryFailedURLs) ClearFailedURL(ctx context.Context, url string) error {
+	delete(m.failed, url)
+	return nil
+}
+
+func (m *memoryFailedURLs) SaveArticle(ctx context.Context, article *domain.Article) error {
+	m.saved = append(m.saved, article)
+	return nil
+}
+
+// stubProcessor returns an article for every URL except the failing ones
+type stubProcessor struct {
+	failing map[string]bool
+}
+
+func (p stubProcessor) ProcessContent(ctx context.Context, url string) (*domain.Article, error) {
+	if p.failing[url] {
+		return nil, errors.New("unexpected status code: 503")
+	}
+	return &domain.Article{URL: url, Title: "Recovered", Text: "Body", CrawledAt: time.Now()}, nil
+}
+
+func TestRetryFailedURLs_ClearsSuccessesAndRecordsFailures(t *testing.T) {
+	store := newMemoryFailedURLs(
+		domain.FailedURL{URL: "https://example.com/recovered", Attempts: 1, Error: "timeout"},
+		domain.FailedURL{URL: "https://example.com/still-down", Attempts: 1, Error: "timeout"},
+		domain.FailedURL{URL: "https://example.com/given-up", Attempts: 3, Error: "timeout"},
+	)
+	processor := stubProcessor{failing: map[string]bool{"https://example.com/still-down": true}}
+
+	stats, err := retryFailedURLs(context.Background(), store, processor, 3)
+	if err != nil {
+		t.Fatalf("retryFailedURLs failed: %v", err)
+	}
+	if stats != (retryStats{Succeeded: 1, Failed: 1, Skipped: 1}) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if _, ok := store.failed["https://example.com/recovered"]; ok {
+		t.Error("Expected the recovered URL to be cleared")
+	}
+	if len(store.saved) != 1 || store.saved[0].URL != "https://example.com/recovered" {
+		t.Errorf("Expected the recovered article to be saved, got %v", store.saved)
+	}
+
+	stillDown := store.failed["https://example.com/still-down"]
+	if stillDown == nil || stillDown.Attempts != 2 || stillDown.Error != "unexpected status code: 503" {
+		t.Errorf("Expected the failure to be recorded as a second attempt, got %+v", stillDown)
+	}
+	if givenUp := store.failed["https://example.com/given-up"]; givenUp == nil || givenUp.Attempts != 3 {
+		t.Errorf("Expected the URL over -max-attempts to be left alone, got %+v", givenUp)
+	}
+}
+
+func TestRetryFailedURLs_StopsWhenCancelled(t *testing.T) {
+	store := newMemoryFailedURLs(domain.FailedURL{URL: "https://example.com/a", Attempts: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := retryFailedURLs(ctx, store, stubProcessor{}, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if store.failed["https://example.com/a"].Attempts != 1 {
+		t.Error("Expected no attempt to be recorded for a cancelled retry")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// signalContext returns a context that is canceled on SIGINT or SIGTERM, so long-running
+// crawls stop handing out new work and let in-flight items finish.
+// Call the returned cancel function to stop listening for signals.
+func signalContext() (context.Context, context.CancelFunc) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	ctx, cancel := contextCanceledBy(context.Background(), sigCh)
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel()
+	}
+}
+
+// contextCanceledBy returns a context that is canceled when a signal arrives on sigCh
+func contextCanceledBy(parent context.Context, sigCh <-chan os.Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	go func() {
+		select {
+		case sig := <-sigCh:
+			log.Printf("Received %s, finishing in-flight work and shutting down...", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestContextCanceledBy_SignalCancelsContext(t *testing.T) {
+	sigCh := make(chan os.Signal, 1)
+	ctx, cancel := contextCanceledBy(context.Background(), sigCh)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("Expected context to stay open before a signal arrives")
+	default:
+	}
+
+	sigCh <- syscall.SIGINT
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected context to be canceled after SIGINT")
+	}
+}
+
+func TestContextCanceledBy_CancelWithoutSignal(t *testing.T) {
+	sigCh := make(chan os.Signal, 1)
+	ctx, cancel := contextCanceledBy(context.Background(), sigCh)
+	cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected context to be canceled by cancel()")
+	}
+}
+
+func TestSignalContext_SIGTERM(t *testing.T) {
+	ctx, stop := signalContext()
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected context to be canceled after SIGTERM")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"blog-search/pkg/textdownloadservice"
+)
+
+// parserAttempt is what one parser made of a source
+type parserAttempt struct {
+	Parser string
+	URLs   int   // URLs found, if the parser succeeded
+	Err    error // Why the parser failed or found nothing
+}
+
+// runValidate checks that a feed, sitemap or URL file is reachable and parseable, without crawling it
+// Exits with a non-zero status if no parser can read it
+func runValidate(args []string) {
+	if len(args) < 1 {
+		log.Fatalf("Usage: go run . validate <feed-or-sitemap-url-or-file>")
+	}
+	source := args[0]
+
+	attempts, ok := validateSource(source, textdownloadservice.DefaultParsers())
+	writeValidationReport(os.Stdout, source, attempts)
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// validateSource tries the parsers in order, like DownloadText, and stops at the first one
+// that finds URLs; it reports false if none did
+func validateSource(source string, parsers []textdownloadservice.Parser) ([]parserAttempt, bool) {
+	var attempts []parserAttempt
+	for _, parser := range parsers {
+		found, err := parser.Fetcher.Fetch(source)
+		if err == nil && len(found) == 0 {
+			err = fmt.Errorf("no URLs found")
+		}
+		attempts = append(attempts, parserAttempt{Parser: parser.Name, URLs: len(found), Err: err})
+		if err == nil {
+			return attempts, true
+		}
+	}
+	return attempts, false
+}
+
+// writeValidationReport prints one line per parser tried and a verdict
+func writeValidationReport(w io.Writer, source string, attempts []parserAttempt) {
+	for _, attempt := range attempts {
+		if attempt.Err != nil {
+			fmt.Fprintf(w, "%-8s failed: %v\n", attempt.Parser, attempt.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%-8s OK: %d URLs\n", attempt.Parser, attempt.URLs)
+	}
+
+	if len(attempts) > 0 && attempts[len(attempts)-1].Err == nil {
+		last := attempts[len(attempts)-1]
+		fmt.Fprintf(w, "%s is a valid %s source with %d URLs\n", source, last.Parser, last.URLs)
+		return
+	}
+	fmt.Fprintf(w, "%s could not be read by any parser\n", source)
+}
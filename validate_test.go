@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog-search/pkg/textdownloadservice"
+)
+
+func TestValidateSource_Sitemap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/posts/a</loc></url>
+  <url><loc>https://example.com/posts/b</loc></url>
+</urlset>`))
+	}))
+	defer server.Close()
+
+	attempts, ok := validateSource(server.URL+"/sitemap.xml", textdownloadservice.DefaultParsers())
+	if !ok {
+		t.Fatalf("Expected the sitemap to validate, got %+v", attempts)
+	}
+	last := attempts[len(attempts)-1]
+	if last.Parser != "sitemap" || last.URLs != 2 {
+		t.Errorf("Expected the sitemap parser to find 2 URLs, got %+v", last)
+	}
+
+	var report bytes.Buffer
+	writeValidationReport(&report, server.URL+"/sitemap.xml", attempts)
+	if !strings.Contains(report.String(), "valid sitemap source with 2 URLs") {
+		t.Errorf("Expected the report to name the sitemap parser, got:\n%s", report.String())
+	}
+}
+
+func TestValidateSource_Unparseable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("this is neither a feed nor a sitemap"))
+	}))
+	defer server.Close()
+
+	attempts, ok := validateSource(server.URL, textdownloadservice.DefaultParsers())
+	if ok {
+		t.Fatalf("Expected validation to fail, got %+v", attempts)
+	}
+	if len(attempts) != len(textdownloadservice.DefaultParsers()) {
+		t.Errorf("Expected every parser to be tried, got %+v", attempts)
+	}
+	for _, attempt := range attempts {
+		if attempt.Err == nil {
+			t.Errorf("Expected %s to fail", attempt.Parser)
+		}
+	}
+
+	var report bytes.Buffer
+	writeValidationReport(&report, server.URL, attempts)
+	if !strings.Contains(report.String(), "could not be read by any parser") {
+		t.Errorf("Expected a failure verdict, got:\n%s", report.String())
+	}
+}
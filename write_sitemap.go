@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"blog-search/pkg/domain"
+	"blog-search/pkg/sitemap"
+)
+
+const defaultSitemapPageSize = 1000
+
+// articlePager reads stored articles page by page; db.Client satisfies it
+type articlePager interface {
+	GetArticlesPaged(ctx context.Context, offset, limit int) ([]domain.Article, error)
+}
+
+// runWriteSitemap writes a sitemap of every stored article, with crawled_at as lastmod
+func runWriteSitemap(args []string) {
+	fs := flag.NewFlagSet("write-sitemap", flag.ExitOnError)
+	outPath := fs.String("out", "sitemap.xml", "File to write the sitemap to")
+	pageSize := fs.Int("page-size", defaultSitemapPageSize, "Articles read from MongoDB per query")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	ctx := context.Background()
+	dbClient := initializeDatabase(ctx)
+	defer dbClient.Close(ctx)
+
+	entries, err := sitemapEntries(ctx, dbClient, *pageSize)
+	if err != nil {
+		log.Fatalf("Failed to read articles: %v", err)
+	}
+
+	if err := writeSitemapFile(*outPath, entries); err != nil {
+		log.Fatalf("Failed to write sitemap: %v", err)
+	}
+	log.Printf("Wrote %d URLs to %s", len(entries), *outPath)
+}
+
+// sitemapEntries returns a sitemap entry for each stored article, newest first
+// Articles without a URL are skipped
+func sitemapEntries(ctx context.Context, store articlePager, pageSize int) ([]sitemap.Entry, error) {
+	if pageSize <= 0 {
+		pageSize = defaultSitemapPageSize
+	}
+
+	var entries []sitemap.Entry
+	for offset := 0; ; offset += pageSize {
+		articles, err := store.GetArticlesPaged(ctx, offset, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read articles at offset %d: %w", offset, err)
+		}
+		for _, article := range articles {
+			if article.URL == "" {
+				continue
+			}
+			entries = append(entries, sitemap.Entry{Loc: article.URL, LastMod: article.CrawledAt})
+		}
+		if len(articles) < pageSize {
+			return entries, nil
+		}
+	}
+}
+
+// writeSitemapFile writes entries to path, replacing the file only if the whole sitemap was written
+func writeSitemapFile(path string, entries []sitemap.Entry) error {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+
+	if err := sitemap.WriteSitemap(file, entries); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to move sitemap into place: %w", err)
+	}
+	return nil
+}
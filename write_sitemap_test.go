@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-search/pkg/domain"
+)
+
+func TestSitemapEntries_ReadsAllPages(t *testing.T) {
+	crawledAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	store := &memoryArticles{articles: []domain.Article{
+		{URL: "https://example.com/a", CrawledAt: crawledAt},
+		{URL: ""},
+		{URL: "https://example.com/b", CrawledAt: crawledAt},
+	}}
+
+	entries, err := sitemapEntries(context.Background(), store, 2)
+	if err != nil {
+		t.Fatalf("sitemapEntries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Loc != "https://example.com/a" || entries[1].Loc != "https://example.com/b" {
+		t.Fatalf("Expected both stored URLs, got %+v", entries)
+	}
+	if !entries[0].LastMod.Equal(crawledAt) {
+		t.Errorf("Expected crawled_at as lastmod, got %v", entries[0].LastMod)
+	}
+
+	path := filepath.Join(t.TempDir(), "sitemap.xml")
+	if err := writeSitemapFile(path, entries); err != nil {
+		t.Fatalf("writeSitemapFile failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read sitemap: %v", err)
+	}
+	if !strings.Contains(string(data), "<loc>https://example.com/b</loc>") {
+		t.Errorf("Expected the sitemap to list the articles, got:\n%s", data)
+	}
+}